@@ -0,0 +1,371 @@
+package cache
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	bitmapContainerBits  = 1 << 16                  // 每个容器容纳的位数。
+	bitmapContainerWords = bitmapContainerBits / 64 // 稠密容器的 uint64 个数。
+	bitmapArrayMax       = bitmapContainerWords * 4 // 稀疏容器的最大元素个数，超过后转换成稠密容器（两者占用的内存相同）。
+	bitmapArrayMin       = bitmapArrayMax / 2       // 稠密容器的元素个数少于该值时转换回稀疏容器。
+)
+
+// bitmapContainer 压缩位图的容器，存储低 16 位。
+// 元素较少时使用有序数组（稀疏），较多时使用位数组（稠密），思路与 Roaring Bitmap 相同。
+type bitmapContainer struct {
+	array []uint16 // 稀疏容器的有序元素，words 为 nil 时使用。
+	words []uint64 // 稠密容器的位数组，bit i 对应 words[i/64] 的第 i%64 位。
+	n     int      // 元素个数。
+}
+
+func (c *bitmapContainer) has(v uint16) bool {
+	if c.words != nil {
+		return c.words[v/64]&(1<<(v%64)) != 0
+	}
+
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+	return i < len(c.array) && c.array[i] == v
+}
+
+// set 设置或者清除元素。
+// return: 设置前元素是否存在。
+func (c *bitmapContainer) set(v uint16, value bool) bool {
+	if c.words != nil {
+		w, mask := &c.words[v/64], uint64(1)<<(v%64)
+		old := *w&mask != 0
+		if old == value {
+			return old
+		}
+
+		if value {
+			*w |= mask
+			c.n++
+		} else {
+			*w &^= mask
+			c.n--
+			if c.n < bitmapArrayMin {
+				c.toArray()
+			}
+		}
+		return old
+	}
+
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+	old := i < len(c.array) && c.array[i] == v
+	if old == value {
+		return old
+	}
+
+	if value {
+		if c.n >= bitmapArrayMax {
+			c.toWords()
+			return c.set(v, value)
+		}
+		c.array = append(c.array, 0)
+		copy(c.array[i+1:], c.array[i:])
+		c.array[i] = v
+		c.n++
+	} else {
+		c.array = append(c.array[:i], c.array[i+1:]...)
+		c.n--
+	}
+	return old
+}
+
+// min 获取最小的元素，调用方需要保证容器不为空。
+func (c *bitmapContainer) min() uint16 {
+	if c.words == nil {
+		return c.array[0]
+	}
+
+	for i, w := range c.words {
+		if w != 0 {
+			return uint16(i*64 + bits.TrailingZeros64(w))
+		}
+	}
+	return 0
+}
+
+// minZero 获取最小的不存在的元素。
+// return: 容器已满时返回 false 。
+func (c *bitmapContainer) minZero() (uint16, bool) {
+	if c.words == nil {
+		for i, v := range c.array {
+			if int(v) != i {
+				return uint16(i), true
+			}
+		}
+		return uint16(len(c.array)), len(c.array) < bitmapContainerBits
+	}
+
+	for i, w := range c.words {
+		if w != ^uint64(0) {
+			return uint16(i*64 + bits.TrailingZeros64(^w)), true
+		}
+	}
+	return 0, false
+}
+
+// dense 获取位数组的副本。
+func (c *bitmapContainer) dense() []uint64 {
+	words := make([]uint64, bitmapContainerWords)
+	if c == nil {
+		return words
+	}
+
+	if c.words != nil {
+		copy(words, c.words)
+		return words
+	}
+
+	for _, v := range c.array {
+		words[v/64] |= 1 << (v % 64)
+	}
+	return words
+}
+
+func (c *bitmapContainer) toWords() {
+	c.words = c.dense()
+	c.array = nil
+}
+
+func (c *bitmapContainer) toArray() {
+	array := make([]uint16, 0, c.n)
+	for i, w := range c.words {
+		for w != 0 {
+			array = append(array, uint16(i*64+bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+	c.array, c.words = array, nil
+}
+
+// newBitmapContainer 从位数组创建容器，没有元素时返回 nil 。
+func newBitmapContainer(words []uint64) *bitmapContainer {
+	n := 0
+	for _, w := range words {
+		n += bits.OnesCount64(w)
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	c := &bitmapContainer{words: words, n: n}
+	if n <= bitmapArrayMax {
+		c.toArray()
+	}
+	return c
+}
+
+// bitmap 内存缓存使用的压缩位图，按高 16 位分成多个容器，不存在的容器表示全 0 。
+// 与 redis 的字符串一致，记录了位图的字节长度，影响 BITOP NOT 和 BITPOS 查找 0 的结果。
+// 非线程安全，由调用方加锁。
+type bitmap struct {
+	containers map[uint32]*bitmapContainer
+	length     int64 // 字节长度，即设置过（包括设置为 0）的最大偏移量所在的字节数。
+}
+
+func newBitmap() *bitmap {
+	return &bitmap{containers: make(map[uint32]*bitmapContainer)}
+}
+
+func (b *bitmap) get(offset int64) bool {
+	c := b.containers[uint32(offset>>16)]
+	return c != nil && c.has(uint16(offset))
+}
+
+// set 设置指定偏移量的位。
+// return: 设置前的值。
+func (b *bitmap) set(offset int64, value bool) bool {
+	if l := offset/8 + 1; l > b.length {
+		b.length = l
+	}
+
+	hi := uint32(offset >> 16)
+	c := b.containers[hi]
+	if c == nil {
+		if !value {
+			return false
+		}
+		c = &bitmapContainer{}
+		b.containers[hi] = c
+	}
+
+	old := c.set(uint16(offset), value)
+	if c.n == 0 {
+		delete(b.containers, hi)
+	}
+	return old
+}
+
+func (b *bitmap) count() int64 {
+	var n int64
+	for _, c := range b.containers {
+		n += int64(c.n)
+	}
+	return n
+}
+
+// pos 获取第一个值为 bit 的偏移量，与 redis 的 BITPOS 一致:
+// 查找 1 时，不存在返回 -1；查找 0 时，不存在返回位图的位数，即紧接着末尾的偏移量。
+func (b *bitmap) pos(bit bool) int64 {
+	keys := b.sortedKeys()
+
+	if bit {
+		if len(keys) == 0 {
+			return -1
+		}
+		hi := keys[0]
+		return int64(hi)<<16 | int64(b.containers[hi].min())
+	}
+
+	size := b.length * 8
+	for hi := int64(0); hi<<16 < size; hi++ {
+		c := b.containers[uint32(hi)]
+		if c == nil {
+			return hi << 16
+		}
+		if v, ok := c.minZero(); ok {
+			if p := hi<<16 | int64(v); p < size {
+				return p
+			}
+			break
+		}
+	}
+	return size
+}
+
+func (b *bitmap) sortedKeys() []uint32 {
+	keys := make([]uint32, 0, len(b.containers))
+	for hi := range b.containers {
+		keys = append(keys, hi)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// bitOp 对多个位图执行位运算，与 redis 的 BITOP 一致，较短的位图视为以 0 填充，
+// 结果的长度为最长的位图的长度，NOT 只接受一个位图。
+// srcs 中的 nil 表示不存在的位图。
+func bitOp(op BitOp, srcs []*bitmap) *bitmap {
+	res := newBitmap()
+	keys := make(map[uint32]struct{})
+	for _, b := range srcs {
+		if b == nil {
+			continue
+		}
+		if b.length > res.length {
+			res.length = b.length
+		}
+		for hi := range b.containers {
+			keys[hi] = struct{}{}
+		}
+	}
+
+	if op == BitOpNot {
+		// 全 0 的容器取反后是全 1，所以要遍历长度范围内的所有容器。
+		size := res.length * 8
+		for hi := int64(0); hi<<16 < size; hi++ {
+			var c *bitmapContainer
+			if srcs[0] != nil {
+				c = srcs[0].containers[uint32(hi)]
+			}
+
+			words := c.dense()
+			for i := range words {
+				words[i] = ^words[i]
+			}
+
+			// 清除超出长度的位。
+			if rest := size - hi<<16; rest < bitmapContainerBits {
+				for i := rest; i < bitmapContainerBits; i++ {
+					words[i/64] &^= 1 << (i % 64)
+				}
+			}
+
+			if c := newBitmapContainer(words); c != nil {
+				res.containers[uint32(hi)] = c
+			}
+		}
+		return res
+	}
+
+	for hi := range keys {
+		var words []uint64
+		for i, b := range srcs {
+			var c *bitmapContainer
+			if b != nil {
+				c = b.containers[hi]
+			}
+
+			if i == 0 {
+				words = c.dense()
+				continue
+			}
+
+			other := c.dense()
+			for j := range words {
+				switch op {
+				case BitOpAnd:
+					words[j] &= other[j]
+				case BitOpOr:
+					words[j] |= other[j]
+				case BitOpXor:
+					words[j] ^= other[j]
+				}
+			}
+		}
+
+		if c := newBitmapContainer(words); c != nil {
+			res.containers[hi] = c
+		}
+	}
+	return res
+}
+
+// bitOpBytes 对 redis 字符串格式的多个位图执行位运算，规则与 bitOp 相同。
+// 位图的第 0 位是第一个字节的最高位。
+func bitOpBytes(op BitOp, srcs [][]byte) []byte {
+	length := 0
+	for _, s := range srcs {
+		if len(s) > length {
+			length = len(s)
+		}
+	}
+
+	res := make([]byte, length)
+	if op == BitOpNot {
+		copy(res, srcs[0])
+		for i := range res {
+			res[i] = ^res[i]
+		}
+		return res
+	}
+
+	for i, s := range srcs {
+		if i == 0 {
+			copy(res, s)
+			continue
+		}
+
+		for j := range res {
+			var v byte
+			if j < len(s) {
+				v = s[j]
+			}
+
+			switch op {
+			case BitOpAnd:
+				res[j] &= v
+			case BitOpOr:
+				res[j] |= v
+			case BitOpXor:
+				res[j] ^= v
+			}
+		}
+	}
+	return res
+}
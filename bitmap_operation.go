@@ -0,0 +1,328 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// BitmapMaxOffset 位图允许的最大偏移量（不包含），与 redis 字符串的最大长度 512MB 一致。
+const BitmapMaxOffset = 1 << 32
+
+// BitOp 位图的位运算。
+type BitOp string
+
+const (
+	BitOpAnd BitOp = "AND" // 按位与。
+	BitOpOr  BitOp = "OR"  // 按位或。
+	BitOpXor BitOp = "XOR" // 按位异或。
+	BitOpNot BitOp = "NOT" // 按位取反，只接受一个位图。
+)
+
+// BitmapCacheProvider 是支持位图结构的 CacheProvider 。
+type BitmapCacheProvider interface {
+	CacheProvider
+
+	// BitmapSet 设置指定偏移量的位，位图不存在时创建。
+	//  @key: cache key.
+	//  @offset: 偏移量，范围 [0, BitmapMaxOffset) 。
+	//  @value: true 设置为 1 ，false 设置为 0 。
+	//  @t: 过期时长， 0表不过期，只有位图是新创建的才设置。
+	// return: 设置前的值。
+	BitmapSet(key string, offset int64, value bool, t time.Duration) (bool, error)
+
+	// BitmapGet 获取指定偏移量的位，位图不存在或者超出长度时返回 false 。
+	BitmapGet(key string, offset int64) (bool, error)
+
+	// BitmapCount 获取值为 1 的位的个数，位图不存在时返回 0 。
+	BitmapCount(key string) (int64, error)
+
+	// BitmapOp 对多个位图执行位运算，结果覆盖写入 dest ，较短的位图视为以 0 填充。
+	//  @op: 位运算，BitOpNot 只接受一个位图。
+	//  @dest: 结果的 cache key ，结果长度为 0 时 dest 被移除。
+	//  @keys: 参与运算的位图，不存在的位图视为空。
+	//  @t: dest 的过期时长， 0表不过期。
+	// return: 结果的字节长度，即最长的位图的字节长度。
+	BitmapOp(op BitOp, dest string, keys []string, t time.Duration) (int64, error)
+
+	// BitmapPos 获取第一个值为 bit 的偏移量。
+	// 与 redis 的 BITPOS 一致: 查找 1 时，不存在返回 -1 ；
+	// 查找 0 时，不存在返回位图的位数（位图不存在时为 0），即位图末尾之后的偏移量。
+	BitmapPos(key string, bit bool) (int64, error)
+}
+
+// BitmapOperation 位图缓存操作对象，例如按用户 ID 记录每天的活跃用户。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个位图。
+// unique flag 中的 time.Time, UnixTime 转换成按天的分桶，格式为 20060102 ，使用时间自身的时区，
+// 同一天内的任意时间得到相同的缓存 key 。
+type BitmapOperation struct {
+	op Operation
+	p  BitmapCacheProvider
+}
+
+// NewBitmapOperation 创建一个位图缓存操作对象。
+// expireTime: 整个位图的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 BitmapCacheProvider 。
+func NewBitmapOperation(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *BitmapOperation {
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(BitmapCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement BitmapCacheProvider", op.cacheProvider))
+	}
+
+	return &BitmapOperation{*op, p}
+}
+
+// Key 获取指定位图的缓存操作对象，time.Time, UnixTime 类型的 key 按天分桶。
+func (c *BitmapOperation) Key(keys ...any) *BitmapKeyOperation {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	flags := make([]any, len(keys))
+	for i, k := range keys {
+		flags[i] = dayBucket(k)
+	}
+
+	key, err := c.op.buildCacheKey(flags...)
+	return &BitmapKeyOperation{
+		owner: c,
+		p:     c.p,
+		exp:   c.op.expireTime,
+		Key:   key,
+		err:   err,
+	}
+}
+
+// And 对多个位图按位与，结果写入 dest ，例如计算连续多天都活跃的用户。
+// 位图必须由当前对象的 Key 方法创建。
+// return: 结果的字节长度。
+func (c *BitmapOperation) And(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) (int64, error) {
+	return c.bitOp(BitOpAnd, dest, sources)
+}
+
+// MustAnd 是 And 的 panic 版。
+func (c *BitmapOperation) MustAnd(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) int64 {
+	result, err := c.And(dest, sources...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Or 对多个位图按位或，结果写入 dest ，例如计算多天内活跃过的用户。
+// 位图必须由当前对象的 Key 方法创建。
+// return: 结果的字节长度。
+func (c *BitmapOperation) Or(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) (int64, error) {
+	return c.bitOp(BitOpOr, dest, sources)
+}
+
+// MustOr 是 Or 的 panic 版。
+func (c *BitmapOperation) MustOr(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) int64 {
+	result, err := c.Or(dest, sources...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Xor 对多个位图按位异或，结果写入 dest 。
+// 位图必须由当前对象的 Key 方法创建。
+// return: 结果的字节长度。
+func (c *BitmapOperation) Xor(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) (int64, error) {
+	return c.bitOp(BitOpXor, dest, sources)
+}
+
+// MustXor 是 Xor 的 panic 版。
+func (c *BitmapOperation) MustXor(dest *BitmapKeyOperation, sources ...*BitmapKeyOperation) int64 {
+	result, err := c.Xor(dest, sources...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Not 对位图按位取反，结果写入 dest ，只在位图的长度范围内取反。
+// 位图必须由当前对象的 Key 方法创建。
+// return: 结果的字节长度。
+func (c *BitmapOperation) Not(dest, source *BitmapKeyOperation) (int64, error) {
+	return c.bitOp(BitOpNot, dest, []*BitmapKeyOperation{source})
+}
+
+// MustNot 是 Not 的 panic 版。
+func (c *BitmapOperation) MustNot(dest, source *BitmapKeyOperation) int64 {
+	result, err := c.Not(dest, source)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// bitOp 在 redis 集群中，不在同一个哈希槽的位图在客户端计算，可以通过 WithHashTag 使它们位于同一个哈希槽。
+func (c *BitmapOperation) bitOp(op BitOp, dest *BitmapKeyOperation, sources []*BitmapKeyOperation) (int64, error) {
+	if len(sources) == 0 {
+		return 0, fmt.Errorf("param 'sources' must not be empty")
+	}
+
+	keys := make([]string, len(sources))
+	for i, keyOp := range append([]*BitmapKeyOperation{dest}, sources...) {
+		if keyOp == nil || keyOp.owner != c {
+			return 0, fmt.Errorf("key does not belong to this operation")
+		}
+		if keyOp.err != nil {
+			return 0, keyOp.err
+		}
+		if i > 0 {
+			keys[i-1] = keyOp.Key
+		}
+	}
+	return c.p.BitmapOp(op, dest.Key, keys, dest.exp.NextExpireTime())
+}
+
+// BitmapKeyOperation 位图缓存 key 的操作对象。
+type BitmapKeyOperation struct {
+	owner *BitmapOperation
+	p     BitmapCacheProvider
+	exp   *Expiration
+	err   error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// SetBit 设置指定偏移量的位，位图是新创建的时候，设置过期时间。
+//  @offset: 偏移量，范围 [0, BitmapMaxOffset) ，例如用户 ID 。
+//  return: 设置前的值。
+func (keyOp *BitmapKeyOperation) SetBit(offset int64, value bool) (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.BitmapSet(keyOp.Key, offset, value, keyOp.exp.NextExpireTime())
+}
+
+// MustSetBit 是 SetBit 的 panic 版。
+func (keyOp *BitmapKeyOperation) MustSetBit(offset int64, value bool) bool {
+	result, err := keyOp.SetBit(offset, value)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetBit 获取指定偏移量的位，位图不存在时返回 false 。
+func (keyOp *BitmapKeyOperation) GetBit(offset int64) (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.BitmapGet(keyOp.Key, offset)
+}
+
+// MustGetBit 是 GetBit 的 panic 版。
+func (keyOp *BitmapKeyOperation) MustGetBit(offset int64) bool {
+	result, err := keyOp.GetBit(offset)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Count 获取值为 1 的位的个数，位图不存在时返回 0 。
+func (keyOp *BitmapKeyOperation) Count() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.BitmapCount(keyOp.Key)
+}
+
+// MustCount 是 Count 的 panic 版。
+func (keyOp *BitmapKeyOperation) MustCount() int64 {
+	result, err := keyOp.Count()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Pos 获取第一个值为 bit 的偏移量。
+//  return: 查找 1 时，不存在返回 -1 ；查找 0 时，不存在返回位图的位数。
+func (keyOp *BitmapKeyOperation) Pos(bit bool) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.BitmapPos(keyOp.Key, bit)
+}
+
+// MustPos 是 Pos 的 panic 版。
+func (keyOp *BitmapKeyOperation) MustPos(bit bool) int64 {
+	result, err := keyOp.Pos(bit)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Remove 移除整个位图。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *BitmapKeyOperation) Remove() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *BitmapKeyOperation) MustRemove() bool {
+	result, err := keyOp.Remove()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// dayBucket 将 time.Time, UnixTime 转换成按天的分桶，其他类型原样返回。
+func dayBucket(v any) any {
+	switch t := indirect(v).(type) {
+	case time.Time:
+		return t.Format("20060102")
+	case UnixTime:
+		return time.Time(t).Format("20060102")
+	}
+	return v
+}
+
+// checkBitOffset 校验位图的偏移量。
+func checkBitOffset(offset int64) error {
+	if offset < 0 || offset >= BitmapMaxOffset {
+		return fmt.Errorf("bit offset %d is out of range [0, %d)", offset, int64(BitmapMaxOffset))
+	}
+	return nil
+}
+
+// checkBitOp 校验位运算及参与运算的位图个数。
+func checkBitOp(op BitOp, keys []string) error {
+	switch op {
+	case BitOpAnd, BitOpOr, BitOpXor:
+	case BitOpNot:
+		if len(keys) != 1 {
+			return fmt.Errorf("BITOP NOT must be called with a single source key")
+		}
+	default:
+		return fmt.Errorf("unknown bit operation '%s'", op)
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("keys must not be empty")
+	}
+	return checkKeys(keys)
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestBitmapOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			op := NewBitmapOperation("ns", "dau_"+fmt.Sprint(rand.Int31()), 1, p, NewExpiration(time.Minute, 0))
+			day1 := time.Date(2022, 5, 1, 8, 0, 0, 0, time.Local)
+			d1, d2, result := op.Key(day1), op.Key(day1.AddDate(0, 0, 1)), op.Key("result")
+			defer d1.Remove()
+			defer d2.Remove()
+			defer result.Remove()
+
+			if key := op.Key(day1.Add(10 * time.Hour)).Key; key != d1.Key {
+				t.Fatalf("Key() in the same day = %v, want %v", key, d1.Key)
+			}
+			if key := op.Key(UnixTime(day1)).Key; key != d1.Key {
+				t.Fatalf("Key() with UnixTime = %v, want %v", key, d1.Key)
+			}
+
+			if d1.MustGetBit(100) || d1.MustCount() != 0 || d1.MustPos(true) != -1 {
+				t.Fatalf("missing bitmap should be empty")
+			}
+
+			for _, uid := range []int64{1, 100, 100000} {
+				if d1.MustSetBit(uid, true) {
+					t.Errorf("SetBit(%d) old value should be false", uid)
+				}
+			}
+			if !d1.MustSetBit(100, true) {
+				t.Errorf("SetBit() existing bit should return true")
+			}
+			if !d1.MustGetBit(100) || d1.MustGetBit(101) {
+				t.Errorf("GetBit() mismatch")
+			}
+			if n := d1.MustCount(); n != 3 {
+				t.Errorf("Count() = %v, want 3", n)
+			}
+			if pos := d1.MustPos(true); pos != 1 {
+				t.Errorf("Pos(true) = %v, want 1", pos)
+			}
+			if pos := d1.MustPos(false); pos != 0 {
+				t.Errorf("Pos(false) = %v, want 0", pos)
+			}
+
+			d2.MustSetBit(100, true)
+			d2.MustSetBit(200, true)
+
+			// 连续两天都活跃的用户。
+			if n := op.MustAnd(result, d1, d2); n != 100000/8+1 {
+				t.Errorf("And() len = %v, want %v", n, 100000/8+1)
+			}
+			if n := result.MustCount(); n != 1 || !result.MustGetBit(100) {
+				t.Errorf("And() count = %v, want 1", n)
+			}
+
+			op.MustOr(result, d1, d2)
+			if n := result.MustCount(); n != 4 {
+				t.Errorf("Or() count = %v, want 4", n)
+			}
+
+			op.MustXor(result, d1, d2)
+			if n := result.MustCount(); n != 3 {
+				t.Errorf("Xor() count = %v, want 3", n)
+			}
+
+			op.MustNot(result, d2)
+			if n := result.MustCount(); n != 26*8-2 {
+				t.Errorf("Not() count = %v, want %v", n, 26*8-2)
+			}
+
+			// 结果为空时移除 dest 。
+			if n := op.MustAnd(result, op.Key("missing")); n != 0 {
+				t.Errorf("And() on missing = %v, want 0", n)
+			}
+			if result.MustRemove() {
+				t.Errorf("empty result should be removed")
+			}
+
+			if _, err := d1.SetBit(-1, true); err == nil {
+				t.Errorf("SetBit() negative offset should return error")
+			}
+			if _, err := d1.SetBit(BitmapMaxOffset, true); err == nil {
+				t.Errorf("SetBit() offset out of range should return error")
+			}
+			if _, err := op.And(result); err == nil {
+				t.Errorf("And() without sources should return error")
+			}
+			other := NewBitmapOperation("ns", "other", 0, p, nil)
+			if _, err := op.Or(result, d1, other.Key()); err == nil {
+				t.Errorf("Or() with key of another operation should return error")
+			}
+		})
+	}
+}
+
+func TestBitmapOperation_Ring(t *testing.T) {
+	ring := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"shard": "127.0.0.1:6379"}, MaxRetries: -1})
+	defer ring.Close()
+
+	// 没有哈希标签，分组后在客户端计算。
+	op := NewBitmapOperation("ns", "dau_"+fmt.Sprint(rand.Int31()), 1, NewRedisCacheProvider(ring), NewExpiration(time.Minute, 0))
+	a, b, c := op.Key("a"), op.Key("b"), op.Key("c")
+	defer a.Remove()
+	defer b.Remove()
+	defer c.Remove()
+
+	a.MustSetBit(1, true)
+	a.MustSetBit(9, true)
+	b.MustSetBit(9, true)
+
+	if n := op.MustAnd(c, a, b); n != 2 {
+		t.Errorf("And() len = %v, want 2", n)
+	}
+	if n := c.MustCount(); n != 1 || !c.MustGetBit(9) {
+		t.Errorf("And() count = %v, want 1", n)
+	}
+	if ttl := ring.PTTL(context.Background(), c.Key).Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("And() ttl = %v, want <= 1m", ttl)
+	}
+}
+
+func TestBitmapOperation_WrongType(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	keyOp := NewBitmapOperation("ns", "dau_wrong_type", 0, p, nil).Key()
+	p.Set(keyOp.Key, 1, time.Minute)
+
+	if _, err := keyOp.SetBit(1, true); err == nil {
+		t.Errorf("SetBit() on non-bitmap key should return error")
+	}
+	if _, err := keyOp.Count(); err == nil {
+		t.Errorf("Count() on non-bitmap key should return error")
+	}
+}
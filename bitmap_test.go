@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// bitmapBytes 将位图转换成 redis 字符串格式。
+func bitmapBytes(b *bitmap) []byte {
+	res := make([]byte, b.length)
+	for i := int64(0); i < b.length*8; i++ {
+		if b.get(i) {
+			res[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return res
+}
+
+func TestBitmap_SetGet(t *testing.T) {
+	b := newBitmap()
+	offsets := map[int64]bool{}
+	for i := 0; i < 10000; i++ {
+		// 集中在第一个容器，使其转换成稠密容器。
+		offset := rand.Int63n(1 << 14)
+		if i%10 == 0 {
+			offset = rand.Int63n(1 << 24)
+		}
+
+		value := rand.Intn(4) != 0
+		if old := b.set(offset, value); old != offsets[offset] {
+			t.Fatalf("set(%d) old = %v, want %v", offset, old, offsets[offset])
+		}
+		offsets[offset] = value
+	}
+
+	if b.containers[0].words == nil {
+		t.Errorf("container should be dense")
+	}
+
+	var n int64
+	for offset, value := range offsets {
+		if b.get(offset) != value {
+			t.Fatalf("get(%d) = %v, want %v", offset, !value, value)
+		}
+		if value {
+			n++
+		}
+	}
+	if got := b.count(); got != n {
+		t.Errorf("count() = %v, want %v", got, n)
+	}
+
+	// 清除后转换回稀疏容器，全部清除后移除容器。
+	for offset := range offsets {
+		b.set(offset, false)
+	}
+	if len(b.containers) != 0 || b.count() != 0 {
+		t.Errorf("containers = %v, want empty", len(b.containers))
+	}
+	if b.length == 0 {
+		t.Errorf("length should be kept after clearing bits")
+	}
+}
+
+func TestBitmap_Pos(t *testing.T) {
+	b := newBitmap()
+	if p := b.pos(true); p != -1 {
+		t.Errorf("pos(true) on empty = %v, want -1", p)
+	}
+	if p := b.pos(false); p != 0 {
+		t.Errorf("pos(false) on empty = %v, want 0", p)
+	}
+
+	b.set(70000, true)
+	b.set(70001, true)
+	if p := b.pos(true); p != 70000 {
+		t.Errorf("pos(true) = %v, want 70000", p)
+	}
+
+	// 一个完整的容器和一个字节都是 1 。
+	b = newBitmap()
+	for i := int64(0); i < bitmapContainerBits+8; i++ {
+		b.set(i, true)
+	}
+	if p := b.pos(false); p != bitmapContainerBits+8 {
+		t.Errorf("pos(false) on full = %v, want %v", p, bitmapContainerBits+8)
+	}
+	b.set(bitmapContainerBits+3, false)
+	if p := b.pos(false); p != bitmapContainerBits+3 {
+		t.Errorf("pos(false) = %v, want %v", p, bitmapContainerBits+3)
+	}
+}
+
+func TestBitOp(t *testing.T) {
+	random := func(n int, max int64) *bitmap {
+		b := newBitmap()
+		for i := 0; i < n; i++ {
+			b.set(rand.Int63n(max), true)
+		}
+		return b
+	}
+
+	for _, op := range []BitOp{BitOpAnd, BitOpOr, BitOpXor, BitOpNot} {
+		t.Run(string(op), func(t *testing.T) {
+			srcs := []*bitmap{random(20000, 1<<17), random(100, 1<<18), nil}
+			if op == BitOpNot {
+				srcs = srcs[1:2]
+			}
+
+			srcBytes := make([][]byte, len(srcs))
+			for i, s := range srcs {
+				if s != nil {
+					srcBytes[i] = bitmapBytes(s)
+				}
+			}
+
+			got := bitOp(op, srcs)
+			want := bitOpBytes(op, srcBytes)
+			if !bytes.Equal(bitmapBytes(got), want) {
+				t.Errorf("bitOp() result mismatch with bitOpBytes()")
+			}
+		})
+	}
+}
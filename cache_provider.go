@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -48,3 +49,48 @@ type CacheProvider interface {
 	// return: 返回增加后的值。
 	IncreaseOrCreate(key string, increment int64, t time.Duration) (int64, error)
 }
+
+// MultiKeyCacheProvider 是支持批量操作多个 key 的 CacheProvider 。
+type MultiKeyCacheProvider interface {
+	CacheProvider
+
+	// TryGetMulti 批量获取缓存。
+	//  @keys: cache keys.
+	//  @values: receive values, 与 keys 一一对应。
+	// return: 与 keys 一一对应，若 key 存在，对应的 value 被更新成对应值，为 true；反之 value 值不做改变，为 false。
+	TryGetMulti(keys []string, values []any) ([]bool, error)
+
+	// RemoveMulti 批量移除缓存。
+	//  @keys: cache keys.
+	// return: 成功移除的缓存数量。
+	RemoveMulti(keys ...string) (int64, error)
+}
+
+// CompareCacheProvider 是支持比较缓存值后原子地操作缓存的 CacheProvider ，例如释放分布式锁时只移除自己持有的锁。
+// 缓存值按编码后的结果比较，需要使用结果确定的编解码器（例如 JSONCodec），加密等结果随机的编解码器无法比较。
+type CompareCacheProvider interface {
+	CacheProvider
+
+	// CompareAndRemove 仅当缓存值等于 value 时移除缓存。
+	//  @key: cache key.
+	//  @value: 期望的缓存值。
+	// return: true 成功移除；false 缓存不存在或者缓存值不相等。
+	CompareAndRemove(key string, value any) (bool, error)
+
+	// CompareAndExpire 仅当缓存值等于 value 时重新设置过期时长。
+	//  @key: cache key.
+	//  @value: 期望的缓存值。
+	//  @t: 过期时长， 0表不过期。
+	// return: true 成功设置；false 缓存不存在或者缓存值不相等。
+	CompareAndExpire(key string, value any, t time.Duration) (bool, error)
+}
+
+// checkKeys 检查 key 不能为空。
+func checkKeys(keys []string) error {
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("key must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/cmstar/go-conv"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 缓存值的编解码器，用于将缓存值与存储的字节互相转换。
+type Codec interface {
+	// Name 编解码器的名称。
+	Name() string
+
+	// Marshal 将 v 编码成字节。
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal 将 data 解码到 v，v 必须是指针。
+	Unmarshal(data []byte, v any) error
+}
+
+// CodecCacheProvider 是可以替换编解码器的 CacheProvider 。
+type CodecCacheProvider interface {
+	CacheProvider
+
+	// WithCodec 返回一个与当前对象共享连接（存储），但使用指定编解码器的缓存提供器。
+	WithCodec(codec Codec) CacheProvider
+}
+
+var (
+	// JSONCodec 使用 encoding/json 编解码，是 RedisCacheProvider 的默认编解码器。
+	JSONCodec Codec = jsonCodec{}
+
+	// GobCodec 使用 encoding/gob 编解码，接口类型的值需要先通过 gob.Register 注册。
+	GobCodec Codec = gobCodec{}
+
+	// MsgpackCodec 使用 MessagePack 编解码。
+	MsgpackCodec Codec = msgpackCodec{}
+
+	// ProtobufCodec 使用 protobuf 编解码，值必须实现 proto.Message 。
+	ProtobufCodec Codec = protobufCodec{}
+
+	// RawCodec 不做编码，直接存取 []byte 和 string，用于与其他语言写入的原始字符串互通。
+	// 基础类型（如数字）按字符串形式存取。
+	RawCodec Codec = rawCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "raw" }
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+
+	if v != nil && conv.IsPrimitiveType(reflect.TypeOf(v)) {
+		var s string
+		if err := conv.Convert(v, &s); err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	}
+
+	return nil, fmt.Errorf("raw codec: unsupported type %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+		return nil
+	case *string:
+		*v = string(data)
+		return nil
+	}
+
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.Ptr && conv.IsPrimitiveType(reflect.TypeOf(v).Elem()) {
+		return conv.Convert(string(data), v)
+	}
+
+	return fmt.Errorf("raw codec: unsupported type %T", v)
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCodec(t *testing.T) {
+	codecs := []Codec{JSONCodec, GobCodec, MsgpackCodec}
+
+	for _, codec := range codecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			b, err := codec.Marshal(data.Person)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var person Person
+			if err = codec.Unmarshal(b, &person); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(person, data.Person) {
+				t.Errorf("Unmarshal() = %v, want %v", person, data.Person)
+			}
+		})
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	b, err := ProtobufCodec.Marshal(wrapperspb.String("protobuf"))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var v wrapperspb.StringValue
+	if err = ProtobufCodec.Unmarshal(b, &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !proto.Equal(&v, wrapperspb.String("protobuf")) {
+		t.Errorf("Unmarshal() = %v", v.GetValue())
+	}
+
+	if _, err = ProtobufCodec.Marshal(data.Person); err == nil {
+		t.Errorf("Marshal() non proto.Message should fail")
+	}
+}
+
+func TestRawCodec(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    string
+		wantErr bool
+	}{
+		{"bytes", []byte("bytes"), "bytes", false},
+		{"string", "string", "string", false},
+		{"int", 10, "10", false},
+		{"struct", data.Person, "", true},
+		{"nil", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RawCodec.Marshal(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	var s string
+	if err := RawCodec.Unmarshal([]byte("java"), &s); err != nil || s != "java" {
+		t.Errorf("Unmarshal() = %v, %v", s, err)
+	}
+
+	var b []byte
+	if err := RawCodec.Unmarshal([]byte("java"), &b); err != nil || string(b) != "java" {
+		t.Errorf("Unmarshal() = %v, %v", b, err)
+	}
+
+	var i int64
+	if err := RawCodec.Unmarshal([]byte("64"), &i); err != nil || i != 64 {
+		t.Errorf("Unmarshal() = %v, %v", i, err)
+	}
+
+	var person Person
+	if err := RawCodec.Unmarshal([]byte("{}"), &person); err == nil {
+		t.Errorf("Unmarshal() struct should fail")
+	}
+}
@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressMagic 压缩数据的头部标记，紧随其后的一个字节是压缩算法的 ID 。
+// 0xC0 在 UTF-8 和 JSON 中不会出现，但 protobuf 、 RawCodec 等的编码结果可能以它开头，
+// 这样的数据即使不压缩也会加上头部，使用 identityCompressorID ，因此不会被误认为压缩数据。
+// 启用压缩之前写入的、以 0xC0 开头的数据无法区分，仍会被当作压缩数据读取。
+const compressMagic byte = 0xC0
+
+// identityCompressorID 表示头部之后的数据没有压缩。
+const identityCompressorID byte = 0
+
+// Compressor 压缩算法。
+type Compressor interface {
+	// ID 压缩算法的标识，写入压缩数据的头部，不同的算法不能重复。
+	ID() byte
+
+	// Name 压缩算法的名称。
+	Name() string
+
+	// Compress 压缩数据。
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress 解压数据。
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	// GzipCompressor 使用 gzip 压缩。
+	GzipCompressor Compressor = gzipCompressor{}
+
+	// FlateCompressor 使用 deflate 压缩。
+	FlateCompressor Compressor = flateCompressor{}
+
+	// SnappyCompressor 使用 snappy 压缩，压缩率较低，但速度很快。
+	SnappyCompressor Compressor = snappyCompressor{}
+
+	// ZstdCompressor 使用 zstd 压缩。
+	ZstdCompressor Compressor = zstdCompressor{}
+)
+
+// builtinCompressors 内置的压缩算法，解压时按头部的 ID 查找，
+// 因此更换压缩算法后，用旧算法压缩的数据仍然可读。
+var builtinCompressors = map[byte]Compressor{
+	GzipCompressor.ID():   GzipCompressor,
+	FlateCompressor.ID():  FlateCompressor,
+	SnappyCompressor.ID(): SnappyCompressor,
+	ZstdCompressor.ID():   ZstdCompressor,
+}
+
+// CompressCodec 对另一个 Codec 的编码结果进行压缩的编解码器，
+// 只有编码结果不小于阈值，并且压缩后确实变小的数据才会被压缩。
+type CompressCodec struct {
+	codec      Codec
+	compressor Compressor
+	threshold  int
+
+	compressed      int64 // 压缩的次数。
+	skipped         int64 // 未压缩的次数。
+	rawBytes        int64 // 被压缩数据压缩前的总字节数。
+	compressedBytes int64 // 被压缩数据压缩后的总字节数（不含头部）。
+}
+
+var _ Codec = (*CompressCodec)(nil)
+
+// NewCompressCodec 创建一个压缩编解码器。
+//  @codec: 实际的编解码器。
+//  @compressor: 压缩算法。
+//  @threshold: 编码结果的字节数不小于该值时才压缩，必须 >= 0 。
+func NewCompressCodec(codec Codec, compressor Compressor, threshold int) *CompressCodec {
+	if codec == nil || compressor == nil {
+		panic(fmt.Errorf("neither 'codec' nor 'compressor' can be nil"))
+	}
+
+	if threshold < 0 {
+		panic(fmt.Errorf("'threshold' must not be less than 0"))
+	}
+
+	return &CompressCodec{codec: codec, compressor: compressor, threshold: threshold}
+}
+
+// implement Codec.Name .
+func (c *CompressCodec) Name() string {
+	return c.codec.Name() + "+" + c.compressor.Name()
+}
+
+// implement Codec.Marshal .
+func (c *CompressCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < c.threshold {
+		atomic.AddInt64(&c.skipped, 1)
+		return uncompressed(data), nil
+	}
+
+	compressed, err := c.compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 压缩后没有变小，不值得解压的开销。
+	if len(compressed)+2 >= len(data) {
+		atomic.AddInt64(&c.skipped, 1)
+		return uncompressed(data), nil
+	}
+
+	atomic.AddInt64(&c.compressed, 1)
+	atomic.AddInt64(&c.rawBytes, int64(len(data)))
+	atomic.AddInt64(&c.compressedBytes, int64(len(compressed)))
+
+	res := make([]byte, 0, len(compressed)+2)
+	res = append(res, compressMagic, c.compressor.ID())
+	return append(res, compressed...), nil
+}
+
+// implement Codec.Unmarshal .
+func (c *CompressCodec) Unmarshal(data []byte, v any) error {
+	if len(data) >= 2 && data[0] == compressMagic && data[1] == identityCompressorID {
+		data = data[2:]
+	} else if len(data) >= 2 && data[0] == compressMagic {
+		compressor := builtinCompressors[data[1]]
+		if data[1] == c.compressor.ID() {
+			compressor = c.compressor
+		}
+
+		if compressor == nil {
+			return fmt.Errorf("unknown compressor id: %d", data[1])
+		}
+
+		var err error
+		if data, err = compressor.Decompress(data[2:]); err != nil {
+			return err
+		}
+	}
+
+	return c.codec.Unmarshal(data, v)
+}
+
+// uncompressed 返回不压缩时保存的数据，以 compressMagic 开头的数据需要加上头部，避免被误认为压缩数据。
+func uncompressed(data []byte) []byte {
+	if len(data) == 0 || data[0] != compressMagic {
+		return data
+	}
+
+	res := make([]byte, 0, len(data)+2)
+	res = append(res, compressMagic, identityCompressorID)
+	return append(res, data...)
+}
+
+// Stats 获取压缩的统计信息。
+func (c *CompressCodec) Stats() CompressStats {
+	return CompressStats{
+		Compressed:      atomic.LoadInt64(&c.compressed),
+		Skipped:         atomic.LoadInt64(&c.skipped),
+		RawBytes:        atomic.LoadInt64(&c.rawBytes),
+		CompressedBytes: atomic.LoadInt64(&c.compressedBytes),
+	}
+}
+
+// CompressStats 压缩的统计信息。
+type CompressStats struct {
+	Compressed      int64 // 压缩的次数。
+	Skipped         int64 // 因为小于阈值或者压缩后没有变小而未压缩的次数。
+	RawBytes        int64 // 被压缩数据压缩前的总字节数。
+	CompressedBytes int64 // 被压缩数据压缩后的总字节数（不含头部）。
+}
+
+// Ratio 压缩率，即压缩后与压缩前字节数的比值，没有压缩过数据时返回 1 。
+func (s CompressStats) Ratio() float64 {
+	if s.RawBytes == 0 {
+		return 1
+	}
+	return float64(s.CompressedBytes) / float64(s.RawBytes)
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) ID() byte { return 1 }
+
+func (gzipCompressor) Name() string { return "gzip" }
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type flateCompressor struct{}
+
+func (flateCompressor) ID() byte { return 2 }
+
+func (flateCompressor) Name() string { return "flate" }
+
+func (flateCompressor) Compress(data []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (flateCompressor) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) ID() byte { return 3 }
+
+func (snappyCompressor) Name() string { return "snappy" }
+
+func (snappyCompressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCompressor) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+type zstdCompressor struct{}
+
+// zstd 的 Encoder 和 Decoder 创建开销较大，EncodeAll 和 DecodeAll 是并发安全的，所以全局共用。
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func (zstdCompressor) init() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+func (zstdCompressor) ID() byte { return 4 }
+
+func (zstdCompressor) Name() string { return "zstd" }
+
+func (z zstdCompressor) Compress(data []byte) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (z zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	return zstdDecoder.DecodeAll(data, nil)
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressCodec(t *testing.T) {
+	large := strings.Repeat("large cached payload ", 100)
+
+	for _, compressor := range []Compressor{GzipCompressor, FlateCompressor, SnappyCompressor, ZstdCompressor} {
+		t.Run(compressor.Name(), func(t *testing.T) {
+			codec := NewCompressCodec(JSONCodec, compressor, 100)
+
+			b, err := codec.Marshal(large)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b[0] != compressMagic || b[1] != compressor.ID() {
+				t.Fatalf("Marshal() large value should be compressed")
+			}
+
+			var s string
+			if err = codec.Unmarshal(b, &s); err != nil || s != large {
+				t.Fatalf("Unmarshal() = %v, %v", len(s), err)
+			}
+
+			// 小于阈值，不压缩。
+			b, err = codec.Marshal("small")
+			if err != nil || string(b) != `"small"` {
+				t.Fatalf("Marshal() = %s, %v", b, err)
+			}
+
+			stats := codec.Stats()
+			if stats.Compressed != 1 || stats.Skipped != 1 {
+				t.Errorf("Stats() = %+v", stats)
+			}
+			if stats.Ratio() <= 0 || stats.Ratio() >= 1 {
+				t.Errorf("Ratio() = %v", stats.Ratio())
+			}
+		})
+	}
+
+	t.Run("compatible", func(t *testing.T) {
+		codec := NewCompressCodec(JSONCodec, GzipCompressor, 0)
+
+		// 启用压缩前写入的数据。
+		old, _ := JSONCodec.Marshal(data.Person)
+		var person Person
+		if err := codec.Unmarshal(old, &person); err != nil || person != data.Person {
+			t.Fatalf("Unmarshal() = %v, %v", person, err)
+		}
+
+		// 更换压缩算法之后，旧算法压缩的数据仍然可读。
+		b, _ := NewCompressCodec(JSONCodec, ZstdCompressor, 0).Marshal(strings.Repeat("a", 1000))
+		var s string
+		if err := codec.Unmarshal(b, &s); err != nil || len(s) != 1000 {
+			t.Fatalf("Unmarshal() = %v, %v", len(s), err)
+		}
+
+		if err := codec.Unmarshal([]byte{compressMagic, 0xFF, 1}, &s); err == nil {
+			t.Fatalf("Unmarshal() unknown compressor should fail")
+		}
+	})
+
+	t.Run("magic_plaintext", func(t *testing.T) {
+		codec := NewCompressCodec(RawCodec, GzipCompressor, 100)
+
+		for _, v := range [][]byte{{compressMagic}, {compressMagic, GzipCompressor.ID(), 1}} {
+			b, err := codec.Marshal(v)
+			if err != nil || !bytes.Equal(b, append([]byte{compressMagic, identityCompressorID}, v...)) {
+				t.Fatalf("Marshal(%v) = %v, %v", v, b, err)
+			}
+
+			var got []byte
+			if err = codec.Unmarshal(b, &got); err != nil || !bytes.Equal(got, v) {
+				t.Fatalf("Unmarshal() = %v, %v, want %v", got, err, v)
+			}
+		}
+	})
+
+	t.Run("incompressible", func(t *testing.T) {
+		codec := NewCompressCodec(RawCodec, GzipCompressor, 0)
+
+		random := make([]byte, 1024)
+		rand.Read(random)
+		random[0] = 'r' // 以 compressMagic 开头的数据会加上头部。
+		b, _ := codec.Marshal(random)
+		if !bytes.Equal(b, random) {
+			t.Fatalf("Marshal() incompressible value should not be compressed")
+		}
+		if codec.Stats().Skipped != 1 || codec.Stats().Ratio() != 1 {
+			t.Errorf("Stats() = %+v", codec.Stats())
+		}
+	})
+}
+
+func TestCompressCodec_Provider(t *testing.T) {
+	large := strings.Repeat("large cached payload ", 100)
+	key := "key_" + fmt.Sprint(rand.Int31())
+
+	providers := map[string]CodecCacheProvider{
+		"memory": NewMemoryCacheProvider(time.Second),
+		"redis":  getNewEveryTime(),
+	}
+	for name, p := range providers {
+		t.Run(name, func(t *testing.T) {
+			defer p.Remove(key)
+
+			codec := NewCompressCodec(JSONCodec, SnappyCompressor, 1024)
+			cp := p.WithCodec(codec)
+			if err := cp.Set(key, large, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			var s string
+			if ok, err := cp.TryGet(key, &s); !ok || err != nil || s != large {
+				t.Fatalf("TryGet() = %v, %v, %v", len(s), ok, err)
+			}
+			if codec.Stats().Compressed != 1 {
+				t.Errorf("Stats() = %+v", codec.Stats())
+			}
+		})
+	}
+}
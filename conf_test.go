@@ -20,6 +20,15 @@ func getNewEveryTime() *RedisCacheProvider {
 	return NewRedisCacheProvider(cli)
 }
 
+// structureTestProviders 用于测试哈希、列表等数据结构的各个缓存提供器。
+func structureTestProviders() map[string]CacheProvider {
+	return map[string]CacheProvider{
+		"memory":       NewMemoryCacheProvider(time.Second),
+		"memory_codec": NewMemoryCacheProvider(time.Second).WithCodec(JSONCodec),
+		"redis":        getNewEveryTime(),
+	}
+}
+
 // 测试的时候使用。
 var tn, _ = time.ParseInLocation("2006-01-02 15:04:05", "2006-01-02 15:04:05", time.Local)
 
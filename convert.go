@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ConvertMode 指定读取缓存时，缓存值与接收值类型不一致的处理方式，可按位组合。
+// 可赋值（assignable）的类型总是直接赋值，不受 ConvertMode 影响。
+type ConvertMode uint8
+
+const (
+	// ConvertConvertible 允许同种 Kind 的可转换类型之间的转换，
+	// 例如字段完全相同的两个结构体、底层类型相同的具名类型。
+	ConvertConvertible ConvertMode = 1 << iota
+
+	// ConvertIndirect 允许指针与值之间的转换，例如缓存 *Person，用 *Person 接收的同时也可以用 Person 接收。
+	ConvertIndirect
+
+	// ConvertJSON 当其他方式都不可行时，使用 JSON 序列化再反序列化完成转换，
+	// 例如结构体转 map[string]any，或者字段不完全相同的结构体。
+	ConvertJSON
+
+	// ConvertNone 仅允许可赋值的类型。
+	ConvertNone ConvertMode = 0
+
+	// ConvertDefault 默认的转换方式。
+	ConvertDefault = ConvertConvertible | ConvertIndirect
+)
+
+// TypeMismatchError 表示缓存值无法以当前的 ConvertMode 转换为接收值的类型。
+type TypeMismatchError struct {
+	Key    string       // 缓存 key 。
+	Source reflect.Type // 缓存值的类型。
+	Target reflect.Type // 接收值的类型。
+}
+
+// Error implements error.
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("cache value of key '%s' is %v, cannot be converted to %v", e.Key, e.Source, e.Target)
+}
+
+// assignValue 将 src 按照 mode 写入 dst（dst 必须是可设置的）。
+// return: 能够转换返回 true，反之 dst 不做改变，返回 false。
+func assignValue(dst, src reflect.Value, mode ConvertMode) (bool, error) {
+	srcT, dstT := src.Type(), dst.Type()
+
+	if srcT.AssignableTo(dstT) {
+		dst.Set(src)
+		return true, nil
+	}
+
+	// 只允许同 Kind 的转换，避免 int -> string 这类反直觉的转换。
+	if mode&ConvertConvertible != 0 && srcT.Kind() == dstT.Kind() && srcT.ConvertibleTo(dstT) {
+		dst.Set(src.Convert(dstT))
+		return true, nil
+	}
+
+	if mode&ConvertIndirect != 0 {
+		// 缓存值是指针，接收值不是。
+		if src.Kind() == reflect.Ptr && !src.IsNil() {
+			if ok, err := assignValue(dst, src.Elem(), mode&^ConvertJSON); ok || err != nil {
+				return ok, err
+			}
+		}
+
+		// 接收值是指针，缓存值不是。
+		if dstT.Kind() == reflect.Ptr {
+			elem := reflect.New(dstT.Elem())
+			if ok, err := assignValue(elem.Elem(), src, mode&^ConvertJSON); ok || err != nil {
+				if ok {
+					dst.Set(elem)
+				}
+				return ok, err
+			}
+		}
+	}
+
+	if mode&ConvertJSON != 0 {
+		b, err := json.Marshal(src.Interface())
+		if err != nil {
+			return false, err
+		}
+
+		// 先写入临时变量，失败时 dst 不做改变。
+		temp := reflect.New(dstT)
+		if err = json.Unmarshal(b, temp.Interface()); err != nil {
+			return false, err
+		}
+		dst.Set(temp.Elem())
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_assignValue(t *testing.T) {
+	type PersonDTO struct {
+		Name string
+		Age  int
+	}
+	type PersonSummary struct {
+		Name string
+	}
+
+	person := Person{"Jerry", 22}
+
+	tests := []struct {
+		name   string
+		src    any
+		dst    any // 指针，用于接收。
+		mode   ConvertMode
+		wantOk bool
+		want   any
+	}{
+		{"assignable", person, &Person{}, ConvertNone, true, person},
+		{"assignable_interface", person, new(any), ConvertNone, true, any(person)},
+		{"convertible_none", person, &PersonDTO{}, ConvertNone, false, PersonDTO{}},
+		{"convertible", person, &PersonDTO{}, ConvertConvertible, true, PersonDTO{"Jerry", 22}},
+		{"convertible_different_kind", []byte("ab"), new(string), ConvertConvertible, false, ""},
+		{"ptr_to_value_none", &person, &Person{}, ConvertNone, false, Person{}},
+		{"ptr_to_value", &person, &Person{}, ConvertIndirect, true, person},
+		{"value_to_ptr", person, new(*Person), ConvertIndirect, true, &person},
+		{"ptr_to_convertible_value", &person, &PersonDTO{}, ConvertDefault, true, PersonDTO{"Jerry", 22}},
+		{"struct_to_map_default", person, &map[string]any{}, ConvertDefault, false, map[string]any{}},
+		{"struct_to_map_json", person, &map[string]any{}, ConvertJSON, true, map[string]any{"Name": "Jerry", "Age": float64(22)}},
+		{"struct_to_subset_json", person, &PersonSummary{}, ConvertJSON, true, PersonSummary{"Jerry"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := reflect.ValueOf(tt.dst).Elem()
+			ok, err := assignValue(dst, reflect.ValueOf(tt.src), tt.mode)
+			if err != nil {
+				t.Fatalf("assignValue() error = %v", err)
+			}
+			if ok != tt.wantOk {
+				t.Fatalf("assignValue() = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(dst.Interface(), tt.want) {
+				t.Errorf("assignValue() value = %v, want %v", dst.Interface(), tt.want)
+			}
+		})
+	}
+}
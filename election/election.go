@@ -0,0 +1,151 @@
+// Package election 提供基于 cache.CacheProvider 的领导者选举，用于保证定时任务等只在一个节点上运行。
+//
+// 候选者竞争同一个租约 key（由 lock.Mutex 实现），获得租约的候选者成为领导者，
+// 持有期间每 ttl/3 续期一次，续期时发现租约已经被其他候选者获取，或者续期一直出错（例如网络分区）直到租约可能过期，
+// 则失去领导权，然后重新竞选。
+// 领导者异常退出时，其他候选者在 ttl 之后接替。
+package election
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/lock"
+)
+
+// Election 领导者选举的操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一次独立的选举。
+type Election struct {
+	l     *lock.Locker
+	retry time.Duration
+}
+
+// NewElection 创建一个领导者选举的操作对象。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 cache.CompareCacheProvider 。
+// ttl: 领导者的租约时长，领导者异常退出后，其他候选者最多等待 ttl 后接替。
+// retry: 竞选失败后的重试间隔，实际的间隔在 [retry/2, retry] 之间随机。
+func NewElection(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider cache.CacheProvider,
+	ttl, retry time.Duration,
+	opts ...cache.OperationOption,
+) *Election {
+	if retry <= 0 {
+		panic(fmt.Errorf("param 'retry' must be greater than 0"))
+	}
+
+	l := lock.NewLocker(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, ttl, opts...)
+	return &Election{l.WithBackoff(retry, retry), retry}
+}
+
+// Key 获取指定选举的一个候选者，每次调用返回一个新的候选者。
+func (e *Election) Key(keys ...any) *Candidate {
+	return &Candidate{e: e, m: e.l.Key(keys...), changes: make(chan bool, 1)}
+}
+
+// Callbacks 领导权变化时的回调，均可以为 nil 。
+type Callbacks struct {
+	// OnElected 成为领导者时在新的 goroutine 中调用，
+	// ctx 在失去领导权或者 Run 的 ctx 结束时取消，领导者的工作应当在 ctx 取消后尽快返回。
+	OnElected func(ctx context.Context)
+
+	// OnStepDown 失去领导权，并且 OnElected 已经返回之后调用。
+	OnStepDown func()
+}
+
+// Candidate 参与选举的一个候选者。
+type Candidate struct {
+	e       *Election
+	m       *lock.Mutex
+	leader  bool
+	mu      sync.Mutex
+	changes chan bool
+}
+
+// Run 持续竞选领导者，直到 ctx 结束。
+// 成为领导者后保持领导权，失去领导权后重新竞选；ctx 结束时如果是领导者，则主动释放租约，其他候选者可以立即接替。
+// 竞选时缓存出错（例如网络异常）在重试间隔之后重试。
+//  return: ctx 结束时返回 ctx.Err() 。
+func (c *Candidate) Run(ctx context.Context, cb Callbacks) error {
+	for {
+		if err := c.m.Lock(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.e.retry):
+			}
+			continue
+		}
+
+		c.lead(ctx, cb)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// lead 作为领导者运行，直到失去领导权或者 ctx 结束。
+func (c *Candidate) lead(ctx context.Context, cb Callbacks) {
+	c.setLeader(true)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	if cb.OnElected != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cb.OnElected(leaderCtx)
+		}()
+	}
+
+	select {
+	case <-c.m.Done():
+	case <-ctx.Done():
+		c.m.Unlock()
+	}
+
+	cancel()
+	wg.Wait()
+	c.setLeader(false)
+
+	if cb.OnStepDown != nil {
+		cb.OnStepDown()
+	}
+}
+
+// IsLeader 判断当前候选者是否是领导者。
+// 租约在续期之前被其他候选者获取时，最多在 ttl/3 之后才能发现。
+func (c *Candidate) IsLeader() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leader
+}
+
+// Changes 返回领导权变化的通道，true 成为领导者，false 失去领导权。
+// 通道只保留最新的状态，没有及时读取的旧状态会被丢弃。
+func (c *Candidate) Changes() <-chan bool {
+	return c.changes
+}
+
+// setLeader 更新领导权，并通知 Changes 。
+func (c *Candidate) setLeader(leader bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.leader = leader
+	select { // 丢弃没有读取的旧状态。
+	case <-c.changes:
+	default:
+	}
+	c.changes <- leader
+}
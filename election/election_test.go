@@ -0,0 +1,148 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+// waitChange 等待领导权变化。
+func waitChange(t *testing.T, c *Candidate, want bool) {
+	t.Helper()
+	select {
+	case got := <-c.Changes():
+		if got != want {
+			t.Fatalf("Changes() = %v, want %v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Changes() timeout, want %v", want)
+	}
+}
+
+func TestCandidate_Run(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			e := NewElection("ns", cachetest.Prefix("election"), 1, p, 300*time.Millisecond, 10*time.Millisecond)
+			c1, c2 := e.Key("cron"), e.Key("cron")
+
+			var running int32
+			cb := Callbacks{
+				OnElected: func(ctx context.Context) {
+					if atomic.AddInt32(&running, 1) > 1 {
+						t.Errorf("more than one leader")
+					}
+					<-ctx.Done()
+					atomic.AddInt32(&running, -1)
+				},
+			}
+
+			ctx1, cancel1 := context.WithCancel(context.Background())
+			done1 := make(chan error, 1)
+			go func() { done1 <- c1.Run(ctx1, cb) }()
+			waitChange(t, c1, true)
+
+			ctx2, cancel2 := context.WithCancel(context.Background())
+			defer cancel2()
+			done2 := make(chan error, 1)
+			go func() { done2 <- c2.Run(ctx2, cb) }()
+
+			time.Sleep(100 * time.Millisecond)
+			if !c1.IsLeader() || c2.IsLeader() {
+				t.Fatalf("IsLeader() = %v, %v, want true, false", c1.IsLeader(), c2.IsLeader())
+			}
+
+			// c1 主动退出，c2 立即接替。
+			cancel1()
+			if err := <-done1; !errors.Is(err, context.Canceled) {
+				t.Errorf("Run() error = %v, want Canceled", err)
+			}
+			waitChange(t, c1, false)
+			waitChange(t, c2, true)
+
+			cancel2()
+			<-done2
+			if c2.IsLeader() {
+				t.Errorf("IsLeader() after Run() returned = true")
+			}
+		})
+	}
+}
+
+func TestCandidate_Lost(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			e := NewElection("ns", cachetest.Prefix("election"), 0, p, 90*time.Millisecond, 500*time.Millisecond)
+			c := e.Key()
+
+			stepDown := make(chan struct{}, 1)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go c.Run(ctx, Callbacks{OnStepDown: func() { stepDown <- struct{}{} }})
+			waitChange(t, c, true)
+
+			// 租约被其他节点获取，失去领导权。
+			p.Set(c.m.Key, "other", time.Second)
+			waitChange(t, c, false)
+			select {
+			case <-stepDown:
+			case <-time.After(time.Second):
+				t.Fatalf("OnStepDown should be called")
+			}
+
+			// 其他节点的租约释放后重新当选。
+			p.Remove(c.m.Key)
+			waitChange(t, c, true)
+		})
+	}
+}
+
+// failingProvider 可以使续期出错的缓存提供器，模拟领导者所在节点的网络分区。
+type failingProvider struct {
+	cache.CompareCacheProvider
+	failing int32
+}
+
+func (p *failingProvider) CompareAndExpire(key string, expected any, t time.Duration) (bool, error) {
+	if atomic.LoadInt32(&p.failing) != 0 {
+		return false, errors.New("network error")
+	}
+	return p.CompareCacheProvider.CompareAndExpire(key, expected, t)
+}
+
+func TestCandidate_RenewError(t *testing.T) {
+	p := cache.NewMemoryCacheProvider(time.Second)
+	partitioned := &failingProvider{CompareCacheProvider: p}
+	prefix := cachetest.Prefix("election")
+	c1 := NewElection("ns", prefix, 0, partitioned, 90*time.Millisecond, 500*time.Millisecond).Key()
+	c2 := NewElection("ns", prefix, 0, p, 90*time.Millisecond, 10*time.Millisecond).Key()
+
+	var running int32
+	cb := Callbacks{
+		OnElected: func(ctx context.Context) {
+			if atomic.AddInt32(&running, 1) > 1 {
+				t.Errorf("more than one leader")
+			}
+			<-ctx.Done()
+			atomic.AddInt32(&running, -1)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c1.Run(ctx, cb)
+	waitChange(t, c1, true)
+	go c2.Run(ctx, cb)
+
+	// 续期一直出错，在租约过期之前失去领导权，租约过期后由 c2 接替。
+	atomic.StoreInt32(&partitioned.failing, 1)
+	waitChange(t, c1, false)
+	waitChange(t, c2, true)
+	if c1.IsLeader() {
+		t.Errorf("c1.IsLeader() = true, want false")
+	}
+}
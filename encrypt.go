@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// encryptMagic 加密数据的头部标记，其后依次是密钥 ID 的长度（1字节）、密钥 ID、nonce、密文。
+// 0xC1 在 UTF-8 和 MessagePack 中都不会出现，与 compressMagic 也不冲突。
+const encryptMagic byte = 0xC1
+
+// ErrCacheMiss 可以被 Codec.Unmarshal 返回（或包装），此时缓存提供器将该缓存视为不存在。
+var ErrCacheMiss = errors.New("cache miss")
+
+// DecryptError 表示缓存值解密失败，例如未加密、密钥不存在或者数据被篡改。
+type DecryptError struct {
+	KeyID string // 缓存值使用的密钥 ID，数据不完整时为空。
+	Err   error  // 失败的原因。
+
+	miss bool // 是否视为缓存不存在。
+}
+
+// Error implements error.
+func (e *DecryptError) Error() string {
+	return fmt.Sprintf("decrypt cache value failed, key id '%s': %v", e.KeyID, e.Err)
+}
+
+// Unwrap 返回失败的原因。
+func (e *DecryptError) Unwrap() error {
+	return e.Err
+}
+
+// Is 用于 errors.Is，当 EncryptCodec 配置了 missOnFailure 时，等同于 ErrCacheMiss 。
+func (e *DecryptError) Is(target error) bool {
+	return e.miss && target == ErrCacheMiss
+}
+
+// Keyring 加密的密钥环，使用主密钥加密，使用任意一个密钥解密，用于密钥轮换。
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring 创建一个密钥环。
+//  @primaryID: 主密钥的 ID，必须存在于 keys 中。
+//  @keys: 密钥 ID 到 AES 密钥的映射，ID 长度为 1-255 字节，密钥长度为 16、24 或 32 字节。
+func NewKeyring(primaryID string, keys map[string][]byte) *Keyring {
+	if _, ok := keys[primaryID]; !ok {
+		panic(fmt.Errorf("primary key '%s' does not exist", primaryID))
+	}
+
+	k := &Keyring{primaryID, make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(id) == 0 || len(id) > 255 {
+			panic(fmt.Errorf("the length of key id '%s' must be in [1, 255]", id))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic(fmt.Errorf("key '%s': %w", id, err))
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(fmt.Errorf("key '%s': %w", id, err))
+		}
+		k.aeads[id] = aead
+	}
+
+	return k
+}
+
+// EncryptCodec 使用 AES-GCM 对另一个 Codec 的编码结果进行加密的编解码器。
+// 通常通过 WithCodec 只对需要加密的 Operation 启用。
+type EncryptCodec struct {
+	codec         Codec
+	keyring       *Keyring
+	missOnFailure bool
+}
+
+var _ Codec = (*EncryptCodec)(nil)
+
+// NewEncryptCodec 创建一个加密编解码器。
+//  @codec: 实际的编解码器，需要压缩时，应该传入 CompressCodec（加密后的数据无法压缩）。
+//  @keyring: 密钥环。
+//  @missOnFailure: 解密失败时，true 表示视为缓存不存在，false 表示返回 *DecryptError 。
+func NewEncryptCodec(codec Codec, keyring *Keyring, missOnFailure bool) *EncryptCodec {
+	if codec == nil || keyring == nil {
+		panic(fmt.Errorf("neither 'codec' nor 'keyring' can be nil"))
+	}
+
+	return &EncryptCodec{codec, keyring, missOnFailure}
+}
+
+// implement Codec.Name .
+func (c *EncryptCodec) Name() string {
+	return c.codec.Name() + "+aes-gcm"
+}
+
+// implement Codec.Marshal .
+func (c *EncryptCodec) Marshal(v any) ([]byte, error) {
+	plaintext, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	id := c.keyring.primary
+	aead := c.keyring.aeads[id]
+
+	header := make([]byte, 0, 2+len(id)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	header = append(header, encryptMagic, byte(len(id)))
+	header = append(header, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// 头部作为附加数据参与认证，防止密钥 ID 被篡改。
+	res := append(header, nonce...)
+	return aead.Seal(res, nonce, plaintext, header), nil
+}
+
+// implement Codec.Unmarshal .
+func (c *EncryptCodec) Unmarshal(data []byte, v any) error {
+	plaintext, err := c.decrypt(data)
+	if err != nil {
+		err.miss = c.missOnFailure
+		return err
+	}
+
+	return c.codec.Unmarshal(plaintext, v)
+}
+
+func (c *EncryptCodec) decrypt(data []byte) ([]byte, *DecryptError) {
+	if len(data) < 2 || data[0] != encryptMagic {
+		return nil, &DecryptError{Err: errors.New("value is not encrypted")}
+	}
+
+	idLen := int(data[1])
+	if len(data) < 2+idLen {
+		return nil, &DecryptError{Err: errors.New("value is truncated")}
+	}
+
+	id := string(data[2 : 2+idLen])
+	aead, ok := c.keyring.aeads[id]
+	if !ok {
+		return nil, &DecryptError{KeyID: id, Err: errors.New("key does not exist")}
+	}
+
+	header := data[:2+idLen]
+	body := data[2+idLen:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, &DecryptError{KeyID: id, Err: errors.New("value is truncated")}
+	}
+
+	nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, &DecryptError{KeyID: id, Err: err}
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEncryptCodec(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, 32)
+	k2 := bytes.Repeat([]byte{2}, 16)
+
+	old := NewEncryptCodec(JSONCodec, NewKeyring("k1", map[string][]byte{"k1": k1}), false)
+	rotated := NewEncryptCodec(JSONCodec, NewKeyring("k2", map[string][]byte{"k1": k1, "k2": k2}), false)
+
+	t.Run("rotation", func(t *testing.T) {
+		b, err := old.Marshal(data.Person)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(b, []byte(data.Person.Name)) {
+			t.Fatal("value should be encrypted")
+		}
+
+		// 轮换后的密钥环可以解密旧密钥加密的数据。
+		var person Person
+		if err = rotated.Unmarshal(b, &person); err != nil || person != data.Person {
+			t.Fatalf("Unmarshal() = %v, %v", person, err)
+		}
+
+		// 新数据使用主密钥加密，旧的密钥环无法解密。
+		b, _ = rotated.Marshal(data.Person)
+		var decryptErr *DecryptError
+		if err = old.Unmarshal(b, &person); !errors.As(err, &decryptErr) || decryptErr.KeyID != "k2" {
+			t.Fatalf("Unmarshal() error = %v, want *DecryptError", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		b, _ := rotated.Marshal(data.Person)
+		tampered := append([]byte(nil), b...)
+		tampered[len(tampered)-1] ^= 1
+
+		plain, _ := JSONCodec.Marshal(data.Person)
+		tests := []struct {
+			name  string
+			value []byte
+		}{
+			{"tampered", tampered},
+			{"tampered_key_id", append([]byte{encryptMagic, 2, 'k', '1'}, b[4:]...)},
+			{"not_encrypted", plain},
+			{"truncated", b[:10]},
+			{"empty", nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var person Person
+				var decryptErr *DecryptError
+				err := rotated.Unmarshal(tt.value, &person)
+				if !errors.As(err, &decryptErr) {
+					t.Fatalf("Unmarshal() error = %v, want *DecryptError", err)
+				}
+				if errors.Is(err, ErrCacheMiss) {
+					t.Fatalf("Unmarshal() error should not be ErrCacheMiss")
+				}
+			})
+		}
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		key := "key_" + fmt.Sprint(rand.Int31())
+		miss := NewEncryptCodec(JSONCodec, NewKeyring("k1", map[string][]byte{"k1": k1}), true)
+
+		providers := map[string]CodecCacheProvider{
+			"memory": NewMemoryCacheProvider(time.Second),
+			"redis":  getNewEveryTime(),
+		}
+		for name, p := range providers {
+			t.Run(name, func(t *testing.T) {
+				defer p.Remove(key)
+
+				p.WithCodec(rotated).Set(key, data.Person, time.Minute)
+
+				var person Person
+				if ok, err := p.WithCodec(rotated).TryGet(key, &person); !ok || err != nil || person != data.Person {
+					t.Fatalf("TryGet() = %v, %v, %v", person, ok, err)
+				}
+
+				if _, err := p.WithCodec(old).TryGet(key, &person); !errors.As(err, new(*DecryptError)) {
+					t.Fatalf("TryGet() error = %v, want *DecryptError", err)
+				}
+
+				// 解密失败视为缓存不存在。
+				if ok, err := p.WithCodec(miss).TryGet(key, &person); ok || err != nil {
+					t.Fatalf("TryGet() = %v, %v, want cache miss", ok, err)
+				}
+			})
+		}
+	})
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   string
+		keys      map[string][]byte
+		wantPanic bool
+	}{
+		{"ok", "k1", map[string][]byte{"k1": make([]byte, 16)}, false},
+		{"no_primary", "k2", map[string][]byte{"k1": make([]byte, 16)}, true},
+		{"empty_id", "", map[string][]byte{"": make([]byte, 16)}, true},
+		{"bad_key", "k1", map[string][]byte{"k1": make([]byte, 10)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if (recover() != nil) != tt.wantPanic {
+					t.Errorf("NewKeyring() wantPanic %v", tt.wantPanic)
+				}
+			}()
+			NewKeyring(tt.primary, tt.keys)
+		})
+	}
+}
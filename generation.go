@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// generation 缓存操作对象的代数，保存在缓存提供器中，并在本地缓存一段时间。
+// 由同一个 Operation 复制出的缓存操作对象共享同一个 generation 。
+type generation struct {
+	key      string        // 代数的缓存key。
+	p        CacheProvider // 保存代数的缓存提供器。
+	localTTL time.Duration // 本地缓存代数的时长。
+
+	mu      sync.Mutex // 保护以下字段，不在读取代数期间持有。
+	value   int64
+	loaded  bool
+	expires time.Time // 本地缓存的代数的过期时间。
+	bumps   int64     // bump 的次数，用于丢弃与 bump 并发的、可能更旧的读取结果。
+}
+
+// WithGeneration 在缓存key中加入代数，缓存key变为 <CacheNamespace>:<Prefix>#<代数>[:unique flag] ，
+// 调用 BumpGeneration 使代数加 1 后，所有旧的缓存立即失效，无需逐个移除，旧的缓存在过期后被清理，
+// 因此通常应当为缓存操作对象指定过期时长。
+// 代数的缓存key为 <CacheNamespace>:<Prefix>#gen ，不会过期，缓存提供器需要支持 IncreaseOrCreate ，不能是 Level2CacheProvider 。
+// 代数在本地缓存 localTTL ，其他进程（或者另外创建的同名缓存操作对象）执行 BumpGeneration 后，最多经过 localTTL 才能读到新的代数。
+// 从未成功读取过代数时，缓存 key 操作对象的方法返回读取的错误。
+//  @localTTL: 本地缓存代数的时长， 0 表示每次构建缓存key时都从缓存提供器读取，即每次 Key 都有一次网络往返。
+func WithGeneration(localTTL time.Duration) OperationOption {
+	return func(c *Operation) {
+		if localTTL < 0 {
+			panic(fmt.Errorf("'localTTL' must not be less than 0"))
+		}
+		if !supportsCounter(c.cacheProvider) {
+			panic(fmt.Errorf("cache provider %T does not support IncreaseOrCreate, cannot be used with WithGeneration", c.cacheProvider))
+		}
+		c.gen = &generation{key: c.keyBase + "#gen", p: c.cacheProvider, localTTL: localTTL}
+	}
+}
+
+// supportsCounter 判断缓存提供器是否支持 IncreaseOrCreate ， Level2CacheProvider 不支持，调用时 panic 。
+func supportsCounter(p CacheProvider) bool {
+	_, ok := p.(*Level2CacheProvider)
+	return !ok
+}
+
+// current 获取当前的代数。
+// 从缓存提供器读取失败时，继续使用之前的代数；从未成功读取过时返回错误。
+// 本地缓存过期时，并发的调用可能各自读取一次。
+func (g *generation) current() (int64, error) {
+	g.mu.Lock()
+	if g.loaded && time.Now().Before(g.expires) {
+		v := g.value
+		g.mu.Unlock()
+		return v, nil
+	}
+	bumps := g.bumps
+	g.mu.Unlock()
+
+	// 增加 0 用于读取计数器，不受编解码器的影响，代数不存在时创建为 0 。
+	v, err := g.p.IncreaseOrCreate(g.key, 0, NoExpiration)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		if g.loaded {
+			return g.value, nil
+		}
+		return 0, fmt.Errorf("load generation %s: %w", g.key, err)
+	}
+
+	if g.bumps == bumps {
+		g.value, g.loaded, g.expires = v, true, time.Now().Add(g.localTTL)
+	}
+	return g.value, nil
+}
+
+// bump 使代数加 1 ，并立即更新本地缓存的代数。
+func (g *generation) bump() (int64, error) {
+	v, err := g.p.IncreaseOrCreate(g.key, 1, NoExpiration)
+	if err != nil {
+		return 0, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bumps++
+	if !g.loaded || v > g.value { // 并发的 bump 可能先返回更大的代数。
+		g.value, g.loaded, g.expires = v, true, time.Now().Add(g.localTTL)
+	}
+	return v, nil
+}
+
+// currentKeyBase 获取缓存key中 [:unique flag] 之前的部分，使用 WithGeneration 时包含代数。
+func (c *Operation) currentKeyBase() (string, error) {
+	if c.gen == nil {
+		return c.keyBase, nil
+	}
+	gen, err := c.gen.current()
+	if err != nil {
+		return "", err
+	}
+	return c.keyBase + "#" + strconv.FormatInt(gen, 10), nil
+}
+
+// BumpGeneration 使代数加 1 ，当前缓存操作对象的所有缓存立即失效，缓存操作对象必须使用 WithGeneration 创建。
+// return: 新的代数。
+func (c *Operation) BumpGeneration() (int64, error) {
+	if c.gen == nil {
+		panic(fmt.Errorf("operation %s is not created with WithGeneration", c.keyBase))
+	}
+	return c.gen.bump()
+}
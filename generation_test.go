@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestOperation_WithGeneration(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := fmt.Sprint("gen_", time.Now().UnixNano())
+			op := NewOperation1[int, Person]("ns", prefix, p, NewExpiration(time.Minute, 0), WithGeneration(time.Hour))
+			if key, want := op.Key(1).Key, "ns:"+prefix+"#0_1"; key != want {
+				t.Fatalf("Key() = %v, want %v", key, want)
+			}
+			op.Key(1).MustSet(Person{"a", 1})
+
+			// 本地缓存了代数的对象，在 localTTL 内读不到其他对象更新的代数。
+			cached := NewOperation("ns", prefix, 1, p, nil, WithGeneration(time.Hour))
+			fresh := NewOperation("ns", prefix, 1, p, nil, WithGeneration(0))
+			cached.Key(1)
+
+			gen, err := op.BumpGeneration()
+			if err != nil || gen != 1 {
+				t.Fatalf("BumpGeneration() = %v, %v, want 1", gen, err)
+			}
+			if _, ok, _ := op.Key(1).TryGet(); ok {
+				t.Errorf("TryGet() after BumpGeneration() should miss")
+			}
+			if key, want := op.Key(1).Key, "ns:"+prefix+"#1_1"; key != want {
+				t.Errorf("Key() after BumpGeneration() = %v, want %v", key, want)
+			}
+			if key, want := fresh.Key(1).Key, "ns:"+prefix+"#1_1"; key != want {
+				t.Errorf("fresh Key() = %v, want %v", key, want)
+			}
+			if key, want := cached.Key(1).Key, "ns:"+prefix+"#0_1"; key != want {
+				t.Errorf("cached Key() = %v, want %v", key, want)
+			}
+		})
+	}
+}
+
+func TestOperation_WithGeneration_HashTag(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	op := NewOperation("ns", "prefix", 2, p, nil, WithHashTag(1), WithGeneration(time.Minute))
+	if key, want := op.Key("a", 1).Key, "{ns:prefix#0_a}_1"; key != want {
+		t.Errorf("Key() = %v, want %v", key, want)
+	}
+}
+
+func TestOperation_WithGeneration_LoadError(t *testing.T) {
+	// 无法连接的 redis ，代数读取失败。
+	cli := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	p := NewRedisCacheProvider(cli)
+	op := NewOperation1[int, int]("ns", "gen_error", p, nil, WithGeneration(time.Minute))
+
+	keyOp := op.Key(1)
+	if keyOp.Err() == nil {
+		t.Fatalf("Err() should not be nil")
+	}
+	if _, _, err := keyOp.TryGet(); err != keyOp.Err() {
+		t.Errorf("TryGet() error = %v, want %v", err, keyOp.Err())
+	}
+	if err := keyOp.Set(1); err != keyOp.Err() {
+		t.Errorf("Set() error = %v, want %v", err, keyOp.Err())
+	}
+	if _, err := keyOp.Remove(); err != keyOp.Err() {
+		t.Errorf("Remove() error = %v, want %v", err, keyOp.Err())
+	}
+
+	series := NewTimeSeriesCounter("ns", "gen_error", 0, ResolutionMinute, nil, p, nil, WithGeneration(time.Minute)).Key()
+	if _, err := series.Sum(time.Now(), time.Now()); err == nil {
+		t.Errorf("Sum() should return the error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustTryGet() should panic")
+		}
+	}()
+	keyOp.MustTryGet()
+}
+
+func TestOperation_BumpGeneration_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("BumpGeneration() without WithGeneration should panic")
+		}
+	}()
+	NewOperation("ns", "prefix", 0, NewMemoryCacheProvider(time.Second), nil).BumpGeneration()
+}
+
+func TestWithGeneration_Level2(t *testing.T) {
+	defer func() {
+		if err, _ := recover().(error); err == nil || !strings.Contains(err.Error(), "IncreaseOrCreate") {
+			t.Errorf("WithGeneration() with Level2CacheProvider should panic, got %v", err)
+		}
+	}()
+	p := NewLevel2CacheProvider(NewMemoryCacheProvider(time.Second), NewMemoryCacheProvider(time.Second), CacheExpirationZero)
+	NewOperation("ns", "prefix", 0, p, nil, WithGeneration(time.Minute))
+}
@@ -5,12 +5,16 @@ go 1.18
 require (
 	github.com/cmstar/go-conv v0.3.1
 	github.com/go-redis/redis/v8 v8.10.0
+	github.com/klauspost/compress v1.15.15
 	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	google.golang.org/protobuf v1.28.1
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.1.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	go.opentelemetry.io/otel v0.20.0 // indirect
 	go.opentelemetry.io/otel/metric v0.20.0 // indirect
 	go.opentelemetry.io/otel/trace v0.20.0 // indirect
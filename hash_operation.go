@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// HashCacheProvider 是支持哈希结构的 CacheProvider ，一个 key 下存储多个字段，整个哈希共用过期时间。
+type HashCacheProvider interface {
+	CacheProvider
+
+	// HashTryGet 尝试获取哈希的指定字段。
+	//  @key: cache key.
+	//  @field: 字段。
+	//  @value: receive value.
+	// return: 若字段存在，value 被更新成对应值，返回 true；反之 value 值不做改变，返回 false。
+	HashTryGet(key, field string, value any) (bool, error)
+
+	// HashSet 设置或者更新哈希的指定字段。
+	//  @key: cache key.
+	//  @field: 字段。
+	//  @value: 字段的值。
+	//  @t: 过期时长， 0表不过期，只有哈希是新创建的才设置。
+	HashSet(key, field string, value any, t time.Duration) error
+
+	// HashRemove 移除哈希的指定字段，所有字段都被移除后，哈希也被移除。
+	//  @key: cache key.
+	//  @fields: 字段。
+	// return: 成功移除的字段个数。
+	HashRemove(key string, fields ...string) (int64, error)
+
+	// HashGetAll 获取哈希的所有字段。
+	//  @key: cache key.
+	//  @value: receive value, 必须是 map[string]T 的指针。
+	// return: 若哈希存在，value 被更新成所有字段，返回 true；反之 value 值不做改变，返回 false。
+	HashGetAll(key string, value any) (bool, error)
+
+	// HashIncreaseOrCreate 为哈希的指定字段的值增加一个增量(负数==减法)，如果不存在则创建该字段。
+	//  @key: cache key.
+	//  @field: 字段。
+	//  @increment: 增量，如果字段不存在，则当成字段的值。
+	//  @t: 过期时长， 0表不过期，只有哈希是新创建的才设置。
+	// return: 返回增加后的值。
+	HashIncreaseOrCreate(key, field string, increment int64, t time.Duration) (int64, error)
+}
+
+// HashOperation 哈希缓存操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，
+// 前 uniqueFlagLen-1 个 unique flag 用于拼接哈希的 key ，最后一个 unique flag 作为哈希的字段。
+type HashOperation[T any] struct {
+	op Operation
+	p  HashCacheProvider
+}
+
+// NewHashOperation 创建一个哈希缓存操作对象。
+// expireTime: 整个哈希的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数(>=1)，最后一个元素作为哈希的字段。
+// cacheProvider: 必须实现 HashCacheProvider 。
+func NewHashOperation[T any](
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *HashOperation[T] {
+	if uniqueFlagLen < 1 {
+		panic(fmt.Errorf(`'uniqueFlagLen' must not be less than 1`))
+	}
+
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen-1, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(HashCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement HashCacheProvider", op.cacheProvider))
+	}
+
+	return &HashOperation[T]{*op, p}
+}
+
+// Key 获取指定哈希的缓存操作对象。
+//  @keys: 前 uniqueFlagLen-1 个 unique flag 。
+func (c *HashOperation[T]) Key(keys ...any) *HashKeyOperation[T] {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen-1(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	key, err := c.op.buildCacheKey(keys...)
+	return &HashKeyOperation[T]{
+		p:   c.p,
+		exp: c.op.expireTime,
+		Key: key,
+		err: err,
+	}
+}
+
+// HashKeyOperation 哈希缓存 key 的操作对象。
+// 字段受支持的类型与 unique flag 相同: bool, int*, uint*, float*, string, time.time, UnixTime 。
+type HashKeyOperation[T any] struct {
+	p   HashCacheProvider
+	exp *Expiration
+	err error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// Get 获取指定字段的值，字段不存在时返回 T 的零值。
+func (keyOp *HashKeyOperation[T]) Get(field any) (T, error) {
+	v, _, err := keyOp.TryGet(field)
+	return v, err
+}
+
+// MustGet 是 Get 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustGet(field any) T {
+	v, err := keyOp.Get(field)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// TryGet 尝试获取指定字段的值。
+// 若字段存在，返回对应值和 true，反之返回 false。
+func (keyOp *HashKeyOperation[T]) TryGet(field any) (T, bool, error) {
+	var v T
+	if keyOp.err != nil {
+		return v, false, keyOp.err
+	}
+
+	result, err := keyOp.p.HashTryGet(keyOp.Key, oneKeyToStr(field), &v)
+	return v, result, err
+}
+
+// MustTryGet 是 TryGet 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustTryGet(field any) (T, bool) {
+	v, result, err := keyOp.TryGet(field)
+	if err != nil {
+		panic(err)
+	}
+	return v, result
+}
+
+// Set 设置或者更新指定字段的值，哈希是新创建的时候，设置整个哈希的过期时间。
+func (keyOp *HashKeyOperation[T]) Set(field any, value T) error {
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	return keyOp.p.HashSet(keyOp.Key, oneKeyToStr(field), value, keyOp.exp.NextExpireTime())
+}
+
+// MustSet 是 Set 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustSet(field any, value T) {
+	err := keyOp.Set(field, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Remove 移除指定字段。
+//  return: 成功移除的字段个数。
+func (keyOp *HashKeyOperation[T]) Remove(fields ...any) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	fs := make([]string, len(fields))
+	for i, f := range fields {
+		fs[i] = oneKeyToStr(f)
+	}
+	return keyOp.p.HashRemove(keyOp.Key, fs...)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustRemove(fields ...any) int64 {
+	result, err := keyOp.Remove(fields...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RemoveAll 移除整个哈希。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *HashKeyOperation[T]) RemoveAll() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemoveAll 是 RemoveAll 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustRemoveAll() bool {
+	result, err := keyOp.RemoveAll()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetAll 获取所有字段，哈希不存在时返回空的 map 。
+func (keyOp *HashKeyOperation[T]) GetAll() (map[string]T, error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	v := make(map[string]T)
+	_, err := keyOp.p.HashGetAll(keyOp.Key, &v)
+	return v, err
+}
+
+// MustGetAll 是 GetAll 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustGetAll() map[string]T {
+	v, err := keyOp.GetAll()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// IncreaseOrCreate 为指定字段的值增加一个增量(负数==减法)，如果不存在则创建该字段。
+//  @increment: 增量，如果字段不存在，则当成字段的值。
+// return: 返回增加后的值。
+func (keyOp *HashKeyOperation[T]) IncreaseOrCreate(field any, increment int64) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.HashIncreaseOrCreate(keyOp.Key, oneKeyToStr(field), increment, keyOp.exp.NextExpireTime())
+}
+
+// MustIncreaseOrCreate 是 IncreaseOrCreate 的 panic 版。
+func (keyOp *HashKeyOperation[T]) MustIncreaseOrCreate(field any, increment int64) int64 {
+	result, err := keyOp.IncreaseOrCreate(field, increment)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// setMapValue 将 fields 的每个值解码后写入 value 。
+//  @value: 必须是 map[string]T 的指针。
+//  @decode: 将 item 解码到 elem（*T）。
+func setMapValue[E any](value any, fields map[string]E, decode func(item E, elem any) error) error {
+	ptr := reflect.ValueOf(value)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() ||
+		ptr.Elem().Kind() != reflect.Map || ptr.Elem().Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("value must be a non-nil pointer to map[string]T, got %T", value)
+	}
+
+	mapT := ptr.Elem().Type()
+	m := reflect.MakeMapWithSize(mapT, len(fields))
+	for field, item := range fields {
+		elem := reflect.New(mapT.Elem())
+		if err := decode(item, elem.Interface()); err != nil {
+			if errors.Is(err, ErrCacheMiss) { // 编解码器要求视为不存在。
+				continue
+			}
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(field).Convert(mapT.Key()), elem.Elem())
+	}
+
+	ptr.Elem().Set(m)
+	return nil
+}
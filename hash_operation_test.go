@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHashOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := "hash_" + fmt.Sprint(rand.Int31())
+			op := NewHashOperation[Person]("ns", prefix, 2, p, NewExpiration(time.Minute, 0))
+			keyOp := op.Key(1)
+			defer keyOp.RemoveAll()
+
+			if got := keyOp.Key; got != "ns:"+prefix+"_1" {
+				t.Fatalf("Key() = %v", got)
+			}
+
+			if _, ok := keyOp.MustTryGet("tom"); ok {
+				t.Fatalf("TryGet() on missing hash should return false")
+			}
+			if got := keyOp.MustGetAll(); len(got) != 0 {
+				t.Fatalf("GetAll() on missing hash = %v, want empty", got)
+			}
+
+			keyOp.MustSet("tom", Person{"Tom", 1})
+			keyOp.MustSet("jerry", Person{"Jerry", 2})
+			keyOp.MustSet("jerry", data.Person)
+
+			if v, ok := keyOp.MustTryGet("tom"); !ok || v != (Person{"Tom", 1}) {
+				t.Errorf("TryGet() = %v, %v", v, ok)
+			}
+
+			want := map[string]Person{"tom": {"Tom", 1}, "jerry": data.Person}
+			if got := keyOp.MustGetAll(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetAll() = %v, want %v", got, want)
+			}
+
+			if n := keyOp.MustRemove("tom", "not_exists"); n != 1 {
+				t.Errorf("Remove() = %v, want 1", n)
+			}
+			if n := keyOp.MustRemove("jerry"); n != 1 {
+				t.Errorf("Remove() = %v, want 1", n)
+			}
+
+			// 所有字段都被移除后，哈希也被移除。
+			if keyOp.MustRemoveAll() {
+				t.Errorf("RemoveAll() on empty hash should return false")
+			}
+		})
+	}
+}
+
+func TestHashOperation_IncreaseOrCreate(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := "hash_" + fmt.Sprint(rand.Int31())
+			op := NewHashOperation[int64]("ns", prefix, 1, p, nil)
+			keyOp := op.Key()
+			defer keyOp.RemoveAll()
+
+			if v := keyOp.MustIncreaseOrCreate("a", 5); v != 5 {
+				t.Errorf("IncreaseOrCreate() = %v, want 5", v)
+			}
+			if v := keyOp.MustIncreaseOrCreate("a", -2); v != 3 {
+				t.Errorf("IncreaseOrCreate() = %v, want 3", v)
+			}
+			if v := keyOp.MustGet("a"); v != 3 {
+				t.Errorf("Get() = %v, want 3", v)
+			}
+
+			keyOp.MustSet("b", 10)
+			if v := keyOp.MustIncreaseOrCreate("b", 1); v != 11 {
+				t.Errorf("IncreaseOrCreate() = %v, want 11", v)
+			}
+		})
+	}
+}
+
+// TestHashOperation_TTL 整个哈希共用过期时间，只有哈希是新创建的才设置。
+func TestHashOperation_TTL(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		p := NewMemoryCacheProvider(time.Second)
+		op := NewHashOperation[int]("ns", "hash_ttl", 2, p, NewExpiration(time.Minute, 0))
+		keyOp := op.Key(1)
+
+		keyOp.MustSet("a", 1)
+		_, exp1, _ := p.cache.GetWithExpiration(keyOp.Key)
+		time.Sleep(10 * time.Millisecond)
+		keyOp.MustSet("b", 2)
+		keyOp.MustIncreaseOrCreate("c", 1)
+		_, exp2, _ := p.cache.GetWithExpiration(keyOp.Key)
+
+		if exp1.IsZero() || !exp1.Equal(exp2) {
+			t.Errorf("expiration changed: %v -> %v", exp1, exp2)
+		}
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		p := getNewEveryTime()
+		ctx := context.Background()
+		prefix := "hash_" + fmt.Sprint(rand.Int31())
+
+		op := NewHashOperation[int]("ns", prefix, 2, p, NewExpiration(time.Minute, 0))
+		keyOp := op.Key(1)
+		defer keyOp.RemoveAll()
+
+		keyOp.MustSet("a", 1)
+		if ttl := p.client.PTTL(ctx, keyOp.Key).Val(); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("Set() ttl = %v, want <= 1m", ttl)
+		}
+
+		p.client.Persist(ctx, keyOp.Key)
+		keyOp.MustSet("b", 2)
+		keyOp.MustIncreaseOrCreate("c", 1)
+		if ttl := p.client.PTTL(ctx, keyOp.Key).Val(); ttl > 0 {
+			t.Errorf("ttl = %v, want no expiration", ttl)
+		}
+	})
+}
+
+func TestHashOperation_WrongType(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := "hash_" + fmt.Sprint(rand.Int31())
+			keyOp := NewHashOperation[int]("ns", prefix, 1, p, nil).Key()
+			defer p.Remove(keyOp.Key)
+			p.Set(keyOp.Key, 1, time.Minute)
+
+			if _, _, err := keyOp.TryGet("a"); err == nil {
+				t.Errorf("TryGet() on non-hash key should return error")
+			}
+			if err := keyOp.Set("a", 1); err == nil {
+				t.Errorf("Set() on non-hash key should return error")
+			}
+		})
+	}
+}
+
+func TestNewHashOperation_Panic(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagLen  int
+		provider CacheProvider
+	}{
+		{"flag_len", 0, NewMemoryCacheProvider(time.Second)},
+		{"unsupported", 1, unsupportedProvider{NewMemoryCacheProvider(time.Second)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewHashOperation() should panic")
+				}
+			}()
+			NewHashOperation[int]("ns", "prefix", tt.flagLen, tt.provider, nil)
+		})
+	}
+}
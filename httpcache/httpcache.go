@@ -0,0 +1,241 @@
+// Package httpcache 提供基于 cache.CacheProvider 的 HTTP 响应缓存。
+//
+// 响应的状态码、响应头和响应体保存在缓存中，缓存时长遵循响应的 Cache-Control ，
+// 响应包含 Vary 时，按照 Vary 指定的请求头的值分别缓存。
+// 服务端使用 Middleware 作为共享缓存，客户端使用 Transport 作为私有缓存。
+package httpcache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+// Response 缓存的响应。
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// Date 保存响应的时间（unix 秒）。
+	Date int64
+}
+
+// entry 请求对应的缓存项。
+// 响应没有 Vary 时，响应直接保存在 Response ；反之 Vary 是排序后的请求头名称，
+// 响应按照这些请求头的值保存在另一个缓存 key 中。
+type entry struct {
+	Vary     []string
+	Response *Response
+}
+
+// store 按照 Vary 分两级保存响应。
+type store struct {
+	op, varyOp *cache.Operation
+	p          cache.CacheProvider
+}
+
+// newStore 创建响应的存储，uniqueFlagLen 是不包括 Vary 摘要的 unique flag 个数。
+func newStore(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider cache.CacheProvider,
+	opts []cache.OperationOption,
+) *store {
+	op := cache.NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, nil, opts...)
+	return &store{
+		op:     op,
+		varyOp: cache.NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen+1, cacheProvider, nil, opts...),
+		p:      op.CacheProvider(), // 应用了 opts 中的编解码器。
+	}
+}
+
+// get 获取请求对应的响应。
+//  return: 缓存不存在时返回 nil 。
+func (s *store) get(r *http.Request, flags []any) (*Response, error) {
+	key, err := cacheKey(s.op, flags...)
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	found, err := s.p.TryGet(key, &e)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	if len(e.Vary) == 0 {
+		return e.Response, nil
+	}
+
+	if key, err = cacheKey(s.varyOp, append(flags, varyFlag(r, e.Vary))...); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	found, err = s.p.TryGet(key, &resp)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// set 保存请求对应的响应。
+//  return: 响应的 Vary 包含 * 时不能缓存，返回 false 。
+func (s *store) set(r *http.Request, flags []any, resp *Response, ttl time.Duration) (bool, error) {
+	vary, ok := parseVary(resp.Header)
+	if !ok {
+		return false, nil
+	}
+
+	key, err := cacheKey(s.op, flags...)
+	if err != nil {
+		return false, err
+	}
+	if len(vary) == 0 {
+		return true, s.p.Set(key, &entry{Response: resp}, ttl)
+	}
+
+	varyKey, err := cacheKey(s.varyOp, append(flags, varyFlag(r, vary))...)
+	if err != nil {
+		return false, err
+	}
+	if err := s.p.Set(key, &entry{Vary: vary}, ttl); err != nil {
+		return false, err
+	}
+	return true, s.p.Set(varyKey, resp, ttl)
+}
+
+// remove 移除请求对应的响应，按照 Vary 保存的响应随之不可访问，在过期后移除。
+func (s *store) remove(flags []any) error {
+	key, err := cacheKey(s.op, flags...)
+	if err != nil {
+		return err
+	}
+	_, err = s.p.Remove(key)
+	return err
+}
+
+// cacheKey 获取缓存key，使用 WithGeneration 时可能因为读取代数失败而返回错误。
+func cacheKey(op *cache.Operation, flags ...any) (string, error) {
+	keyOp := op.Key(flags...)
+	return keyOp.Key, keyOp.Err()
+}
+
+// cacheableStatus 默认可以缓存的状态码。
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// parseCacheControl 解析所有的 Cache-Control 头，指令名称转换成小写。
+func parseCacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+
+			name, value, _ := strings.Cut(d, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+// seconds 读取以秒为单位的指令值，例如 max-age 。
+func seconds(cc map[string]string, name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, true // 非法的值视为已经过期。
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// parseVary 获取响应的 Vary 指定的请求头名称，规范化并排序。
+//  return: 包含 * 时返回 false ，表示响应不能缓存。
+func parseVary(h http.Header) ([]string, bool) {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, true
+}
+
+// varyFlag 根据 Vary 指定的请求头的值计算缓存 key 的 unique flag 。
+func varyFlag(r *http.Request, vary []string) string {
+	h := sha1.New()
+	for _, name := range vary {
+		h.Write([]byte(name + ":" + strings.Join(r.Header.Values(name), ",") + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// flag 转义作为 unique flag 的字符串，避免其中的 '_' 与缓存 key 的分隔符混淆。
+func flag(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "_", "%5F")
+}
+
+// etagMatch 判断 If-None-Match 是否匹配 etag ，使用弱比较。
+func etagMatch(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified 判断条件请求是否可以返回 304 Not Modified ，If-None-Match 优先于 If-Modified-Since 。
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatch(inm, h.Get("ETag"))
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.After(ims)
+}
+
+// notModifiedHeaders 304 响应需要包含的响应头。
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Last-Modified", "Vary"}
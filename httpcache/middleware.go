@@ -0,0 +1,157 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/httprecord"
+)
+
+// ServerCache 服务端的响应缓存，作为共享缓存，不缓存带有 Authorization 的请求，
+// 以及带有 Set-Cookie 或者 Cache-Control: private/no-cache/no-store 的响应。
+// 只缓存 GET 请求，缓存key为 <CacheNamespace>:<Prefix>_<method>_<path>[_<query param>...][_<header>...] ，
+// 响应包含 Vary 时，在末尾再增加一段 Vary 指定的请求头的值的摘要。
+type ServerCache struct {
+	s           *store
+	ttl         time.Duration
+	queryParams []string
+	headers     []string
+}
+
+// NewServerCache 创建一个服务端的响应缓存。
+//  @ttl: 响应没有指定 Cache-Control 的 s-maxage 或者 max-age 时的缓存时长， 0 表示这样的响应不缓存。
+//  @queryParams: 参与构建缓存 key 的查询参数，其他的查询参数不影响缓存。
+//  @headers: 参与构建缓存 key 的请求头，例如 Accept-Language 。
+func NewServerCache(
+	cacheNamespace, keyPrefix string,
+	cacheProvider cache.CacheProvider,
+	ttl time.Duration,
+	queryParams, headers []string,
+	opts ...cache.OperationOption,
+) *ServerCache {
+	if ttl < 0 {
+		panic(fmt.Errorf("param 'ttl' must not be less than 0"))
+	}
+
+	n := 2 + len(queryParams) + len(headers)
+	return &ServerCache{
+		s:           newStore(cacheNamespace, keyPrefix, n, cacheProvider, opts),
+		ttl:         ttl,
+		queryParams: queryParams,
+		headers:     headers,
+	}
+}
+
+// flags 获取请求的缓存 key 的 unique flag 。
+func (c *ServerCache) flags(r *http.Request) []any {
+	flags := make([]any, 0, 2+len(c.queryParams)+len(c.headers))
+	flags = append(flags, r.Method, flag(r.URL.Path))
+
+	query := r.URL.Query()
+	for _, name := range c.queryParams {
+		flags = append(flags, flag(query.Get(name)))
+	}
+	for _, name := range c.headers {
+		flags = append(flags, flag(r.Header.Get(name)))
+	}
+	return flags
+}
+
+// Get 获取请求对应的缓存响应。
+//  return: 缓存不存在时返回 nil 。
+func (c *ServerCache) Get(r *http.Request) (*Response, error) {
+	return c.s.get(r, c.flags(r))
+}
+
+// Set 按照响应的 Cache-Control 和 Vary 缓存请求对应的响应。
+//  return: true 响应被缓存；false 响应不能缓存。
+func (c *ServerCache) Set(r *http.Request, resp *Response) (bool, error) {
+	ttl, ok := c.storable(resp)
+	if !ok {
+		return false, nil
+	}
+
+	return c.s.set(r, c.flags(r), resp, ttl)
+}
+
+// storable 判断响应是否可以缓存，并获取缓存时长。
+func (c *ServerCache) storable(resp *Response) (time.Duration, bool) {
+	if !cacheableStatus[resp.Status] || len(resp.Header.Values("Set-Cookie")) > 0 {
+		return 0, false
+	}
+
+	cc := parseCacheControl(resp.Header)
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[d]; ok {
+			return 0, false
+		}
+	}
+
+	ttl, ok := seconds(cc, "s-maxage")
+	if !ok {
+		if ttl, ok = seconds(cc, "max-age"); !ok {
+			ttl = c.ttl
+		}
+	}
+	return ttl, ttl > 0
+}
+
+// Middleware 返回 net/http 中间件，缓存 GET 请求的响应。
+// 命中缓存时直接返回缓存的响应，并带有 Age 响应头；条件请求的 If-None-Match 或者 If-Modified-Since
+// 与缓存的 ETag 或者 Last-Modified 匹配时返回 304 Not Modified 。
+// 请求带有 Cache-Control: no-cache 时不读取缓存，带有 no-store 时既不读取也不保存。
+// 缓存出错时视为没有缓存，不影响请求的处理。
+func Middleware(c *ServerCache) func(http.Handler) http.Handler {
+	if c == nil {
+		panic(fmt.Errorf("param 'c' must not be nil"))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cc := parseCacheControl(r.Header)
+			_, noStore := cc["no-store"]
+			if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" || noStore {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if _, noCache := cc["no-cache"]; !noCache {
+				if resp, _ := c.Get(r); resp != nil {
+					serve(w, r, resp)
+					return
+				}
+			}
+
+			rec := httprecord.New(w)
+			next.ServeHTTP(rec, r)
+			status, header, body := rec.Result()
+			c.Set(r, &Response{status, header, body, time.Now().Unix()})
+		})
+	}
+}
+
+// serve 返回缓存的响应。
+func serve(w http.ResponseWriter, r *http.Request, resp *Response) {
+	h := w.Header()
+	if notModified(r, resp.Header) {
+		for _, name := range notModifiedHeaders {
+			if v := resp.Header.Values(name); len(v) > 0 {
+				h[http.CanonicalHeaderKey(name)] = v
+			}
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+	if age := time.Now().Unix() - resp.Date; age >= 0 {
+		h.Set("Age", strconv.FormatInt(age, 10))
+	}
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
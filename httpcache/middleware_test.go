@@ -0,0 +1,153 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	lastModified := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			c := NewServerCache("ns", cachetest.Prefix("httpcache"), p, time.Minute, []string{"page"}, []string{"X-Tenant"})
+
+			calls := map[string]int{}
+			handler := Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls[r.URL.Path]++
+				h := w.Header()
+				switch r.URL.Path {
+				case "/no-store":
+					h.Set("Cache-Control", "no-store")
+				case "/max-age-0":
+					h.Set("Cache-Control", "public, max-age=0")
+				case "/vary":
+					h.Set("Vary", "accept-language")
+				case "/cookie":
+					h.Set("Set-Cookie", "a=1")
+				case "/error":
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				h.Set("ETag", `"v1"`)
+				h.Set("Last-Modified", lastModified)
+				fmt.Fprintf(w, "%s %s %s", r.URL.Path, r.URL.Query().Get("page"), r.Header.Get("Accept-Language"))
+			}))
+
+			serve := func(method, target string, header ...string) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(method, target, nil)
+				for i := 0; i < len(header); i += 2 {
+					r.Header.Set(header[i], header[i+1])
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				return w
+			}
+
+			// 命中缓存，不参与 key 的查询参数不影响缓存。
+			first := serve("GET", "/items?page=1&ts=1")
+			hit := serve("GET", "/items?page=1&ts=2")
+			if hit.Code != http.StatusOK || hit.Body.String() != first.Body.String() || hit.Header().Get("Age") == "" || first.Header().Get("Age") != "" {
+				t.Errorf("hit: code = %v, body = %q, headers = %v", hit.Code, hit.Body, hit.Header())
+			}
+			serve("GET", "/items?page=2")
+			serve("GET", "/items?page=1", "X-Tenant", "t2")
+			serve("HEAD", "/items?page=1")
+			if calls["/items"] != 4 {
+				t.Errorf("calls = %d, want 4", calls["/items"])
+			}
+
+			// 请求的 no-cache 跳过缓存。
+			serve("GET", "/items?page=1", "Cache-Control", "no-cache")
+			if calls["/items"] != 5 {
+				t.Errorf("calls with no-cache = %d, want 5", calls["/items"])
+			}
+
+			// 不能缓存的响应。
+			for _, path := range []string{"/no-store", "/max-age-0", "/cookie", "/error"} {
+				serve("GET", path)
+				serve("GET", path)
+				if calls[path] != 2 {
+					t.Errorf("%s calls = %d, want 2", path, calls[path])
+				}
+			}
+			serve("GET", "/auth", "Authorization", "Bearer x")
+			serve("GET", "/auth", "Authorization", "Bearer x")
+			if calls["/auth"] != 2 {
+				t.Errorf("/auth calls = %d, want 2", calls["/auth"])
+			}
+
+			// Vary 按照请求头分别缓存。
+			zh := serve("GET", "/vary", "Accept-Language", "zh")
+			en := serve("GET", "/vary", "Accept-Language", "en")
+			zh2 := serve("GET", "/vary", "Accept-Language", "zh")
+			if calls["/vary"] != 2 || zh.Body.String() != zh2.Body.String() || zh.Body.String() == en.Body.String() {
+				t.Errorf("vary: calls = %d, bodies = %q, %q, %q", calls["/vary"], zh.Body, en.Body, zh2.Body)
+			}
+
+			// 条件请求。
+			if w := serve("GET", "/items?page=1", "If-None-Match", `W/"v0", "v1"`); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != `"v1"` {
+				t.Errorf("If-None-Match: code = %v, headers = %v", w.Code, w.Header())
+			}
+			if w := serve("GET", "/items?page=1", "If-None-Match", `"v0"`); w.Code != http.StatusOK {
+				t.Errorf("If-None-Match mismatch: code = %v", w.Code)
+			}
+			if w := serve("GET", "/items?page=1", "If-Modified-Since", lastModified); w.Code != http.StatusNotModified {
+				t.Errorf("If-Modified-Since: code = %v", w.Code)
+			}
+			if calls["/items"] != 5 {
+				t.Errorf("calls after conditional requests = %d, want 5", calls["/items"])
+			}
+		})
+	}
+}
+
+func TestServerCache_Storable(t *testing.T) {
+	c := NewServerCache("ns", "p", cache.NewMemoryCacheProvider(time.Second), 0, nil, nil)
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+	}{
+		{"", 0},
+		{"max-age=60", time.Minute},
+		{"max-age=60, s-maxage=10", 10 * time.Second},
+		{`public, max-age="30"`, 30 * time.Second},
+		{"max-age=abc", 0},
+		{"private, max-age=60", 0},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.cacheControl != "" {
+			h.Set("Cache-Control", tt.cacheControl)
+		}
+		got, ok := c.storable(&Response{Status: http.StatusOK, Header: h})
+		if got != tt.want || ok != (tt.want > 0) {
+			t.Errorf("storable(%q) = %v, %v, want %v", tt.cacheControl, got, ok, tt.want)
+		}
+	}
+}
+
+func TestServerCache_WithCodec(t *testing.T) {
+	p := cache.NewMemoryCacheProvider(time.Second)
+	c := NewServerCache("ns", cachetest.Prefix("httpcache"), p, time.Minute, nil, nil, cache.WithCodec(cache.GobCodec))
+	r := httptest.NewRequest("GET", "/items", nil)
+	want := &Response{Status: http.StatusOK, Header: http.Header{}, Body: []byte("ok")}
+	if ok, err := c.Set(r, want); !ok || err != nil {
+		t.Fatalf("Set() = %v, %v", ok, err)
+	}
+
+	// 响应使用 opts 指定的编解码器保存，而不是原始的缓存提供器。
+	var raw []byte
+	if found, err := p.TryGet(c.s.op.Key(c.flags(r)...).Key, &raw); !found || err != nil || len(raw) == 0 {
+		t.Errorf("raw value = %v, %v, %v, want encoded bytes", raw, found, err)
+	}
+	if got, err := c.Get(r); err != nil || got == nil || string(got.Body) != "ok" {
+		t.Errorf("Get() = %v, %v", got, err)
+	}
+}
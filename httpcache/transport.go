@@ -0,0 +1,287 @@
+package httpcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+// defaultRetention 默认的过期响应保留时长，用于条件请求验证。
+const defaultRetention = 24 * time.Hour
+
+// Transport 客户端的响应缓存，实现 http.RoundTripper ，作为私有缓存按照 RFC 7234 缓存 GET 请求的响应。
+//  - 新鲜度按照 Cache-Control 的 max-age 、 Expires 、 Last-Modified 依次计算，响应带有 no-cache 时总是需要验证；
+//  - 过期的响应带有 ETag 或者 Last-Modified 时，使用 If-None-Match 或者 If-Modified-Since 发起条件请求，
+//    服务端返回 304 Not Modified 时更新并返回缓存的响应；
+//  - 只缓存 GET 请求，其他方法的请求不读取也不保存缓存，成功时移除相同 URL 的缓存。
+// 缓存key为 <CacheNamespace>:<Prefix>_<url> ，响应包含 Vary 时，在末尾再增加一段 Vary 指定的请求头的值的摘要。
+// 从缓存返回的响应带有 Age 响应头和 X-From-Cache: 1 响应头。
+type Transport struct {
+	s         *store
+	base      http.RoundTripper
+	hostTTL   map[string]time.Duration
+	retention time.Duration
+	now       func() time.Time // 获取当前时间，便于测试。
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport 创建一个客户端的响应缓存。
+//  @base: 实际发送请求的 http.RoundTripper ， nil 表示使用 http.DefaultTransport 。
+func NewTransport(
+	cacheNamespace, keyPrefix string,
+	cacheProvider cache.CacheProvider,
+	base http.RoundTripper,
+	opts ...cache.OperationOption,
+) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		s:         newStore(cacheNamespace, keyPrefix, 1, cacheProvider, opts),
+		base:      base,
+		retention: defaultRetention,
+		now:       time.Now,
+	}
+}
+
+// WithHostTTL 返回一个对指定主机强制使用固定缓存时长的 Transport ，用于没有返回缓存相关响应头的第三方接口。
+// 该主机的可缓存的响应忽略响应的 Cache-Control 和 Expires ，在 ttl 内直接使用缓存。
+//  @host: 主机名，不含端口，与 URL.Hostname() 比较。
+func (t *Transport) WithHostTTL(host string, ttl time.Duration) *Transport {
+	if ttl <= 0 {
+		panic(fmt.Errorf("param 'ttl' must be greater than 0"))
+	}
+
+	hostTTL := make(map[string]time.Duration, len(t.hostTTL)+1)
+	for h, d := range t.hostTTL {
+		hostTTL[h] = d
+	}
+	hostTTL[host] = ttl
+
+	cp := *t
+	cp.hostTTL = hostTTL
+	return &cp
+}
+
+// WithRetention 返回一个使用指定保留时长的 Transport ，
+// 带有 ETag 或者 Last-Modified 的响应在过期后继续保留 retention ，用于条件请求验证，默认 24 小时。
+func (t *Transport) WithRetention(retention time.Duration) *Transport {
+	if retention < 0 {
+		panic(fmt.Errorf("param 'retention' must not be less than 0"))
+	}
+
+	cp := *t
+	cp.retention = retention
+	return &cp
+}
+
+// flags 获取请求的缓存 key 的 unique flag 。
+func (t *Transport) flags(req *http.Request) []any {
+	u := *req.URL
+	u.Fragment = ""
+	return []any{flag(u.String())}
+}
+
+// implement http.RoundTripper.RoundTrip .
+// 缓存出错时视为没有缓存，不影响请求的发送。
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		res, err := t.base.RoundTrip(req)
+		if err == nil && res.StatusCode < http.StatusBadRequest {
+			t.s.remove(t.flags(req))
+		}
+		return res, err
+	}
+
+	cc := parseCacheControl(req.Header)
+	if _, noStore := cc["no-store"]; noStore || req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	flags := t.flags(req)
+	cached, _ := t.s.get(req, flags)
+	if cached == nil {
+		return t.fetch(req, flags)
+	}
+
+	_, noCache := cc["no-cache"]
+	if !noCache && t.fresh(req, cached) {
+		if notModified(req, cached.Header) {
+			return t.response(req, cached, http.StatusNotModified), nil
+		}
+		return t.response(req, cached, cached.Status), nil
+	}
+
+	// 调用方自己的条件请求，或者缓存没有验证器时直接发送请求。
+	etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	if conditional || (etag == "" && lastModified == "") {
+		return t.fetch(req, flags)
+	}
+
+	return t.revalidate(req, flags, cached, etag, lastModified)
+}
+
+// fetch 发送请求，并缓存可以缓存的响应。
+func (t *Transport) fetch(req *http.Request, flags []any) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.keep(req, flags, res)
+}
+
+// keep 缓存可以缓存的响应，需要读取完整的响应体。
+func (t *Transport) keep(req *http.Request, flags []any, res *http.Response) (*http.Response, error) {
+	resp := &Response{res.StatusCode, res.Header.Clone(), nil, t.now().Unix()}
+	if _, ok := t.storable(req, resp); !ok {
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp.Body = body
+	t.store(req, flags, resp)
+	return res, nil
+}
+
+// revalidate 使用缓存的验证器发起条件请求，服务端返回 304 Not Modified 时更新并返回缓存的响应。
+func (t *Transport) revalidate(req *http.Request, flags []any, cached *Response, etag, lastModified string) (*http.Response, error) {
+	creq := req.Clone(req.Context())
+	if etag != "" {
+		creq.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		creq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	res, err := t.base.RoundTrip(creq)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusNotModified {
+		t.s.remove(flags)
+		return t.keep(req, flags, res)
+	}
+	res.Body.Close()
+
+	// 使用 304 响应的响应头更新缓存的响应，不使用 Codec 的内存缓存返回的是共享的对象，需要复制后再修改。
+	updated := *cached
+	updated.Header = cached.Header.Clone()
+	for k, v := range res.Header {
+		if k != "Content-Length" {
+			updated.Header[k] = v
+		}
+	}
+	updated.Date = t.now().Unix()
+	t.store(req, flags, &updated)
+	return t.response(req, &updated, updated.Status), nil
+}
+
+// store 保存响应，带有验证器的响应在过期后继续保留 retention 。
+func (t *Transport) store(req *http.Request, flags []any, resp *Response) {
+	lifetime, ok := t.storable(req, resp)
+	if !ok {
+		return
+	}
+
+	ttl := lifetime
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+		ttl += t.retention
+	}
+	if ttl > 0 {
+		t.s.set(req, flags, resp, ttl)
+	}
+}
+
+// storable 判断响应是否可以缓存，并获取新鲜度的时长，时长为 0 的响应每次使用前都需要验证。
+func (t *Transport) storable(req *http.Request, resp *Response) (time.Duration, bool) {
+	if !cacheableStatus[resp.Status] {
+		return 0, false
+	}
+
+	if ttl, ok := t.hostTTL[req.URL.Hostname()]; ok {
+		return ttl, true
+	}
+
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return 0, false
+	}
+	return freshness(resp, cc), true
+}
+
+// fresh 判断缓存的响应是否仍然新鲜。
+func (t *Transport) fresh(req *http.Request, resp *Response) bool {
+	lifetime, ok := t.storable(req, resp)
+	age := time.Duration(t.now().Unix()-resp.Date) * time.Second
+	return ok && age < lifetime
+}
+
+// freshness 按照 Cache-Control 的 max-age 、 Expires 、 Last-Modified 依次计算响应的新鲜度时长。
+// 只有 Last-Modified 时使用启发式的新鲜度：距离最后修改时间的 10% 。没有 Date 时使用保存响应的时间。
+func freshness(resp *Response, cc map[string]string) time.Duration {
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+
+	if d, ok := seconds(cc, "max-age"); ok {
+		return d
+	}
+
+	h := resp.Header
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		date = time.Unix(resp.Date, 0)
+	}
+
+	if v := h.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil || expires.Before(date) {
+			return 0
+		}
+		return expires.Sub(date)
+	}
+
+	if lm, err := http.ParseTime(h.Get("Last-Modified")); err == nil && lm.Before(date) {
+		return date.Sub(lm) / 10
+	}
+	return 0
+}
+
+// response 使用缓存的响应构建 http.Response 。
+func (t *Transport) response(req *http.Request, resp *Response, status int) *http.Response {
+	h := resp.Header.Clone()
+	h.Set("Age", strconv.FormatInt(t.now().Unix()-resp.Date, 10))
+	h.Set("X-From-Cache", "1")
+
+	body := resp.Body
+	if status == http.StatusNotModified {
+		body = nil
+		h.Del("Content-Length")
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,220 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestTransport(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.Method+" "+r.URL.Path]++
+		n := calls[r.Method+" "+r.URL.Path]
+		mu.Unlock()
+
+		h := w.Header()
+		switch r.URL.Path {
+		case "/etag":
+			h.Set("Cache-Control", "max-age=60")
+			h.Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/expires":
+			h.Set("Expires", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		case "/no-store":
+			h.Set("Cache-Control", "no-store, max-age=60")
+		case "/changed":
+			h.Set("Cache-Control", "max-age=60")
+			h.Set("ETag", fmt.Sprintf(`"v%d"`, n))
+		}
+		fmt.Fprintf(w, "%s %d", r.URL.Path, n)
+	}))
+	defer srv.Close()
+
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			calls = map[string]int{}
+			mu.Unlock()
+
+			now := time.Now()
+			tr := NewTransport("ns", cachetest.Prefix("httpcache"), p, nil)
+			tr.now = func() time.Time { return now }
+			client := &http.Client{Transport: tr}
+
+			get := func(path string, header ...string) (*http.Response, string) {
+				req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+				for i := 0; i < len(header); i += 2 {
+					req.Header.Set(header[i], header[i+1])
+				}
+				res, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("GET %s error = %v", path, err)
+				}
+				defer res.Body.Close()
+				body, _ := io.ReadAll(res.Body)
+				return res, string(body)
+			}
+			count := func(key string) int {
+				mu.Lock()
+				defer mu.Unlock()
+				return calls[key]
+			}
+
+			// 新鲜的响应直接使用缓存。
+			_, body := get("/etag")
+			res, cached := get("/etag")
+			if cached != body || res.Header.Get("X-From-Cache") != "1" || count("GET /etag") != 1 {
+				t.Errorf("fresh: body = %q, headers = %v, calls = %d", cached, res.Header, count("GET /etag"))
+			}
+
+			// 调用方的条件请求命中新鲜的缓存时返回 304 。
+			if res, _ := get("/etag", "If-None-Match", `"v1"`); res.StatusCode != http.StatusNotModified {
+				t.Errorf("conditional: code = %v", res.StatusCode)
+			}
+
+			// 过期后使用 ETag 验证，304 时返回缓存的响应。
+			now = now.Add(61 * time.Second)
+			res, revalidated := get("/etag")
+			if res.StatusCode != http.StatusOK || revalidated != body || count("GET /etag") != 2 {
+				t.Errorf("revalidated: code = %v, body = %q, calls = %d", res.StatusCode, revalidated, count("GET /etag"))
+			}
+			if get("/etag"); count("GET /etag") != 2 {
+				t.Errorf("after revalidated calls = %d, want 2", count("GET /etag"))
+			}
+
+			// 验证时响应已经改变。
+			get("/changed")
+			now = now.Add(61 * time.Second)
+			if _, body := get("/changed"); body != "/changed 2" {
+				t.Errorf("changed: body = %q", body)
+			}
+			if _, body := get("/changed"); body != "/changed 2" || count("GET /changed") != 2 {
+				t.Errorf("changed cached: body = %q, calls = %d", body, count("GET /changed"))
+			}
+
+			// Expires 。
+			get("/expires")
+			get("/expires")
+			now = now.Add(61 * time.Second)
+			get("/expires")
+			if count("GET /expires") != 2 {
+				t.Errorf("expires calls = %d, want 2", count("GET /expires"))
+			}
+
+			// 不能缓存的响应和请求。
+			get("/no-store")
+			get("/no-store")
+			get("/etag", "Cache-Control", "no-store")
+			if count("GET /no-store") != 2 || count("GET /etag") != 3 {
+				t.Errorf("no-store calls = %d, %d", count("GET /no-store"), count("GET /etag"))
+			}
+
+			// 非幂等的请求不缓存，并移除相同 URL 的缓存。
+			for i := 0; i < 2; i++ {
+				res, err := client.PostForm(srv.URL+"/etag", url.Values{"a": {"1"}})
+				if err != nil {
+					t.Fatalf("POST error = %v", err)
+				}
+				res.Body.Close()
+			}
+			get("/etag")
+			if count("POST /etag") != 2 || count("GET /etag") != 4 {
+				t.Errorf("POST calls = %d, GET calls = %d", count("POST /etag"), count("GET /etag"))
+			}
+		})
+	}
+}
+
+func TestTransport_WithHostTTL(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			calls = 0
+			host := strings.Split(strings.TrimPrefix(srv.URL, "http://"), ":")[0]
+			tr := NewTransport("ns", cachetest.Prefix("httpcache"), p, nil).WithHostTTL(host, time.Minute)
+			now := time.Now()
+			tr.now = func() time.Time { return now }
+			client := &http.Client{Transport: tr}
+
+			for i := 0; i < 3; i++ {
+				res, err := client.Get(srv.URL + "/api")
+				if err != nil {
+					t.Fatalf("GET error = %v", err)
+				}
+				res.Body.Close()
+			}
+			now = now.Add(time.Minute)
+			res, _ := client.Get(srv.URL + "/api")
+			res.Body.Close()
+
+			if calls != 2 {
+				t.Errorf("calls = %d, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestTransport_ConcurrentRevalidate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Request", r.Header.Get("X-Request"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	// 不使用 Codec 的内存缓存，读取到的是同一个对象。
+	client := &http.Client{Transport: NewTransport("ns", cachetest.Prefix("httpcache"), cache.NewMemoryCacheProvider(time.Second), nil)}
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	res.Body.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			req.Header.Set("X-Request", fmt.Sprint(i))
+			res, err := client.Do(req)
+			if err != nil {
+				t.Errorf("GET error = %v", err)
+				return
+			}
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			if string(body) != "body" || res.Header.Get("X-Request") != fmt.Sprint(i) {
+				t.Errorf("GET = %q, X-Request = %q, want %d", body, res.Header.Get("X-Request"), i)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
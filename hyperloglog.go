@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// 与 redis 的 HyperLogLog 实现保持一致的参数，使两者的数据可以互相导入。
+const (
+	hllP         = 14             // 用于选择寄存器的哈希位数。
+	hllQ         = 64 - hllP      // 用于计算连续 0 个数的哈希位数。
+	hllRegisters = 1 << hllP      // 寄存器个数。
+	hllBits      = 6              // 每个寄存器的位数。
+	hllMaxValue  = 1<<hllBits - 1 // 寄存器的最大值。
+	hllHdrSize   = 16             // 头部的字节数。
+	hllDenseSize = hllHdrSize + (hllRegisters*hllBits+7)/8
+	hllAlphaInf  = 0.721347520444481703680 // 渐进修正常数。
+	hllSeed      = 0xadc83b19
+
+	hllDense  = 0 // 稠密编码。
+	hllSparse = 1 // 稀疏编码。
+)
+
+// hyperLogLog 纯 Go 实现的 HyperLogLog，哈希函数、寄存器的计算以及基数估计算法都与 redis 相同，
+// 序列化格式是 redis 的稠密编码，可以通过 SET 写入 redis 后使用 PFCOUNT 等命令读取。
+type hyperLogLog struct {
+	registers [hllRegisters]uint8
+}
+
+// add 添加元素。
+// return: 有寄存器被更新时返回 true，即基数的估计值可能发生了变化。
+func (h *hyperLogLog) add(element []byte) bool {
+	index, count := hllPatLen(element)
+	if h.registers[index] >= count {
+		return false
+	}
+	h.registers[index] = count
+	return true
+}
+
+// merge 合并另一个 HyperLogLog，合并后的基数为两者的并集。
+func (h *hyperLogLog) merge(o *hyperLogLog) {
+	for i, v := range o.registers {
+		if v > h.registers[i] {
+			h.registers[i] = v
+		}
+	}
+}
+
+// count 估计基数，算法与 redis 5.0 以后的 hllCount 相同。
+func (h *hyperLogLog) count() int64 {
+	var histogram [hllQ + 2]int
+	for _, v := range h.registers {
+		histogram[v]++
+	}
+
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histogram[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+
+	return int64(math.Round(hllAlphaInf * m * m / z))
+}
+
+// marshal 序列化成 redis 的稠密编码。
+func (h *hyperLogLog) marshal() []byte {
+	// 多一个字节，最后一个寄存器跨越的下一个字节不会越界。
+	b := make([]byte, hllDenseSize+1)
+	copy(b, "HYLL")
+	b[4] = hllDense
+
+	// 缓存的基数设置为无效，由 redis 重新计算。
+	b[15] = 0x80
+
+	registers := b[hllHdrSize:]
+	for i, v := range h.registers {
+		pos := i * hllBits
+		idx, fb := pos/8, uint(pos&7)
+		registers[idx] |= v << fb
+		registers[idx+1] |= v >> (8 - fb)
+	}
+	return b[:hllDenseSize]
+}
+
+// unmarshalHyperLogLog 解析 redis 的 HyperLogLog 数据，支持稠密编码和稀疏编码。
+func unmarshalHyperLogLog(b []byte) (*hyperLogLog, error) {
+	if len(b) < hllHdrSize || string(b[:4]) != "HYLL" {
+		return nil, fmt.Errorf("invalid HyperLogLog data: bad header")
+	}
+
+	h := &hyperLogLog{}
+	switch b[4] {
+	case hllDense:
+		if len(b) != hllDenseSize {
+			return nil, fmt.Errorf("invalid HyperLogLog data: dense size %d", len(b))
+		}
+
+		registers := b[hllHdrSize:]
+		for i := range h.registers {
+			pos := i * hllBits
+			idx, fb := pos/8, uint(pos&7)
+			v := registers[idx] >> fb
+			if idx+1 < len(registers) {
+				v |= registers[idx+1] << (8 - fb)
+			}
+			h.registers[i] = v & hllMaxValue
+		}
+
+	case hllSparse:
+		// 稀疏编码由三种操作码组成:
+		//  ZERO:  00xxxxxx            连续 xxxxxx+1 个值为 0 的寄存器。
+		//  XZERO: 01xxxxxx yyyyyyyy   连续 xxxxxxyyyyyyyy+1 个值为 0 的寄存器。
+		//  VAL:   1vvvvvxx            连续 xx+1 个值为 vvvvv+1 的寄存器。
+		idx := 0
+		for p := hllHdrSize; p < len(b); p++ {
+			op := b[p]
+			switch {
+			case op&0xc0 == 0x00:
+				idx += int(op&0x3f) + 1
+			case op&0xc0 == 0x40:
+				if p+1 >= len(b) {
+					return nil, fmt.Errorf("invalid HyperLogLog data: truncated sparse opcode")
+				}
+				p++
+				idx += int(op&0x3f)<<8 | int(b[p]) + 1
+			default:
+				v, n := (op>>2)&0x1f+1, int(op&0x03)+1
+				if idx+n > hllRegisters {
+					return nil, fmt.Errorf("invalid HyperLogLog data: sparse registers overflow")
+				}
+				for i := 0; i < n; i++ {
+					h.registers[idx+i] = v
+				}
+				idx += n
+			}
+		}
+
+		if idx != hllRegisters {
+			return nil, fmt.Errorf("invalid HyperLogLog data: sparse registers count %d", idx)
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid HyperLogLog data: unknown encoding %d", b[4])
+	}
+
+	return h, nil
+}
+
+// hllPatLen 计算元素对应的寄存器下标，以及哈希值剩余部分连续 0 的个数加 1 。
+func hllPatLen(element []byte) (int, uint8) {
+	hash := murmurHash64A(element, hllSeed)
+	index := int(hash & (hllRegisters - 1))
+
+	// 设置第 hllQ 位，保证计数不超过 hllQ+1 。
+	hash >>= hllP
+	hash |= 1 << hllQ
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+
+	y, z := 1.0, x
+	for {
+		x *= x
+		zPrime := z
+		z += x * y
+		y += y
+		if zPrime == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		zPrime := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if zPrime == z {
+			return z / 3
+		}
+	}
+}
+
+// murmurHash64A redis 的 HyperLogLog 使用的哈希函数，按小端序读取。
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ (uint64(len(data)) * m)
+
+	for len(data) >= 8 {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+
+		h ^= k
+		h *= m
+		data = data[8:]
+	}
+
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * uint(i))
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
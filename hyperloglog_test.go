@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHyperLogLog_Count(t *testing.T) {
+	h := &hyperLogLog{}
+	if n := h.count(); n != 0 {
+		t.Fatalf("count() on empty = %v, want 0", n)
+	}
+
+	for _, n := range []int{10, 1000, 100000} {
+		h := &hyperLogLog{}
+		for i := 0; i < n; i++ {
+			h.add([]byte(fmt.Sprint("member_", i)))
+			h.add([]byte(fmt.Sprint("member_", i))) // 重复的成员不影响计数。
+		}
+
+		got := h.count()
+		if e := math.Abs(float64(got)-float64(n)) / float64(n); e > 0.03 {
+			t.Errorf("count() = %v, want %v, error %.4f", got, n, e)
+		}
+	}
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	a, b := &hyperLogLog{}, &hyperLogLog{}
+	for i := 0; i < 1000; i++ {
+		a.add([]byte(fmt.Sprint(i)))
+		b.add([]byte(fmt.Sprint(i + 500)))
+	}
+
+	a.merge(b)
+	if got := a.count(); math.Abs(float64(got)-1500) > 45 {
+		t.Errorf("merge() count = %v, want about 1500", got)
+	}
+}
+
+func TestHyperLogLog_Marshal(t *testing.T) {
+	h := &hyperLogLog{}
+	for i := 0; i < 5000; i++ {
+		h.add([]byte(fmt.Sprint(i)))
+	}
+	h.registers[hllRegisters-1] = hllMaxValue // 最后一个寄存器跨越字节边界。
+
+	data := h.marshal()
+	if len(data) != hllDenseSize || !bytes.HasPrefix(data, []byte("HYLL")) {
+		t.Fatalf("marshal() len = %v, header = %q", len(data), data[:4])
+	}
+
+	got, err := unmarshalHyperLogLog(data)
+	if err != nil {
+		t.Fatalf("unmarshalHyperLogLog() error = %v", err)
+	}
+	if got.registers != h.registers {
+		t.Errorf("unmarshalHyperLogLog() registers mismatch")
+	}
+}
+
+func TestUnmarshalHyperLogLog_Sparse(t *testing.T) {
+	header := []byte{'H', 'Y', 'L', 'L', hllSparse, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	// redis 新建的空计数器: XZERO 16384 。
+	h, err := unmarshalHyperLogLog(append(header, 0x7f, 0xff))
+	if err != nil || h.count() != 0 {
+		t.Fatalf("unmarshalHyperLogLog() empty = %v, %v", h, err)
+	}
+
+	// ZERO 64, XZERO 36, VAL 3x2, XZERO 16282 。
+	data := append(header, 0x3f, 0x40, 35, 0x89, 0x7f, 0x99)
+	h, err = unmarshalHyperLogLog(data)
+	if err != nil {
+		t.Fatalf("unmarshalHyperLogLog() error = %v", err)
+	}
+	if h.registers[99] != 0 || h.registers[100] != 3 || h.registers[101] != 3 || h.registers[102] != 0 {
+		t.Errorf("unmarshalHyperLogLog() registers = %v", h.registers[98:104])
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad_header", []byte("HYLX")},
+		{"bad_encoding", append([]byte("HYLL"), 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)},
+		{"dense_size", append(append([]byte(nil), header[:4]...), hllDense, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1)},
+		{"sparse_count", append(append([]byte(nil), header...), 0x7f, 0xfe)},
+		{"sparse_truncated", append(append([]byte(nil), header...), 0x7f)},
+		{"sparse_overflow", append(append([]byte(nil), header...), 0x7f, 0xff, 0x80)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := unmarshalHyperLogLog(tt.data); err == nil {
+				t.Errorf("unmarshalHyperLogLog() should return error")
+			}
+		})
+	}
+}
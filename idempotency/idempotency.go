@@ -0,0 +1,107 @@
+// Package idempotency 提供基于 cache.CacheProvider 的幂等键存储，同一个幂等键的重复请求返回相同的响应。
+//
+// 请求开始处理前使用 Create 占用幂等键，记录为处理中；处理完成后保存最终的响应，
+// 之后相同幂等键的请求直接返回保存的响应，处理中的重复请求被拒绝。
+package idempotency
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+// ErrInProgress 相同幂等键的请求正在处理中。
+var ErrInProgress = errors.New("idempotency key is in progress")
+
+// Response 保存的响应。
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// record 缓存中的幂等键记录，Response 为 nil 表示处理中。
+type record struct {
+	Response *Response
+}
+
+// Store 幂等键存储。
+// 缓存key为 <CacheNamespace>:<Prefix>_<key> 。
+type Store struct {
+	op      *cache.Operation // 保存响应。
+	claimOp *cache.Operation // 占用幂等键，缓存key与 op 相同，过期时长为 inProgressTTL 。
+}
+
+// NewStore 创建一个幂等键存储。
+//
+//	@expireTime: 保存响应的过期时长，过期后相同的幂等键视为新的请求。
+//	@inProgressTTL: 处理中状态的过期时长，处理请求的节点异常退出时，幂等键在此之后可以重新使用，应当大于请求的最长处理时间。
+func NewStore(
+	cacheNamespace, keyPrefix string,
+	cacheProvider cache.CacheProvider,
+	expireTime *cache.Expiration,
+	inProgressTTL time.Duration,
+	opts ...cache.OperationOption,
+) *Store {
+	if inProgressTTL <= 0 {
+		panic(fmt.Errorf("param 'inProgressTTL' must be greater than 0"))
+	}
+
+	return &Store{
+		op:      cache.NewOperation(cacheNamespace, keyPrefix, 1, cacheProvider, expireTime, opts...),
+		claimOp: cache.NewOperation(cacheNamespace, keyPrefix, 1, cacheProvider, cache.NewExpiration(inProgressTTL, 0), opts...),
+	}
+}
+
+// Claim 占用幂等键。
+//
+//	@key: 幂等键，受支持的类型与 unique flag 相同。
+//	return: 占用成功时返回 (nil, nil) ，调用方处理请求后必须调用 Complete 或者 Abort ；
+//	幂等键已经完成时返回保存的响应；幂等键正在处理中时返回 ErrInProgress 。
+func (s *Store) Claim(key any) (*Response, error) {
+	keyOp := s.claimOp.Key(key)
+
+	// 读取和占用之间记录可能恰好过期，此时重试一次。
+	for i := 0; i < 2; i++ {
+		ok, err := keyOp.Create(&record{})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		var rec record
+		found, err := keyOp.TryGet(&rec)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		if rec.Response == nil {
+			return nil, ErrInProgress
+		}
+		return rec.Response, nil
+	}
+
+	return nil, ErrInProgress
+}
+
+// Complete 保存幂等键最终的响应，使用 NewStore 指定的过期时长。
+func (s *Store) Complete(key any, resp *Response) error {
+	if resp == nil {
+		return fmt.Errorf("param 'resp' must not be nil")
+	}
+	return s.op.Key(key).Set(&record{resp})
+}
+
+// Abort 放弃处理，移除幂等键，之后相同幂等键的请求视为新的请求，例如请求处理失败需要客户端重试时。
+func (s *Store) Abort(key any) error {
+	_, err := s.op.Key(key).Remove()
+	return err
+}
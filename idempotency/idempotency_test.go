@@ -0,0 +1,73 @@
+package idempotency
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestStore(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			s := NewStore("ns", cachetest.Prefix("idempotency"), p, cache.NewExpiration(time.Minute, 0), time.Second)
+
+			if resp, err := s.Claim("k1"); resp != nil || err != nil {
+				t.Fatalf("Claim() = %v, %v", resp, err)
+			}
+			if _, err := s.Claim("k1"); err != ErrInProgress {
+				t.Errorf("Claim() in progress error = %v, want ErrInProgress", err)
+			}
+
+			want := &Response{http.StatusCreated, http.Header{"Content-Type": {"application/json"}}, []byte(`{"id":1}`)}
+			if err := s.Complete("k1", want); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				got, err := s.Claim("k1")
+				if err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("Claim() completed = %v, %v, want %v", got, err, want)
+				}
+			}
+
+			// 放弃处理后可以重新占用。
+			s.Claim("k2")
+			if err := s.Abort("k2"); err != nil {
+				t.Fatalf("Abort() error = %v", err)
+			}
+			if resp, err := s.Claim("k2"); resp != nil || err != nil {
+				t.Errorf("Claim() after Abort() = %v, %v", resp, err)
+			}
+		})
+	}
+}
+
+func TestStore_WithCodec(t *testing.T) {
+	p := cache.NewMemoryCacheProvider(time.Second)
+	s := NewStore("ns", cachetest.Prefix("idempotency"), p, cache.NewExpiration(time.Minute, 0), time.Second, cache.WithCodec(cache.GobCodec))
+
+	// 占用和保存响应使用相同的编解码器。
+	s.Claim("k")
+	want := &Response{http.StatusOK, http.Header{}, []byte("ok")}
+	if err := s.Complete("k", want); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got, err := s.Claim("k"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Claim() completed = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestStore_GenerationError(t *testing.T) {
+	cli := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	p := cache.NewRedisCacheProvider(cli)
+	s := NewStore("ns", "idempotency", p, nil, time.Second, cache.WithGeneration(time.Minute))
+
+	// 读取代数失败时返回错误，而不是占用空的缓存key。
+	if resp, err := s.Claim("k"); resp != nil || err == nil {
+		t.Errorf("Claim() = %v, %v, want error", resp, err)
+	}
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/thisXYH/cache/internal/httprecord"
+)
+
+// HeaderKey 从请求头 Idempotency-Key 中获取幂等键。
+func HeaderKey(r *http.Request) string {
+	return r.Header.Get("Idempotency-Key")
+}
+
+// Middleware 返回 net/http 中间件，相同幂等键的请求返回相同的响应。
+//  - 首次请求正常处理，响应的状态码、响应头和响应体被保存；
+//  - 重复请求直接返回保存的响应，并且带有响应头 Idempotent-Replayed: true ；
+//  - 相同幂等键的请求正在处理时返回 409 Conflict ；
+//  - 处理结果是 5xx 或者 panic 时不保存响应，移除幂等键，客户端可以使用相同的幂等键重试；
+//  - 存储出错时返回 500 Internal Server Error 。
+//  @keyFunc: 从请求中获取幂等键，例如 HeaderKey ；返回空字符串时不做幂等处理。
+func Middleware(s *Store, keyFunc func(r *http.Request) string) func(http.Handler) http.Handler {
+	if s == nil || keyFunc == nil {
+		panic(fmt.Errorf("param 's' and 'keyFunc' must not be nil"))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resp, err := s.Claim(key)
+			if err == ErrInProgress {
+				http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+				return
+			}
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			if resp != nil {
+				replay(w, resp)
+				return
+			}
+
+			rec := httprecord.New(w)
+			completed := false
+			defer func() {
+				if !completed { // panic 时放弃处理。
+					s.Abort(key)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			completed = true
+			status, header, body := rec.Result()
+			resp = &Response{status, header, body}
+			if resp.Status >= http.StatusInternalServerError {
+				s.Abort(key)
+				return
+			}
+
+			// 响应已经发送，保存失败时幂等键在处理中状态过期后可以重新使用。
+			s.Complete(key, resp)
+		})
+	}
+}
+
+// replay 返回保存的响应。
+func replay(w http.ResponseWriter, resp *Response) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+	h.Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
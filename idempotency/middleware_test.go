@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	s := NewStore("ns", cachetest.Prefix("idempotency"), cache.NewMemoryCacheProvider(time.Second), cache.NewExpiration(time.Minute, 0), time.Second)
+
+	var calls int32
+	block := make(chan struct{})
+	handler := Middleware(s, HeaderKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/slow":
+			<-block
+		case "/fail":
+			if n == 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		case "/panic":
+			panic("boom")
+		}
+		w.Header().Set("X-Order", "1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	serve := func(path, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}"))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// 重复请求返回相同的响应，不再调用处理器。
+	first := serve("/pay", "k1")
+	replayed := serve("/pay", "k1")
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("first: code = %v, headers = %v", first.Code, first.Header())
+	}
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != "created" ||
+		replayed.Header().Get("X-Order") != "1" || replayed.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replayed: code = %v, body = %v, headers = %v", replayed.Code, replayed.Body, replayed.Header())
+	}
+	if calls != 1 {
+		t.Errorf("handler calls = %d, want 1", calls)
+	}
+
+	// 处理中的重复请求返回 409 。
+	done := make(chan struct{})
+	go func() {
+		serve("/slow", "k2")
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if w := serve("/slow", "k2"); w.Code != http.StatusConflict {
+		t.Errorf("concurrent: code = %v, want 409", w.Code)
+	}
+	close(block)
+	<-done
+
+	// 5xx 和 panic 不保存响应，可以重试。
+	atomic.StoreInt32(&calls, 0)
+	if w := serve("/fail", "k3"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("fail: code = %v", w.Code)
+	}
+	if w := serve("/fail", "k3"); w.Code != http.StatusCreated {
+		t.Errorf("retry after fail: code = %v", w.Code)
+	}
+	func() {
+		defer func() { recover() }()
+		serve("/panic", "k4")
+	}()
+	if resp, err := s.Claim("k4"); resp != nil || err != nil {
+		t.Errorf("Claim() after panic = %v, %v", resp, err)
+	}
+
+	// 没有幂等键时不做处理。
+	atomic.StoreInt32(&calls, 0)
+	serve("/pay", "")
+	serve("/pay", "")
+	if calls != 2 {
+		t.Errorf("handler calls without key = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,26 @@
+// Package cachetest 提供各个子包的测试共用的缓存提供器，使所有的包使用相同的测试矩阵。
+package cachetest
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// Providers 测试使用的缓存提供器，redis 需要在本地运行。
+func Providers() map[string]cache.CacheProvider {
+	cli := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379", MaxRetries: -1})
+	return map[string]cache.CacheProvider{
+		"memory":       cache.NewMemoryCacheProvider(time.Second),
+		"memory_codec": cache.NewMemoryCacheProvider(time.Second).WithCodec(cache.GobCodec),
+		"redis":        cache.NewRedisCacheProvider(cli),
+	}
+}
+
+// Prefix 获取随机的缓存key前缀，避免多次运行的测试相互影响。
+func Prefix(name string) string {
+	return name + "_" + fmt.Sprint(rand.Int31())
+}
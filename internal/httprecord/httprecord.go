@@ -0,0 +1,64 @@
+// Package httprecord 提供 net/http 中间件共用的响应记录器。
+package httprecord
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Recorder 在写入响应的同时记录响应。
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+var _ http.Flusher = (*Recorder)(nil)
+
+// New 创建一个记录写入 w 的响应的 Recorder 。
+func New(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w}
+}
+
+// WriteHeader 写入并记录状态码，记录此时的响应头。
+func (rec *Recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write 写入并记录响应体。
+func (rec *Recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush 底层的 http.ResponseWriter 实现了 http.Flusher 时转发，反之只写入状态码。
+func (rec *Recorder) Flush() {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 获取底层的 http.ResponseWriter ，供 http.ResponseController 使用。
+func (rec *Recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Result 获取记录的响应，没有写入任何内容时视为 200 OK 。
+func (rec *Recorder) Result() (status int, header http.Header, body []byte) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	return rec.status, rec.header, rec.body.Bytes()
+}
@@ -0,0 +1,38 @@
+package httprecord
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := New(w)
+	rec.Header().Set("X-A", "1")
+	rec.Write([]byte("hello "))
+	rec.Flush()
+	rec.Header().Set("X-B", "2") // 写入状态码之后的修改不会发送，也不记录。
+	rec.Write([]byte("world"))
+
+	status, header, body := rec.Result()
+	if status != http.StatusOK || header.Get("X-A") != "1" || header.Get("X-B") != "" || string(body) != "hello world" {
+		t.Errorf("Result() = %v, %v, %q", status, header, body)
+	}
+	if !w.Flushed || w.Body.String() != "hello world" {
+		t.Errorf("Flush() should be forwarded, flushed = %v, body = %q", w.Flushed, w.Body.String())
+	}
+}
+
+func TestRecorder_Empty(t *testing.T) {
+	rec := New(httptest.NewRecorder())
+	if status, _, body := rec.Result(); status != http.StatusOK || len(body) != 0 {
+		t.Errorf("Result() = %v, %q", status, body)
+	}
+
+	rec = New(httptest.NewRecorder())
+	rec.WriteHeader(http.StatusNoContent)
+	if status, _, _ := rec.Result(); status != http.StatusNoContent {
+		t.Errorf("Result() status = %v", status)
+	}
+}
@@ -1,16 +1,38 @@
 package cache
 
 type KeyOperation struct {
-	p   CacheProvider
-	exp *Expiration
+	p    CacheProvider
+	exp  *Expiration
+	ns   string // CacheNamespace ，标签属于该 CacheNamespace 。
+	tags []string
+	err  error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
 
 	// 缓存key。
 	Key string
 }
 
+// Tag 返回一个关联了 tags 的 KeyOperation ，通过它 Create 、 Set 、 IncreaseOrCreate 写入的缓存会关联到这些标签，
+// 之后调用 TagCacheProvider.InvalidateTag 即可移除，标签属于当前 Operation 的 CacheNamespace ，缓存提供器必须实现 TagCacheProvider 。
+func (keyOp *KeyOperation) Tag(tags ...string) *KeyOperation {
+	checkTags(keyOp.p, tags)
+	res := *keyOp
+	res.tags = appendTags(keyOp.tags, tags)
+	return &res
+}
+
+// Err 返回构建缓存key时的错误，例如使用 WithGeneration 时读取代数失败，此时 Key 为空字符串。
+// 直接使用 Key 访问缓存提供器之前需要检查该错误。
+func (keyOp *KeyOperation) Err() error {
+	return keyOp.err
+}
+
 // Get 获取指定缓存值。
 // 如果key存在，value被更新成对应值， 反之value值不做改变。
 func (keyOp *KeyOperation) Get(value any) error {
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
 	return keyOp.p.Get(keyOp.Key, value)
 }
 
@@ -25,6 +47,10 @@ func (keyOp *KeyOperation) MustGet(value any) {
 // TryGet 尝试获取指定缓存。
 // 若key存在，value被更新成对应值，返回true，反之value值不做改变，返回false。
 func (keyOp *KeyOperation) TryGet(value any) (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
 	return keyOp.p.TryGet(keyOp.Key, value)
 }
 
@@ -40,7 +66,16 @@ func (keyOp *KeyOperation) MustTryGet(value any) bool {
 // Create 仅当缓存键不存在时，创建缓存。
 //  return: true表示创建了缓存；false说明缓存已经存在了。
 func (keyOp *KeyOperation) Create(value any) (bool, error) {
-	return keyOp.p.Create(keyOp.Key, value, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	created, err := keyOp.p.Create(keyOp.Key, value, t)
+	if err != nil || !created {
+		return created, err
+	}
+	return true, addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustCreate 是 Create 的 panic 版。
@@ -54,7 +89,15 @@ func (keyOp *KeyOperation) MustCreate(value any) bool {
 
 // Set 设置或者更新缓存。
 func (keyOp *KeyOperation) Set(value any) error {
-	return keyOp.p.Set(keyOp.Key, value, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	if err := keyOp.p.Set(keyOp.Key, value, t); err != nil {
+		return err
+	}
+	return addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustSet 是 Set 的 panic 版。
@@ -68,6 +111,10 @@ func (keyOp *KeyOperation) MustSet(value any) {
 // Remove 移除指定缓存,
 //  return: true成功移除，false缓存不存在。
 func (keyOp *KeyOperation) Remove() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
 	return keyOp.p.Remove(keyOp.Key)
 }
 
@@ -83,6 +130,10 @@ func (keyOp *KeyOperation) MustRemove() bool {
 // Increase 为已存在的指定缓存的值（必须是整数）增加1。
 //  return: 符合条件返回增加后的值，反之返回默认值，以及对应的 error。
 func (keyOp *KeyOperation) Increase() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
 	return keyOp.p.Increase(keyOp.Key)
 }
 
@@ -99,7 +150,16 @@ func (keyOp *KeyOperation) MustIncrease() int64 {
 //  @increment: 增量，如果 key 不存在，则当成新缓存的 value。
 // return: 返回增加后的值。
 func (keyOp *KeyOperation) IncreaseOrCreate(increment int64) (int64, error) {
-	return keyOp.p.IncreaseOrCreate(keyOp.Key, increment, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	result, err := keyOp.p.IncreaseOrCreate(keyOp.Key, increment, t)
+	if err != nil {
+		return result, err
+	}
+	return result, addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustIncreaseOrCreate 是 IncreaseOrCreate 的 panic 版。
@@ -113,16 +173,36 @@ func (keyOp *KeyOperation) MustIncreaseOrCreate(increment int64) int64 {
 
 // KeyOperationT 是泛型版本的 KeyOperation 。
 type KeyOperationT[T any] struct {
-	p   CacheProvider
-	exp *Expiration
+	p    CacheProvider
+	exp  *Expiration
+	ns   string // CacheNamespace ，标签属于该 CacheNamespace 。
+	tags []string
+	err  error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
 
 	// 缓存key。
 	Key string
 }
 
+// Tag 返回一个关联了 tags 的 KeyOperationT ，用法与 KeyOperation.Tag 相同。
+func (keyOp *KeyOperationT[T]) Tag(tags ...string) *KeyOperationT[T] {
+	checkTags(keyOp.p, tags)
+	res := *keyOp
+	res.tags = appendTags(keyOp.tags, tags)
+	return &res
+}
+
+// Err 返回构建缓存key时的错误，用法与 KeyOperation.Err 相同。
+func (keyOp *KeyOperationT[T]) Err() error {
+	return keyOp.err
+}
+
 // Get 获取指定缓存值。
 func (keyOp *KeyOperationT[T]) Get() (T, error) {
 	var v T
+	if keyOp.err != nil {
+		return v, keyOp.err
+	}
+
 	err := keyOp.p.Get(keyOp.Key, &v)
 	return v, err
 }
@@ -140,6 +220,10 @@ func (keyOp *KeyOperationT[T]) MustGet() T {
 // 若key存在，value被更新成对应值，返回true，反之value值不做改变，返回false。
 func (keyOp *KeyOperationT[T]) TryGet() (T, bool, error) {
 	var v T
+	if keyOp.err != nil {
+		return v, false, keyOp.err
+	}
+
 	result, err := keyOp.p.TryGet(keyOp.Key, &v)
 	return v, result, err
 
@@ -157,7 +241,16 @@ func (keyOp *KeyOperationT[T]) MustTryGet() (T, bool) {
 // Create 仅当缓存键不存在时，创建缓存。
 //  return: true表示创建了缓存；false说明缓存已经存在了。
 func (keyOp *KeyOperationT[T]) Create(value T) (bool, error) {
-	return keyOp.p.Create(keyOp.Key, value, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	created, err := keyOp.p.Create(keyOp.Key, value, t)
+	if err != nil || !created {
+		return created, err
+	}
+	return true, addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustCreate 是 Create 的 panic 版。
@@ -171,7 +264,15 @@ func (keyOp *KeyOperationT[T]) MustCreate(value T) bool {
 
 // Set 设置或者更新缓存。
 func (keyOp *KeyOperationT[T]) Set(value T) error {
-	return keyOp.p.Set(keyOp.Key, value, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	if err := keyOp.p.Set(keyOp.Key, value, t); err != nil {
+		return err
+	}
+	return addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustSet 是 Set 的 panic 版。
@@ -185,6 +286,10 @@ func (keyOp *KeyOperationT[T]) MustSet(value T) {
 // Remove 移除指定缓存,
 //  return: true成功移除，false缓存不存在。
 func (keyOp *KeyOperationT[T]) Remove() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
 	return keyOp.p.Remove(keyOp.Key)
 }
 
@@ -200,6 +305,10 @@ func (keyOp *KeyOperationT[T]) MustRemove() bool {
 // Increase 为已存在的指定缓存的值（必须是整数）增加1。
 //  return: 符合条件返回增加后的值，反之返回默认值，以及对应的 error。
 func (keyOp *KeyOperationT[T]) Increase() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
 	return keyOp.p.Increase(keyOp.Key)
 }
 
@@ -216,7 +325,16 @@ func (keyOp *KeyOperationT[T]) MustIncrease() int64 {
 //  @increment: 增量，如果 key 不存在，则当成新缓存的 value。
 // return: 返回增加后的值。
 func (keyOp *KeyOperationT[T]) IncreaseOrCreate(increment int64) (int64, error) {
-	return keyOp.p.IncreaseOrCreate(keyOp.Key, increment, keyOp.exp.NextExpireTime())
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	t := keyOp.exp.NextExpireTime()
+	result, err := keyOp.p.IncreaseOrCreate(keyOp.Key, increment, t)
+	if err != nil {
+		return result, err
+	}
+	return result, addTags(keyOp.p, keyOp.ns, keyOp.Key, keyOp.tags, t)
 }
 
 // MustIncreaseOrCreate 是 IncreaseOrCreate 的 panic 版。
@@ -23,6 +23,11 @@ type Level2CacheProvider struct {
 	expireTime *Expiration
 }
 
+var (
+	_ CacheProvider      = (*Level2CacheProvider)(nil)
+	_ CodecCacheProvider = (*Level2CacheProvider)(nil)
+)
+
 // NewLevel2CacheProvider 新建一个二级缓存提供器。
 //  @l1: 一级缓存。
 //  @l2: 二级级缓存。
@@ -35,6 +40,17 @@ func NewLevel2CacheProvider(l1, l2 CacheProvider, expireTime *Expiration) *Level
 	return &Level2CacheProvider{l1, l2, expireTime}
 }
 
+// implement CodecCacheProvider.WithCodec, 两个层级的缓存提供器都必须实现 CodecCacheProvider 。
+func (p *Level2CacheProvider) WithCodec(codec Codec) CacheProvider {
+	l1, ok1 := p.level1.(CodecCacheProvider)
+	l2, ok2 := p.level2.(CodecCacheProvider)
+	if !ok1 || !ok2 {
+		panic(fmt.Errorf("both levels of cache provider must implement CodecCacheProvider"))
+	}
+
+	return &Level2CacheProvider{l1.WithCodec(codec), l2.WithCodec(codec), p.expireTime}
+}
+
 // implement CacheProvider.Get .
 func (p *Level2CacheProvider) Get(key string, value any) error {
 	_, err := p.TryGet(key, value)
@@ -0,0 +1,357 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ListCacheProvider 是支持列表结构的 CacheProvider ，可用作最近动态列表或者简单的队列。
+type ListCacheProvider interface {
+	CacheProvider
+
+	// ListPush 将元素依次插入列表的头部或者尾部，列表不存在时创建。
+	//  @key: cache key.
+	//  @left: true 插入头部，false 插入尾部。
+	//  @values: 元素。
+	//  @t: 过期时长， 0表不过期，只有列表是新创建的才设置。
+	// return: 插入后列表的长度。
+	ListPush(key string, left bool, values []any, t time.Duration) (int64, error)
+
+	// ListPop 弹出列表头部或者尾部的元素，所有元素都被弹出后，列表也被移除。
+	//  @key: cache key.
+	//  @left: true 弹出头部，false 弹出尾部。
+	//  @value: receive value.
+	// return: 若列表不为空，value 被更新成弹出的元素，返回 true；反之 value 值不做改变，返回 false。
+	ListPop(key string, left bool, value any) (bool, error)
+
+	// ListBlockingPop 是 ListPop 的阻塞版，列表为空时一直等待，直到有元素可以弹出或者 ctx 结束。
+	//  @ctx: 用于控制等待时长，ctx 结束时返回 ctx.Err() 。
+	// return: 同 ListPop 。
+	ListBlockingPop(ctx context.Context, key string, left bool, value any) (bool, error)
+
+	// ListRange 获取列表指定区间的元素，下标的含义与 redis 的 LRANGE 一致，负数表示从尾部开始计算。
+	//  @key: cache key.
+	//  @start: 开始下标（包含）。
+	//  @stop: 结束下标（包含）。
+	//  @values: receive value, 必须是 []T 的指针，列表不存在时被更新成空切片。
+	ListRange(key string, start, stop int64, values any) error
+
+	// ListTrim 只保留列表指定区间的元素，下标的含义与 ListRange 一致。
+	ListTrim(key string, start, stop int64) error
+
+	// ListLen 获取列表的长度，列表不存在时返回 0 。
+	ListLen(key string) (int64, error)
+}
+
+// ListOperation 列表缓存操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个列表。
+type ListOperation[T any] struct {
+	op Operation
+	p  ListCacheProvider
+}
+
+// NewListOperation 创建一个列表缓存操作对象。
+// expireTime: 整个列表的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 ListCacheProvider 。
+func NewListOperation[T any](
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *ListOperation[T] {
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(ListCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement ListCacheProvider", op.cacheProvider))
+	}
+
+	return &ListOperation[T]{*op, p}
+}
+
+// Key 获取指定列表的缓存操作对象。
+func (c *ListOperation[T]) Key(keys ...any) *ListKeyOperation[T] {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	key, err := c.op.buildCacheKey(keys...)
+	return &ListKeyOperation[T]{
+		p:   c.p,
+		exp: c.op.expireTime,
+		Key: key,
+		err: err,
+	}
+}
+
+// ListKeyOperation 列表缓存 key 的操作对象。
+type ListKeyOperation[T any] struct {
+	p   ListCacheProvider
+	exp *Expiration
+	err error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// LeftPush 将元素依次插入列表的头部，列表是新创建的时候，设置过期时间。
+//  return: 插入后列表的长度。
+func (keyOp *ListKeyOperation[T]) LeftPush(values ...T) (int64, error) {
+	return keyOp.push(true, values)
+}
+
+// MustLeftPush 是 LeftPush 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustLeftPush(values ...T) int64 {
+	result, err := keyOp.LeftPush(values...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RightPush 将元素依次插入列表的尾部，列表是新创建的时候，设置过期时间。
+//  return: 插入后列表的长度。
+func (keyOp *ListKeyOperation[T]) RightPush(values ...T) (int64, error) {
+	return keyOp.push(false, values)
+}
+
+// MustRightPush 是 RightPush 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustRightPush(values ...T) int64 {
+	result, err := keyOp.RightPush(values...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// LeftPop 弹出列表头部的元素。
+// 若列表不为空，返回弹出的元素和 true，反之返回 false。
+func (keyOp *ListKeyOperation[T]) LeftPop() (T, bool, error) {
+	var v T
+	if keyOp.err != nil {
+		return v, false, keyOp.err
+	}
+
+	result, err := keyOp.p.ListPop(keyOp.Key, true, &v)
+	return v, result, err
+}
+
+// MustLeftPop 是 LeftPop 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustLeftPop() (T, bool) {
+	v, result, err := keyOp.LeftPop()
+	if err != nil {
+		panic(err)
+	}
+	return v, result
+}
+
+// RightPop 弹出列表尾部的元素。
+// 若列表不为空，返回弹出的元素和 true，反之返回 false。
+func (keyOp *ListKeyOperation[T]) RightPop() (T, bool, error) {
+	var v T
+	if keyOp.err != nil {
+		return v, false, keyOp.err
+	}
+
+	result, err := keyOp.p.ListPop(keyOp.Key, false, &v)
+	return v, result, err
+}
+
+// MustRightPop 是 RightPop 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustRightPop() (T, bool) {
+	v, result, err := keyOp.RightPop()
+	if err != nil {
+		panic(err)
+	}
+	return v, result
+}
+
+// BlockingLeftPop 是 LeftPop 的阻塞版，列表为空时一直等待，直到有元素可以弹出或者 ctx 结束。
+// ctx 结束时返回 ctx.Err() ，可以通过 context.WithTimeout 指定等待时长。
+func (keyOp *ListKeyOperation[T]) BlockingLeftPop(ctx context.Context) (T, error) {
+	var v T
+	if keyOp.err != nil {
+		return v, keyOp.err
+	}
+
+	_, err := keyOp.p.ListBlockingPop(ctx, keyOp.Key, true, &v)
+	return v, err
+}
+
+// BlockingRightPop 是 RightPop 的阻塞版，列表为空时一直等待，直到有元素可以弹出或者 ctx 结束。
+// ctx 结束时返回 ctx.Err() ，可以通过 context.WithTimeout 指定等待时长。
+func (keyOp *ListKeyOperation[T]) BlockingRightPop(ctx context.Context) (T, error) {
+	var v T
+	if keyOp.err != nil {
+		return v, keyOp.err
+	}
+
+	_, err := keyOp.p.ListBlockingPop(ctx, keyOp.Key, false, &v)
+	return v, err
+}
+
+// Range 获取列表指定区间的元素，负数下标表示从尾部开始计算，例如 Range(0, -1) 获取所有元素。
+// 列表不存在时返回空切片。
+func (keyOp *ListKeyOperation[T]) Range(start, stop int64) ([]T, error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	var v []T
+	err := keyOp.p.ListRange(keyOp.Key, start, stop, &v)
+	return v, err
+}
+
+// MustRange 是 Range 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustRange(start, stop int64) []T {
+	v, err := keyOp.Range(start, stop)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Trim 只保留列表指定区间的元素，负数下标表示从尾部开始计算。
+func (keyOp *ListKeyOperation[T]) Trim(start, stop int64) error {
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	return keyOp.p.ListTrim(keyOp.Key, start, stop)
+}
+
+// MustTrim 是 Trim 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustTrim(start, stop int64) {
+	err := keyOp.Trim(start, stop)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TrimToLength 只保留列表头部的 length 个元素，常与 LeftPush 配合，只保留最新的若干条记录。
+//  @length: 保留的元素个数，0 表示移除整个列表。
+func (keyOp *ListKeyOperation[T]) TrimToLength(length int64) error {
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	if length < 0 {
+		return fmt.Errorf("'length' must not be less than 0")
+	}
+
+	// stop 为 -1 表示最后一个元素，所以需要单独处理。
+	if length == 0 {
+		_, err := keyOp.p.Remove(keyOp.Key)
+		return err
+	}
+	return keyOp.p.ListTrim(keyOp.Key, 0, length-1)
+}
+
+// MustTrimToLength 是 TrimToLength 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustTrimToLength(length int64) {
+	err := keyOp.TrimToLength(length)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Len 获取列表的长度，列表不存在时返回 0 。
+func (keyOp *ListKeyOperation[T]) Len() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.ListLen(keyOp.Key)
+}
+
+// MustLen 是 Len 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustLen() int64 {
+	result, err := keyOp.Len()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Remove 移除整个列表。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *ListKeyOperation[T]) Remove() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *ListKeyOperation[T]) MustRemove() bool {
+	result, err := keyOp.Remove()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func (keyOp *ListKeyOperation[T]) push(left bool, values []T) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	vs := make([]any, len(values))
+	for i, v := range values {
+		vs[i] = v
+	}
+	return keyOp.p.ListPush(keyOp.Key, left, vs, keyOp.exp.NextExpireTime())
+}
+
+// setSliceValue 将 items 的每个值解码后写入 values 。
+//  @values: 必须是 []T 的指针。
+//  @decode: 将 item 解码到 elem（*T）。
+func setSliceValue[E any](values any, items []E, decode func(item E, elem any) error) error {
+	ptr := reflect.ValueOf(values)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("values must be a non-nil pointer to []T, got %T", values)
+	}
+
+	sliceT := ptr.Elem().Type()
+	s := reflect.MakeSlice(sliceT, 0, len(items))
+	for _, item := range items {
+		elem := reflect.New(sliceT.Elem())
+		if err := decode(item, elem.Interface()); err != nil {
+			if errors.Is(err, ErrCacheMiss) { // 编解码器要求视为不存在。
+				continue
+			}
+			return err
+		}
+		s = reflect.Append(s, elem.Elem())
+	}
+
+	ptr.Elem().Set(s)
+	return nil
+}
+
+// listRangeIndex 按照 redis 的规则将 start、stop 转换成 [0, length) 内的下标。
+// return: 区间为空时返回 ok=false 。
+func listRangeIndex(start, stop, length int64) (int64, int64, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := "list_" + fmt.Sprint(rand.Int31())
+			op := NewListOperation[Person]("ns", prefix, 1, p, NewExpiration(time.Minute, 0))
+			keyOp := op.Key(1)
+			defer keyOp.Remove()
+
+			if _, ok := keyOp.MustLeftPop(); ok {
+				t.Fatalf("LeftPop() on missing list should return false")
+			}
+			if got := keyOp.MustRange(0, -1); len(got) != 0 {
+				t.Fatalf("Range() on missing list = %v, want empty", got)
+			}
+
+			a, b, c, d := Person{"a", 1}, Person{"b", 2}, Person{"c", 3}, Person{"d", 4}
+			keyOp.MustRightPush(b, c)
+			if n := keyOp.MustLeftPush(a); n != 3 {
+				t.Errorf("LeftPush() = %v, want 3", n)
+			}
+			keyOp.MustRightPush(d)
+
+			tests := []struct {
+				start, stop int64
+				want        []Person
+			}{
+				{0, -1, []Person{a, b, c, d}},
+				{1, 2, []Person{b, c}},
+				{-2, -1, []Person{c, d}},
+				{2, 100, []Person{c, d}},
+				{3, 1, []Person{}},
+				{-100, 0, []Person{a}},
+			}
+			for _, tt := range tests {
+				if got := keyOp.MustRange(tt.start, tt.stop); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Range(%v, %v) = %v, want %v", tt.start, tt.stop, got, tt.want)
+				}
+			}
+
+			if v, ok := keyOp.MustLeftPop(); !ok || v != a {
+				t.Errorf("LeftPop() = %v, %v", v, ok)
+			}
+			if v, ok := keyOp.MustRightPop(); !ok || v != d {
+				t.Errorf("RightPop() = %v, %v", v, ok)
+			}
+			if n := keyOp.MustLen(); n != 2 {
+				t.Errorf("Len() = %v, want 2", n)
+			}
+
+			keyOp.MustRightPop()
+			keyOp.MustRightPop()
+
+			// 所有元素都被弹出后，列表也被移除。
+			if keyOp.MustRemove() {
+				t.Errorf("Remove() on empty list should return false")
+			}
+		})
+	}
+}
+
+func TestListOperation_LeftPushOrder(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewListOperation[int]("ns", "list_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer keyOp.Remove()
+
+			keyOp.MustLeftPush(1, 2, 3)
+			if got := keyOp.MustRange(0, -1); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+				t.Errorf("LeftPush(1, 2, 3) = %v, want [3 2 1]", got)
+			}
+		})
+	}
+}
+
+func TestListOperation_Trim(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewListOperation[int]("ns", "list_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer keyOp.Remove()
+
+			keyOp.MustRightPush(1, 2, 3, 4, 5)
+			keyOp.MustTrim(1, -1)
+			if got := keyOp.MustRange(0, -1); !reflect.DeepEqual(got, []int{2, 3, 4, 5}) {
+				t.Errorf("Trim(1, -1) = %v", got)
+			}
+
+			keyOp.MustTrimToLength(2)
+			if got := keyOp.MustRange(0, -1); !reflect.DeepEqual(got, []int{2, 3}) {
+				t.Errorf("TrimToLength(2) = %v", got)
+			}
+
+			keyOp.MustTrimToLength(0)
+			if n := keyOp.MustLen(); n != 0 {
+				t.Errorf("TrimToLength(0) len = %v, want 0", n)
+			}
+
+			if err := keyOp.TrimToLength(-1); err == nil {
+				t.Errorf("TrimToLength(-1) should return error")
+			}
+		})
+	}
+}
+
+func TestListOperation_BlockingPop(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewListOperation[string]("ns", "list_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer keyOp.Remove()
+
+			// 已有元素，立即返回。
+			keyOp.MustRightPush("a")
+			if v, err := keyOp.BlockingLeftPop(context.Background()); err != nil || v != "a" {
+				t.Errorf("BlockingLeftPop() = %v, %v", v, err)
+			}
+
+			// 等待其他协程插入元素。
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				keyOp.MustRightPush("b")
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if v, err := keyOp.BlockingRightPop(ctx); err != nil || v != "b" {
+				t.Errorf("BlockingRightPop() = %v, %v", v, err)
+			}
+
+			// 超时，不足 1 秒的超时也能及时返回。
+			ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			if _, err := keyOp.BlockingLeftPop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("BlockingLeftPop() error = %v, want DeadlineExceeded", err)
+			}
+			if d := time.Since(start); d > 500*time.Millisecond {
+				t.Errorf("BlockingLeftPop() returned after %v, want about 100ms", d)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheProvider_ListBlockingPop_Cancel(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.ListBlockingPop(ctx, "list_cancel", true, new(int))
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	if n := len(p.listWaiters); n != 0 {
+		t.Errorf("len(listWaiters) = %d after ctx canceled, want 0", n)
+	}
+}
+
+func TestListOperation_WrongType(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewListOperation[int]("ns", "list_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer p.Remove(keyOp.Key)
+			p.Set(keyOp.Key, 1, time.Minute)
+
+			if _, err := keyOp.RightPush(1); err == nil {
+				t.Errorf("RightPush() on non-list key should return error")
+			}
+			if _, _, err := keyOp.LeftPop(); err == nil {
+				t.Errorf("LeftPop() on non-list key should return error")
+			}
+		})
+	}
+}
+
+func TestListOperation_TTL(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	keyOp := NewListOperation[int]("ns", "list_ttl", 0, p, NewExpiration(time.Minute, 0)).Key()
+
+	keyOp.MustRightPush(1)
+	_, exp1, _ := p.cache.GetWithExpiration(keyOp.Key)
+	time.Sleep(10 * time.Millisecond)
+	keyOp.MustLeftPush(2)
+	_, exp2, _ := p.cache.GetWithExpiration(keyOp.Key)
+
+	if exp1.IsZero() || !exp1.Equal(exp2) {
+		t.Errorf("expiration changed: %v -> %v", exp1, exp2)
+	}
+}
@@ -0,0 +1,148 @@
+// Package lock 提供基于 cache.CacheProvider 的分布式锁和分布式信号量。
+//
+// 持有者使用随机的令牌标识，释放和续期时比较令牌，避免租约过期后误删其他持有者的锁或许可。
+// Mutex 要求缓存提供器实现 cache.CompareCacheProvider ，例如 RedisCacheProvider 和 MemoryCacheProvider ；
+// Semaphore 要求缓存提供器是 RedisCacheProvider 或者 MemoryCacheProvider 。
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+var (
+	// ErrNotHeld 释放锁时，锁没有被当前对象持有，或者租约已经过期被其他持有者获取。
+	ErrNotHeld = errors.New("lock is not held")
+
+	// ErrHeld 当前对象已经持有锁，不能重复获取。
+	ErrHeld = errors.New("lock is already held")
+)
+
+const (
+	defaultBackoffMin = 10 * time.Millisecond  // 默认的最小重试间隔。
+	defaultBackoffMax = 500 * time.Millisecond // 默认的最大重试间隔。
+)
+
+// compareProvider 获取使用确定结果的编解码器的缓存提供器，令牌按编码后的结果比较。
+func compareProvider(p cache.CacheProvider) cache.CompareCacheProvider {
+	if cp, ok := p.(cache.CodecCacheProvider); ok {
+		p = cp.WithCodec(cache.JSONCodec)
+	}
+
+	cp, ok := p.(cache.CompareCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement CompareCacheProvider", p))
+	}
+	return cp
+}
+
+// newToken 生成随机的持有者令牌。
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// hold 一次持有。
+type hold struct {
+	token string
+	stop  chan struct{} // 关闭时停止续期。
+	done  chan struct{} // 持有结束时关闭。
+	once  sync.Once
+}
+
+func newHold(token string) *hold {
+	return &hold{token: token, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (h *hold) end() {
+	h.once.Do(func() { close(h.done) })
+}
+
+// doneChan 获取持有结束时关闭的通道，h 为 nil 时返回一个已关闭的通道。
+func (h *hold) doneChan() <-chan struct{} {
+	if h == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return h.done
+}
+
+// keepAlive 持有期间每 ttl/3 调用一次 renew 续期，直到 h.stop 关闭。
+// renew 返回 false 表示已经不再持有，此时调用 lost 后结束持有；续期出错时（例如网络异常）在下一个周期重试，
+// 但距离最后一次成功续期已经超过 ttl-ttl/3 时，下一次续期之前租约可能已经过期，被其他持有者获取，同样视为丢失。
+func keepAlive(h *hold, ttl time.Duration, renew func() (bool, error), lost func()) {
+	interval := ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now() // 最后一次成功续期（或者获取）的时间，租约至少持续到 renewed+ttl 。
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		ok, err := renew()
+		if err == nil && ok {
+			renewed = start
+			continue
+		}
+		if err != nil && time.Since(renewed) < ttl-interval {
+			continue
+		}
+
+		lost()
+		h.end()
+		return
+	}
+}
+
+// backoff 指数退避的重试间隔，加入随机抖动避免多个等待者同时重试。
+type backoff struct {
+	min, max time.Duration
+	next     time.Duration
+}
+
+// wait 等待下一次重试。
+//  return: ctx 结束时返回 ctx.Err() 。
+func (b *backoff) wait(ctx context.Context) error {
+	if b.next == 0 {
+		b.next = b.min
+	}
+
+	d := b.next/2 + time.Duration(mrand.Int63n(int64(b.next/2)+1))
+	if b.next *= 2; b.next > b.max {
+		b.next = b.max
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// checkBackoff 校验重试间隔。
+func checkBackoff(min, max time.Duration) {
+	if min <= 0 || max < min {
+		panic(fmt.Errorf("backoff must satisfy 0 < min <= max, got min=%v max=%v", min, max))
+	}
+}
@@ -0,0 +1,162 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+// Locker 分布式锁的操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一把锁，缓存值是持有者的令牌。
+type Locker struct {
+	op  *cache.Operation
+	p   cache.CompareCacheProvider
+	ttl time.Duration
+
+	backoffMin, backoffMax time.Duration
+}
+
+// NewLocker 创建一个分布式锁的操作对象。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 cache.CompareCacheProvider ，令牌固定使用 JSONCodec 编码。
+// ttl: 锁的租约时长，持有期间每 ttl/3 自动续期，持有者异常退出时锁在 ttl 后自动释放。
+func NewLocker(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider cache.CacheProvider,
+	ttl time.Duration,
+	opts ...cache.OperationOption,
+) *Locker {
+	if ttl < 3*time.Millisecond {
+		panic(fmt.Errorf("param 'ttl' must not be less than 3ms"))
+	}
+
+	op := cache.NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, nil, opts...)
+	return &Locker{op, compareProvider(cacheProvider), ttl, defaultBackoffMin, defaultBackoffMax}
+}
+
+// WithBackoff 返回一个使用指定重试间隔的 Locker ，阻塞获取锁时重试间隔从 min 开始指数增长到 max 。
+func (l *Locker) WithBackoff(min, max time.Duration) *Locker {
+	checkBackoff(min, max)
+	return &Locker{l.op, l.p, l.ttl, min, max}
+}
+
+// Key 获取指定锁的操作对象，每次调用返回一个新的持有者。
+func (l *Locker) Key(keys ...any) *Mutex {
+	keyOp := l.op.Key(keys...)
+	return &Mutex{l: l, err: keyOp.Err(), Key: keyOp.Key}
+}
+
+// Mutex 分布式锁的一个持有者，可以被多个 goroutine 使用，但同一时间最多持有一次。
+type Mutex struct {
+	l   *Locker
+	mu  sync.Mutex
+	h   *hold // 当前的持有，没有持有时为 nil 。
+	err error // 构建缓存key时的错误，不为 nil 时获取锁都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// TryLock 尝试获取锁，不等待。
+//  return: true 获取成功；false 锁被其他持有者持有。当前对象已经持有锁时返回 ErrHeld 。
+func (m *Mutex) TryLock() (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.h != nil {
+		return false, ErrHeld
+	}
+
+	token := newToken()
+	ok, err := m.l.p.Create(m.Key, token, m.l.ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	h := newHold(token)
+	m.h = h
+	go m.renew(h)
+	return true, nil
+}
+
+// Lock 阻塞获取锁，获取失败时按指数退避重试，直到获取成功或者 ctx 结束。
+//  return: ctx 结束时返回 ctx.Err() 。
+func (m *Mutex) Lock(ctx context.Context) error {
+	b := &backoff{min: m.l.backoffMin, max: m.l.backoffMax}
+	for {
+		ok, err := m.TryLock()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		if err = b.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Unlock 释放锁，只移除自己持有的锁。
+//  return: 没有持有锁，或者租约已经过期被其他持有者获取时返回 ErrNotHeld 。
+func (m *Mutex) Unlock() error {
+	m.mu.Lock()
+	h := m.h
+	m.h = nil
+	m.mu.Unlock()
+
+	if h == nil {
+		return ErrNotHeld
+	}
+
+	close(h.stop)
+	defer h.end()
+
+	ok, err := m.l.p.CompareAndRemove(m.Key, h.token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Held 判断当前对象是否持有锁。
+// 租约在续期之前被其他持有者获取时，最多在 ttl/3 之后才能发现。
+func (m *Mutex) Held() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.h != nil
+}
+
+// Done 返回一个在本次持有结束时关闭的通道：锁被释放，或者续期时发现锁已经丢失。
+// 没有持有锁时返回一个已关闭的通道。
+func (m *Mutex) Done() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.h.doneChan()
+}
+
+// renew 持有期间每 ttl/3 续期一次，续期时发现锁已经不属于自己，或者续期一直出错直到租约可能过期，则结束持有。
+func (m *Mutex) renew(h *hold) {
+	keepAlive(h, m.l.ttl, func() (bool, error) {
+		return m.l.p.CompareAndExpire(m.Key, h.token, m.l.ttl)
+	}, func() {
+		m.mu.Lock()
+		if m.h == h {
+			m.h = nil
+		}
+		m.mu.Unlock()
+	})
+}
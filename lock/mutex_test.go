@@ -0,0 +1,182 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestMutex_TryLock(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			l := NewLocker("ns", cachetest.Prefix("lock"), 1, p, time.Second)
+			m1, m2 := l.Key("order"), l.Key("order")
+
+			if ok, err := m1.TryLock(); !ok || err != nil {
+				t.Fatalf("TryLock() = %v, %v", ok, err)
+			}
+			if ok, err := m2.TryLock(); ok || err != nil {
+				t.Errorf("TryLock() on held lock = %v, %v", ok, err)
+			}
+			if _, err := m1.TryLock(); err != ErrHeld {
+				t.Errorf("TryLock() twice error = %v, want ErrHeld", err)
+			}
+			if ok, err := l.Key("other").TryLock(); !ok || err != nil {
+				t.Errorf("TryLock() on another key = %v, %v", ok, err)
+			}
+
+			if err := m1.Unlock(); err != nil {
+				t.Fatalf("Unlock() error = %v", err)
+			}
+			if err := m1.Unlock(); err != ErrNotHeld {
+				t.Errorf("Unlock() twice error = %v, want ErrNotHeld", err)
+			}
+			if ok, err := m2.TryLock(); !ok || err != nil {
+				t.Errorf("TryLock() after Unlock() = %v, %v", ok, err)
+			}
+			m2.Unlock()
+		})
+	}
+}
+
+func TestMutex_UnlockAfterExpired(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			l := NewLocker("ns", cachetest.Prefix("lock"), 0, p, time.Second)
+			m1, m2 := l.Key(), l.Key()
+
+			m1.TryLock()
+			p.Remove(m1.Key) // 模拟租约过期。
+			if ok, _ := m2.TryLock(); !ok {
+				t.Fatalf("TryLock() after expired should succeed")
+			}
+
+			// m1 不能释放 m2 持有的锁。
+			if err := m1.Unlock(); err != ErrNotHeld {
+				t.Errorf("Unlock() expired lock error = %v, want ErrNotHeld", err)
+			}
+			if ok, _ := l.Key().TryLock(); ok {
+				t.Errorf("lock of m2 should not be removed by m1")
+			}
+			if err := m2.Unlock(); err != nil {
+				t.Errorf("Unlock() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestMutex_Renew(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			l := NewLocker("ns", cachetest.Prefix("lock"), 0, p, 90*time.Millisecond)
+			m1, m2 := l.Key(), l.Key()
+
+			m1.TryLock()
+			defer m1.Unlock()
+
+			// 持有时间超过租约，自动续期。
+			time.Sleep(250 * time.Millisecond)
+			if ok, _ := m2.TryLock(); ok {
+				t.Errorf("TryLock() should fail while the lease is renewed")
+			}
+			if !m1.Held() {
+				t.Errorf("Held() = false, want true")
+			}
+
+			// 锁被其他持有者获取后，续期失败，持有结束。
+			done := m1.Done()
+			p.Remove(m1.Key)
+			m2.TryLock()
+			defer m2.Unlock()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("Done() should be closed after the lock is lost")
+			}
+			if m1.Held() {
+				t.Errorf("Held() after lost = true, want false")
+			}
+		})
+	}
+}
+
+// failingProvider 可以使续期出错的缓存提供器，模拟网络异常。
+type failingProvider struct {
+	cache.CompareCacheProvider
+	failing int32
+}
+
+func (p *failingProvider) CompareAndExpire(key string, expected any, t time.Duration) (bool, error) {
+	if atomic.LoadInt32(&p.failing) != 0 {
+		return false, errors.New("network error")
+	}
+	return p.CompareCacheProvider.CompareAndExpire(key, expected, t)
+}
+
+func TestMutex_RenewError(t *testing.T) {
+	p := &failingProvider{CompareCacheProvider: cache.NewMemoryCacheProvider(time.Second)}
+	l := NewLocker("ns", cachetest.Prefix("lock"), 0, p, 90*time.Millisecond)
+	m := l.Key()
+	if ok, err := m.TryLock(); !ok || err != nil {
+		t.Fatalf("TryLock() = %v, %v", ok, err)
+	}
+	defer m.Unlock()
+
+	// 续期出错时继续重试，在租约过期之前结束持有。
+	start := time.Now()
+	atomic.StoreInt32(&p.failing, 1)
+	select {
+	case <-m.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Done() should be closed when renewals keep failing")
+	}
+	if d := time.Since(start); d > 90*time.Millisecond {
+		t.Errorf("Done() after %v, want before the lease expires", d)
+	}
+	if m.Held() {
+		t.Errorf("Held() = true, want false")
+	}
+}
+
+func TestMutex_Lock(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			l := NewLocker("ns", cachetest.Prefix("lock"), 0, p, time.Second).WithBackoff(time.Millisecond, 20*time.Millisecond)
+			m1, m2 := l.Key(), l.Key()
+
+			m1.TryLock()
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				m1.Unlock()
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := m2.Lock(ctx); err != nil {
+				t.Fatalf("Lock() error = %v", err)
+			}
+			defer m2.Unlock()
+
+			ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if err := l.Key().Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Lock() on held lock error = %v, want DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestNewLocker_Unsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewLocker() should panic with unsupported provider")
+		}
+	}()
+	NewLocker("ns", "lock", 0, struct{ cache.CacheProvider }{cache.NewMemoryCacheProvider(time.Second)}, time.Second)
+}
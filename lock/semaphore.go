@@ -0,0 +1,470 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// semaphoreAcquireScript 清理过期的持有者和等待者，按排队顺序尝试获取一个许可。
+// 等待者按照入队的顺序排队，只有排在前面的等待者都获取许可之后，后来者才能获取，
+// 入队序号是队列中最大的序号加 1 ，只关心先后顺序。
+//  KEYS[1]: 持有者，成员是令牌，分数是租约的到期时间（毫秒）。
+//  KEYS[2]: 等待队列，成员是令牌，分数是入队序号。
+//  KEYS[3]: 等待者的租约，成员是令牌，分数是租约的到期时间（毫秒），等待者停止轮询后被移出队列。
+//  ARGV[1]: 令牌。
+//  ARGV[2]: 许可的数量。
+//  ARGV[3]: 当前时间（毫秒）。
+//  ARGV[4]: 租约的时长（毫秒）。
+//  ARGV[5]: 1 获取失败时留在队列中等待；0 获取失败时离开队列。
+//  return: 1 获取成功；0 获取失败。
+var semaphoreAcquireScript = redis.NewScript(`
+local token, limit = ARGV[1], tonumber(ARGV[2])
+local now, ttl = tonumber(ARGV[3]), tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+for _, m in ipairs(redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now)) do
+	redis.call('ZREM', KEYS[2], m)
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', now)
+
+local rank = redis.call('ZRANK', KEYS[2], token)
+if not rank then
+	local last = redis.call('ZRANGE', KEYS[2], -1, -1, 'WITHSCORES')
+	local ticket = 1
+	if #last > 0 then
+		ticket = tonumber(last[2]) + 1
+	end
+	redis.call('ZADD', KEYS[2], ticket, token)
+	rank = redis.call('ZCARD', KEYS[2]) - 1
+end
+
+local acquired = rank < limit - redis.call('ZCARD', KEYS[1])
+if acquired or ARGV[5] == '0' then
+	redis.call('ZREM', KEYS[2], token)
+	redis.call('ZREM', KEYS[3], token)
+else
+	redis.call('ZADD', KEYS[3], now + ttl, token)
+end
+if acquired then
+	redis.call('ZADD', KEYS[1], now + ttl, token)
+end
+
+-- 缓存的过期时间取最晚的租约。
+local function expire(key, leases)
+	local last = redis.call('ZRANGE', leases, -1, -1, 'WITHSCORES')
+	if #last > 0 then
+		redis.call('PEXPIRE', key, math.max(tonumber(last[2]) - now, 1))
+	end
+end
+expire(KEYS[1], KEYS[1])
+expire(KEYS[2], KEYS[3])
+expire(KEYS[3], KEYS[3])
+
+if acquired then
+	return 1
+end
+return 0
+`)
+
+// semaphoreRenewScript 仅当令牌仍持有许可时续期。
+//  ARGV[1]: 令牌。
+//  ARGV[2]: 当前时间（毫秒）。
+//  ARGV[3]: 租约的时长（毫秒）。
+var semaphoreRenewScript = redis.NewScript(`
+local deadline = redis.call('ZSCORE', KEYS[1], ARGV[1])
+local now, ttl = tonumber(ARGV[2]), tonumber(ARGV[3])
+if not deadline or tonumber(deadline) <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// semaphoreReleaseScript 移除令牌的许可和排队。
+//  ARGV[1]: 令牌。
+//  ARGV[2]: 当前时间（毫秒）。
+//  return: 1 令牌持有未过期的许可；0 反之。
+var semaphoreReleaseScript = redis.NewScript(`
+local deadline = redis.call('ZSCORE', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+if deadline and tonumber(deadline) > tonumber(ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// Semaphore 分布式计数信号量的操作对象，同一个 key 最多同时发放 limit 个许可。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个信号量。
+// 在 redis 中，一个信号量由 {<key>}:holders 、{<key>}:queue 、{<key>}:waiters 三个有序集合组成，
+// 哈希标签保证它们位于 redis 集群的同一个哈希槽。
+// 持有者和等待者都有租约，持有者异常退出时许可在租约到期后自动回收。
+// 使用调用方的本地时间计算租约，多个节点共用 redis 时，各节点的时钟应当保持同步。
+type Semaphore struct {
+	op    *cache.Operation
+	p     cache.CacheProvider
+	limit int64
+	ttl   time.Duration
+
+	backoffMin, backoffMax time.Duration
+
+	now func() time.Time // 获取当前时间，便于测试。
+}
+
+// NewSemaphore 创建一个分布式计数信号量的操作对象。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// limit: 同时发放的许可数量。
+// cacheProvider: 必须是 RedisCacheProvider 或者 MemoryCacheProvider 。
+// ttl: 许可的租约时长，持有期间每 ttl/3 自动续期。
+func NewSemaphore(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	limit int64,
+	cacheProvider cache.CacheProvider,
+	ttl time.Duration,
+	opts ...cache.OperationOption,
+) *Semaphore {
+	switch cacheProvider.(type) {
+	case *cache.RedisCacheProvider, *cache.MemoryCacheProvider:
+	default:
+		panic(fmt.Errorf("cache provider %T is not supported, use RedisCacheProvider or MemoryCacheProvider", cacheProvider))
+	}
+
+	if limit <= 0 {
+		panic(fmt.Errorf("param 'limit' must be greater than 0"))
+	}
+
+	if ttl < 3*time.Millisecond {
+		panic(fmt.Errorf("param 'ttl' must not be less than 3ms"))
+	}
+
+	op := cache.NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, nil, opts...)
+	return &Semaphore{op, cacheProvider, limit, ttl, defaultBackoffMin, defaultBackoffMax, time.Now}
+}
+
+// WithBackoff 返回一个使用指定重试间隔的 Semaphore ，阻塞获取许可时重试间隔从 min 开始指数增长到 max 。
+// 等待者需要在租约内重新轮询才能保留排队的位置，因此实际的重试间隔不超过 ttl/3 。
+func (s *Semaphore) WithBackoff(min, max time.Duration) *Semaphore {
+	checkBackoff(min, max)
+	return &Semaphore{s.op, s.p, s.limit, s.ttl, min, max, s.now}
+}
+
+// Key 获取指定信号量的操作对象，每次调用返回一个新的持有者。
+func (s *Semaphore) Key(keys ...any) *Permit {
+	keyOp := s.op.Key(keys...)
+	return &Permit{s: s, err: keyOp.Err(), Key: keyOp.Key}
+}
+
+// Permit 分布式信号量的一个持有者，可以被多个 goroutine 使用，但同一时间最多持有一个许可。
+type Permit struct {
+	s   *Semaphore
+	mu  sync.Mutex
+	h   *hold // 当前的持有，没有持有时为 nil 。
+	err error // 构建缓存key时的错误，不为 nil 时获取许可都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// TryAcquire 尝试获取一个许可，不等待。有等待者在排队时，即使有空闲的许可也不能插队。
+//  return: true 获取成功；false 没有可用的许可。当前对象已经持有许可时返回 ErrHeld 。
+func (p *Permit) TryAcquire() (bool, error) {
+	if p.err != nil {
+		return false, p.err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.h != nil {
+		return false, ErrHeld
+	}
+	return p.acquire(newToken(), false)
+}
+
+// Acquire 阻塞获取一个许可，按照排队的先后顺序获取，直到获取成功或者 ctx 结束。
+//  return: ctx 结束时离开队列并返回 ctx.Err() 。
+func (p *Permit) Acquire(ctx context.Context) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	b := &backoff{min: p.s.backoffMin, max: p.s.backoffMax}
+	if limit := p.s.ttl / 3; b.max > limit {
+		b.max = limit
+		if b.min > limit {
+			b.min = limit
+		}
+	}
+
+	token := newToken()
+	for {
+		p.mu.Lock()
+		ok, err := false, ErrHeld
+		if p.h == nil {
+			ok, err = p.acquire(token, true)
+		}
+		p.mu.Unlock()
+
+		if err != nil || ok {
+			if err != nil {
+				p.s.release(p.Key, token)
+			}
+			return err
+		}
+
+		if err = b.wait(ctx); err != nil {
+			p.s.release(p.Key, token)
+			return err
+		}
+	}
+}
+
+// acquire 使用指定的令牌获取许可，获取成功时开始续期，调用方需要持有 p.mu 。
+//  wait: 获取失败时是否留在队列中等待。
+func (p *Permit) acquire(token string, wait bool) (bool, error) {
+	var ok bool
+	var err error
+	switch cp := p.s.p.(type) {
+	case *cache.RedisCacheProvider:
+		ok, err = p.s.redisAcquire(cp, p.Key, token, wait)
+	case *cache.MemoryCacheProvider:
+		ok, err = p.s.memoryAcquire(cp, p.Key, token, wait)
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+
+	h := newHold(token)
+	p.h = h
+	go p.renew(h)
+	return true, nil
+}
+
+// Release 释放持有的许可。
+//  return: 没有持有许可，或者租约已经过期时返回 ErrNotHeld 。
+func (p *Permit) Release() error {
+	p.mu.Lock()
+	h := p.h
+	p.h = nil
+	p.mu.Unlock()
+
+	if h == nil {
+		return ErrNotHeld
+	}
+
+	close(h.stop)
+	defer h.end()
+
+	ok, err := p.s.release(p.Key, h.token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Held 判断当前对象是否持有许可。
+// 租约在续期之前过期时，最多在 ttl/3 之后才能发现。
+func (p *Permit) Held() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.h != nil
+}
+
+// Done 返回一个在本次持有结束时关闭的通道：许可被释放，或者续期时发现租约已经过期。
+// 没有持有许可时返回一个已关闭的通道。
+func (p *Permit) Done() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.h.doneChan()
+}
+
+// renew 持有期间每 ttl/3 续期一次，续期时发现租约已经过期，或者续期一直出错直到租约可能过期，则结束持有。
+func (p *Permit) renew(h *hold) {
+	keepAlive(h, p.s.ttl, func() (bool, error) {
+		return p.s.renew(p.Key, h.token)
+	}, func() {
+		p.mu.Lock()
+		if p.h == h {
+			p.h = nil
+		}
+		p.mu.Unlock()
+	})
+}
+
+// semaphoreKeys 获取信号量在 redis 中的三个有序集合。
+func semaphoreKeys(key string) []string {
+	tag := "{" + key + "}:"
+	return []string{tag + "holders", tag + "queue", tag + "waiters"}
+}
+
+// redisAcquire 在服务端执行 semaphoreAcquireScript 。
+func (s *Semaphore) redisAcquire(p *cache.RedisCacheProvider, key, token string, wait bool) (bool, error) {
+	w := 0
+	if wait {
+		w = 1
+	}
+
+	n, err := semaphoreAcquireScript.Run(context.Background(), p.Client(), semaphoreKeys(key),
+		token, s.limit, s.now().UnixMilli(), s.ttl.Milliseconds(), w).Int64()
+	return n == 1, err
+}
+
+// renew 续期令牌持有的许可。
+//  return: false 令牌已经不再持有许可。
+func (s *Semaphore) renew(key, token string) (bool, error) {
+	now, ttl := s.now().UnixMilli(), s.ttl.Milliseconds()
+	switch p := s.p.(type) {
+	case *cache.RedisCacheProvider:
+		n, err := semaphoreRenewScript.Run(context.Background(), p.Client(), semaphoreKeys(key)[:1], token, now, ttl).Int64()
+		return n == 1, err
+	default:
+		var ok bool
+		err := s.memoryUpdate(p.(*cache.MemoryCacheProvider), key, now, func(state *semaphoreState) {
+			if _, ok = state.holders[token]; ok {
+				state.holders[token] = now + ttl
+			}
+		})
+		return ok, err
+	}
+}
+
+// release 移除令牌的许可和排队。
+//  return: false 令牌没有持有未过期的许可。
+func (s *Semaphore) release(key, token string) (bool, error) {
+	now := s.now().UnixMilli()
+	switch p := s.p.(type) {
+	case *cache.RedisCacheProvider:
+		n, err := semaphoreReleaseScript.Run(context.Background(), p.Client(), semaphoreKeys(key), token, now).Int64()
+		return n == 1, err
+	default:
+		var ok bool
+		err := s.memoryUpdate(p.(*cache.MemoryCacheProvider), key, now, func(state *semaphoreState) {
+			_, ok = state.holders[token]
+			delete(state.holders, token)
+			state.dequeue(token)
+		})
+		return ok, err
+	}
+}
+
+// semaphoreState 内存缓存中的信号量。
+type semaphoreState struct {
+	holders map[string]int64   // 持有者的令牌及其租约的到期时间（毫秒）。
+	queue   []semaphoreWaiter // 按入队顺序排列的等待者。
+}
+
+// semaphoreWaiter 等待者的令牌及其租约的到期时间（毫秒）。
+type semaphoreWaiter struct {
+	token    string
+	deadline int64
+}
+
+// clean 移除租约已经过期的持有者和等待者。
+func (state *semaphoreState) clean(now int64) {
+	for token, deadline := range state.holders {
+		if deadline <= now {
+			delete(state.holders, token)
+		}
+	}
+
+	queue := state.queue[:0]
+	for _, w := range state.queue {
+		if w.deadline > now {
+			queue = append(queue, w)
+		}
+	}
+	state.queue = queue
+}
+
+// rank 获取等待者在队列中的位置，不在队列中时返回 -1 。
+func (state *semaphoreState) rank(token string) int {
+	for i, w := range state.queue {
+		if w.token == token {
+			return i
+		}
+	}
+	return -1
+}
+
+// dequeue 将等待者移出队列。
+func (state *semaphoreState) dequeue(token string) {
+	if i := state.rank(token); i >= 0 {
+		state.queue = append(state.queue[:i], state.queue[i+1:]...)
+	}
+}
+
+// ttl 获取缓存的过期时长，取最晚的租约。
+func (state *semaphoreState) ttl(now int64) time.Duration {
+	var last int64
+	for _, deadline := range state.holders {
+		if deadline > last {
+			last = deadline
+		}
+	}
+	for _, w := range state.queue {
+		if w.deadline > last {
+			last = w.deadline
+		}
+	}
+
+	if last <= now { // 没有持有者和等待者，保留 1 毫秒后过期。
+		return time.Millisecond
+	}
+	return time.Duration(last-now) * time.Millisecond
+}
+
+// memoryAcquire 在锁内执行与 semaphoreAcquireScript 相同的算法。
+func (s *Semaphore) memoryAcquire(p *cache.MemoryCacheProvider, key, token string, wait bool) (bool, error) {
+	now, ttl := s.now().UnixMilli(), s.ttl.Milliseconds()
+
+	var acquired bool
+	err := s.memoryUpdate(p, key, now, func(state *semaphoreState) {
+		rank := state.rank(token)
+		if rank < 0 {
+			state.queue = append(state.queue, semaphoreWaiter{token, now + ttl})
+			rank = len(state.queue) - 1
+		}
+
+		acquired = int64(rank) < s.limit-int64(len(state.holders))
+		if acquired || !wait {
+			state.dequeue(token)
+		} else {
+			state.queue[rank].deadline = now + ttl
+		}
+		if acquired {
+			state.holders[token] = now + ttl
+		}
+	})
+	return acquired, err
+}
+
+// memoryUpdate 在锁内移除过期的持有者和等待者，然后修改内存缓存中的信号量，修改后按最晚的租约更新过期时间。
+func (s *Semaphore) memoryUpdate(p *cache.MemoryCacheProvider, key string, now int64, fn func(state *semaphoreState)) error {
+	return p.Update(key, func(value any, found bool) (any, time.Duration, error) {
+		state := &semaphoreState{holders: map[string]int64{}}
+		if found {
+			v, ok := value.(*semaphoreState)
+			if !ok {
+				return nil, 0, fmt.Errorf("unexpected value type %T of key '%s'", value, key)
+			}
+			state = v
+		}
+
+		state.clean(now)
+		fn(state)
+		return state, state.ttl(now), nil
+	})
+}
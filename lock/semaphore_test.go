@@ -0,0 +1,144 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestSemaphore_TryAcquire(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			s := NewSemaphore("ns", cachetest.Prefix("lock"), 1, 2, p, time.Second)
+			p1, p2, p3 := s.Key("api"), s.Key("api"), s.Key("api")
+
+			for i, pm := range []*Permit{p1, p2} {
+				if ok, err := pm.TryAcquire(); !ok || err != nil {
+					t.Fatalf("TryAcquire() #%d = %v, %v", i, ok, err)
+				}
+			}
+			if ok, err := p3.TryAcquire(); ok || err != nil {
+				t.Errorf("TryAcquire() over limit = %v, %v", ok, err)
+			}
+			if _, err := p1.TryAcquire(); err != ErrHeld {
+				t.Errorf("TryAcquire() twice error = %v, want ErrHeld", err)
+			}
+			if ok, err := s.Key("other").TryAcquire(); !ok || err != nil {
+				t.Errorf("TryAcquire() on another key = %v, %v", ok, err)
+			}
+
+			if err := p1.Release(); err != nil {
+				t.Fatalf("Release() error = %v", err)
+			}
+			if err := p1.Release(); err != ErrNotHeld {
+				t.Errorf("Release() twice error = %v, want ErrNotHeld", err)
+			}
+			if ok, err := p3.TryAcquire(); !ok || err != nil {
+				t.Errorf("TryAcquire() after Release() = %v, %v", ok, err)
+			}
+			p2.Release()
+			p3.Release()
+		})
+	}
+}
+
+func TestSemaphore_Fairness(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			s := NewSemaphore("ns", cachetest.Prefix("lock"), 0, 1, p, time.Second).WithBackoff(5*time.Millisecond, 10*time.Millisecond)
+			p1, p2, p3 := s.Key(), s.Key(), s.Key()
+
+			p1.TryAcquire()
+			acquired := make(chan error, 1)
+			go func() {
+				acquired <- p2.Acquire(context.Background())
+			}()
+
+			// p2 已经在排队，释放的许可不能被后来的 p3 获取。
+			time.Sleep(50 * time.Millisecond)
+			p1.Release()
+			if ok, err := p3.TryAcquire(); ok || err != nil {
+				t.Errorf("TryAcquire() should not jump the queue, got %v, %v", ok, err)
+			}
+
+			select {
+			case err := <-acquired:
+				if err != nil {
+					t.Fatalf("Acquire() error = %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Acquire() should succeed after the permit is released")
+			}
+			if !p2.Held() {
+				t.Errorf("Held() = false, want true")
+			}
+			p2.Release()
+		})
+	}
+}
+
+func TestSemaphore_AcquireCanceled(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			s := NewSemaphore("ns", cachetest.Prefix("lock"), 0, 1, p, time.Second).WithBackoff(time.Millisecond, 10*time.Millisecond)
+			p1 := s.Key()
+			p1.TryAcquire()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if err := s.Key().Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Acquire() error = %v, want DeadlineExceeded", err)
+			}
+
+			// 取消的等待者已经离开队列。
+			p1.Release()
+			if ok, err := s.Key().TryAcquire(); !ok || err != nil {
+				t.Errorf("TryAcquire() after canceled waiter = %v, %v", ok, err)
+			}
+		})
+	}
+}
+
+func TestSemaphore_Expired(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			s := NewSemaphore("ns", cachetest.Prefix("lock"), 0, 1, p, 60*time.Millisecond)
+			p1, p2 := s.Key(), s.Key()
+
+			// 持有期间自动续期。
+			p1.TryAcquire()
+			time.Sleep(150 * time.Millisecond)
+			if ok, _ := p2.TryAcquire(); ok {
+				t.Fatalf("TryAcquire() should fail while the lease is renewed")
+			}
+
+			// 模拟持有者异常退出，停止续期后许可在租约到期后回收。
+			close(p1.h.stop)
+			time.Sleep(100 * time.Millisecond)
+			if ok, err := p2.TryAcquire(); !ok || err != nil {
+				t.Fatalf("TryAcquire() after lease expired = %v, %v", ok, err)
+			}
+			defer p2.Release()
+
+			p1.mu.Lock()
+			p1.h = newHold(p1.h.token) // 已经停止续期的持有。
+			p1.mu.Unlock()
+			if err := p1.Release(); err != ErrNotHeld {
+				t.Errorf("Release() expired permit error = %v, want ErrNotHeld", err)
+			}
+		})
+	}
+}
+
+func TestNewSemaphore_Unsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewSemaphore() should panic with unsupported provider")
+		}
+	}()
+	NewSemaphore("ns", "sem", 0, 1, struct{ cache.CacheProvider }{cache.NewMemoryCacheProvider(time.Second)}, time.Second)
+}
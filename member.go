@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/cmstar/go-conv"
+)
+
+// encodeMember 将集合的成员编码成字符串。
+// 成员用于判断是否相同，编码结果必须是确定的，所以不使用 Codec（例如加密的结果每次都不同），
+// 基础类型转换成字符串，其他类型使用 JSON 。
+func encodeMember(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	if v != nil && conv.IsPrimitiveType(reflect.TypeOf(v)) {
+		var s string
+		err := conv.Convert(v, &s)
+		return s, err
+	}
+
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// decodeMember 将 encodeMember 的编码结果解码到 v，v 必须是指针。
+func decodeMember(s string, v any) error {
+	if p, ok := v.(*string); ok {
+		*p = s
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr && conv.IsPrimitiveType(t.Elem()) {
+		return conv.Convert(s, v)
+	}
+
+	return json.Unmarshal([]byte(s), v)
+}
+
+// encodeMembers 是 encodeMember 的批量版。
+func encodeMembers[T any](members []T) ([]string, error) {
+	res := make([]string, len(members))
+	for i, m := range members {
+		s, err := encodeMember(m)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = s
+	}
+	return res, nil
+}
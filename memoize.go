@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MemoizedError 从缓存中读取的函数错误，只保留了错误信息。
+type MemoizedError struct {
+	Msg string
+}
+
+func (e *MemoizedError) Error() string {
+	return e.Msg
+}
+
+// MemoizeOption Memoize1 等函数的可选配置。
+type MemoizeOption func(*memoizeConfig)
+
+type memoizeConfig struct {
+	errExp *Expiration // 非 nil 时缓存函数返回的错误。
+	dedup  bool
+}
+
+// WithErrorCaching 缓存函数返回的错误，过期之前相同参数的调用直接返回 *MemoizedError ，
+// 用于避免下游持续失败时被反复调用。 ctx 结束导致的错误不缓存。
+// 错误信息保存在缓存key <CacheNamespace>:<Prefix>#err[:unique flag] 中，与函数结果的缓存key不会冲突。
+func WithErrorCaching(exp *Expiration) MemoizeOption {
+	return func(c *memoizeConfig) {
+		if exp == nil {
+			panic(fmt.Errorf("param 'exp' must not be nil"))
+		}
+		c.errExp = exp
+	}
+}
+
+// WithDedup 合并当前进程内相同缓存 key 的并发调用，只有一个调用读取缓存或者执行函数，其他调用等待并共享结果。
+// 共享的调用使用第一个调用的 ctx 。
+func WithDedup() MemoizeOption {
+	return func(c *memoizeConfig) {
+		c.dedup = true
+	}
+}
+
+type bypassMemoizeKey struct{}
+
+// BypassMemoize 返回一个跳过缓存的 ctx ，使用它调用 Memoize1 等函数返回的函数时，直接执行原函数，不读取也不写入缓存。
+func BypassMemoize(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassMemoizeKey{}, true)
+}
+
+// Memoize0 缓存函数 fn 的结果，返回相同签名的函数。
+// 缓存存在时直接返回缓存的值，反之执行 fn 并缓存其结果；
+// 读取缓存出错时（例如网络异常）直接执行 fn ，不写入缓存；写入缓存失败时仍然返回 fn 的结果。
+func Memoize0[V any](
+	op *Operation0[V],
+	fn func(ctx context.Context) (V, error),
+	opts ...MemoizeOption,
+) func(ctx context.Context) (V, error) {
+	m := newMemoizer[V](&op.op, opts)
+	return func(ctx context.Context) (V, error) {
+		return m.call(ctx, op.Key(), nil, func() (V, error) { return fn(ctx) })
+	}
+}
+
+// Memoize1 缓存函数 fn 的结果，参数作为缓存 key 的 unique flag ，返回相同签名的函数。
+// 缓存的行为与 Memoize0 相同。
+func Memoize1[K UniqueFlag, V any](
+	op *Operation1[K, V],
+	fn func(ctx context.Context, k K) (V, error),
+	opts ...MemoizeOption,
+) func(ctx context.Context, k K) (V, error) {
+	m := newMemoizer[V](&op.op, opts)
+	return func(ctx context.Context, k K) (V, error) {
+		return m.call(ctx, op.Key(k), []any{k}, func() (V, error) { return fn(ctx, k) })
+	}
+}
+
+// Memoize2 是两个参数的 Memoize1 。
+func Memoize2[K1, K2 UniqueFlag, V any](
+	op *Operation2[K1, K2, V],
+	fn func(ctx context.Context, k1 K1, k2 K2) (V, error),
+	opts ...MemoizeOption,
+) func(ctx context.Context, k1 K1, k2 K2) (V, error) {
+	m := newMemoizer[V](&op.op, opts)
+	return func(ctx context.Context, k1 K1, k2 K2) (V, error) {
+		return m.call(ctx, op.Key(k1, k2), []any{k1, k2}, func() (V, error) { return fn(ctx, k1, k2) })
+	}
+}
+
+// Memoize3 是三个参数的 Memoize1 。
+func Memoize3[K1, K2, K3 UniqueFlag, V any](
+	op *Operation3[K1, K2, K3, V],
+	fn func(ctx context.Context, k1 K1, k2 K2, k3 K3) (V, error),
+	opts ...MemoizeOption,
+) func(ctx context.Context, k1 K1, k2 K2, k3 K3) (V, error) {
+	m := newMemoizer[V](&op.op, opts)
+	return func(ctx context.Context, k1 K1, k2 K2, k3 K3) (V, error) {
+		return m.call(ctx, op.Key(k1, k2, k3), []any{k1, k2, k3}, func() (V, error) { return fn(ctx, k1, k2, k3) })
+	}
+}
+
+// Memoize4 是四个参数的 Memoize1 。
+func Memoize4[K1, K2, K3, K4 UniqueFlag, V any](
+	op *Operation4[K1, K2, K3, K4, V],
+	fn func(ctx context.Context, k1 K1, k2 K2, k3 K3, k4 K4) (V, error),
+	opts ...MemoizeOption,
+) func(ctx context.Context, k1 K1, k2 K2, k3 K3, k4 K4) (V, error) {
+	m := newMemoizer[V](&op.op, opts)
+	return func(ctx context.Context, k1 K1, k2 K2, k3 K3, k4 K4) (V, error) {
+		return m.call(ctx, op.Key(k1, k2, k3, k4), []any{k1, k2, k3, k4}, func() (V, error) { return fn(ctx, k1, k2, k3, k4) })
+	}
+}
+
+// memoizer 缓存一个函数的结果。
+type memoizer[V any] struct {
+	memoizeConfig
+	errOp *Operation // 保存函数返回的错误， nil 表示不缓存错误。
+	group flightGroup
+}
+
+func newMemoizer[V any](op *Operation, opts []MemoizeOption) *memoizer[V] {
+	m := &memoizer[V]{}
+	for _, opt := range opts {
+		opt(&m.memoizeConfig)
+	}
+
+	if m.errExp != nil {
+		errOp := *op // 共享 op 的代数，BumpGeneration 同时使缓存的错误失效。
+		errOp.keyBase += "#err"
+		errOp.expireTime = m.errExp
+		m.errOp = &errOp
+	}
+	return m
+}
+
+// call 通过缓存执行 fn 。
+//  @keys: keyOp 的 unique flag ，用于构建保存错误的缓存key。
+func (m *memoizer[V]) call(ctx context.Context, keyOp *KeyOperationT[V], keys []any, fn func() (V, error)) (V, error) {
+	if ctx.Value(bypassMemoizeKey{}) != nil {
+		return fn()
+	}
+
+	if !m.dedup {
+		return m.load(keyOp, keys, fn)
+	}
+
+	v, err := m.group.do(keyOp.Key, func() (any, error) {
+		return m.load(keyOp, keys, fn)
+	})
+	res, _ := v.(V)
+	return res, err
+}
+
+// load 读取缓存，缓存不存在时执行 fn 并缓存其结果；读取缓存出错时直接执行 fn ，不写入缓存。
+func (m *memoizer[V]) load(keyOp *KeyOperationT[V], keys []any, fn func() (V, error)) (V, error) {
+	v, ok, err := keyOp.TryGet()
+	if err != nil {
+		return fn()
+	}
+	if ok {
+		return v, nil
+	}
+
+	var errKeyOp *KeyOperation
+	if m.errOp != nil {
+		errKeyOp = m.errOp.Key(keys...)
+		var msg string
+		ok, err := errKeyOp.TryGet(&msg)
+		if err != nil {
+			return fn()
+		}
+		if ok {
+			return v, &MemoizedError{msg}
+		}
+	}
+
+	v, err = fn()
+	if err != nil {
+		if errKeyOp != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			errKeyOp.Set(err.Error())
+		}
+		return v, err
+	}
+
+	keyOp.Set(v)
+	return v, nil
+}
+
+// flightGroup 合并相同 key 的并发调用。
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall 正在执行的调用。
+type flightCall struct {
+	wg  sync.WaitGroup
+	val any
+	err error
+}
+
+// do 执行 fn ，相同 key 的调用正在执行时等待其结果。
+func (g *flightGroup) do(key string, fn func() (any, error)) (any, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+
+	c := &flightCall{err: fmt.Errorf("memoized function of key '%s' panicked", key)}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.val, c.err = fn()
+	return c.val, c.err
+}
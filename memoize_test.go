@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := fmt.Sprint("memoize_", time.Now().UnixNano())
+			var calls int32
+			get := Memoize2(
+				NewOperation2[string, int, Person]("ns", prefix, p, NewExpiration(time.Minute, 0)),
+				func(ctx context.Context, name string, age int) (Person, error) {
+					atomic.AddInt32(&calls, 1)
+					return Person{name, age}, nil
+				})
+
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				v, err := get(ctx, "a", 1)
+				if err != nil || v != (Person{"a", 1}) {
+					t.Fatalf("get() = %v, %v", v, err)
+				}
+			}
+			get(ctx, "a", 2)
+			if calls != 2 {
+				t.Errorf("calls = %d, want 2", calls)
+			}
+
+			// 跳过缓存。
+			get(BypassMemoize(ctx), "a", 1)
+			if calls != 3 {
+				t.Errorf("calls with BypassMemoize = %d, want 3", calls)
+			}
+		})
+	}
+}
+
+func TestMemoize_ErrorCaching(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			prefix := fmt.Sprint("memoize_err_", time.Now().UnixNano())
+			errDown := errors.New("downstream unavailable")
+			var calls int32
+			fn := func(ctx context.Context, id int) (string, error) {
+				atomic.AddInt32(&calls, 1)
+				if err := ctx.Err(); err != nil {
+					return "", err
+				}
+				return "", errDown
+			}
+
+			// 默认不缓存错误。
+			get := Memoize1(NewOperation1[int, string]("ns", prefix, p, NewExpiration(time.Minute, 0)), fn)
+			get(context.Background(), 1)
+			if _, err := get(context.Background(), 1); err != errDown || calls != 2 {
+				t.Errorf("get() error = %v, calls = %d", err, calls)
+			}
+
+			get = Memoize1(NewOperation1[int, string]("ns", prefix+"_cached", p, NewExpiration(time.Minute, 0)), fn,
+				WithErrorCaching(NewExpiration(time.Minute, 0)))
+			calls = 0
+			get(context.Background(), 1)
+			_, err := get(context.Background(), 1)
+			var me *MemoizedError
+			if !errors.As(err, &me) || me.Msg != errDown.Error() || calls != 1 {
+				t.Errorf("get() cached error = %v, calls = %d", err, calls)
+			}
+
+			// ctx 结束的错误不缓存。
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			get(ctx, 2)
+			if _, err := get(context.Background(), 2); err != errDown || calls != 3 {
+				t.Errorf("get() after canceled error = %v, calls = %d", err, calls)
+			}
+		})
+	}
+}
+
+// failingReadProvider 读取总是出错的缓存提供器，模拟网络异常。
+type failingReadProvider struct {
+	CacheProvider
+}
+
+func (p failingReadProvider) TryGet(key string, value any) (bool, error) {
+	return false, errors.New("network error")
+}
+
+func TestMemoize_CacheError(t *testing.T) {
+	var calls int32
+	get := Memoize1(
+		NewOperation1[int, int]("ns", "memoize_fail", failingReadProvider{NewMemoryCacheProvider(time.Second)}, nil),
+		func(ctx context.Context, id int) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			return id * 2, nil
+		},
+		WithErrorCaching(NewExpiration(time.Minute, 0)))
+
+	// 读取缓存出错时直接执行函数。
+	for i := 0; i < 2; i++ {
+		if v, err := get(context.Background(), 1); v != 2 || err != nil {
+			t.Fatalf("get() = %v, %v, want 2", v, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestMemoize_ErrorKey(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	get := Memoize1(
+		NewOperation1[string, string]("ns", "memoize_err_key", p, nil),
+		func(ctx context.Context, id string) (string, error) {
+			if id == "a" {
+				return "", errors.New("failed")
+			}
+			return "value of " + id, nil
+		},
+		WithErrorCaching(NewExpiration(time.Minute, 0)))
+
+	// 缓存的错误不会被当作以 ":err" 结尾的参数的结果。
+	get(context.Background(), "a")
+	if v, err := get(context.Background(), "a:err"); v != "value of a:err" || err != nil {
+		t.Errorf("get(a:err) = %q, %v", v, err)
+	}
+}
+
+func TestMemoize_Dedup(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	release := make(chan struct{})
+	var calls int32
+	get := Memoize1(NewOperation1[int, int]("ns", "memoize_dedup", p, NewExpiration(time.Minute, 0)),
+		func(ctx context.Context, id int) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return id * 10, nil
+		}, WithDedup())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := get(context.Background(), 7); v != 70 || err != nil {
+				t.Errorf("get() = %v, %v", v, err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
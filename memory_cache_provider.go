@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,7 +15,16 @@ import (
 // MemoryCacheProvider 内存类型的缓存提供器。
 type MemoryCacheProvider struct {
 	cache *c.Cache // 线程安全的缓存
-	mu    sync.RWMutex
+	mu    *sync.RWMutex
+
+	// 非基础类型的缓存值与接收值类型不一致时的转换方式。
+	convertMode ConvertMode
+
+	// 编码模式下缓存值的编解码器，nil 表示直接存储原始值。
+	codec Codec
+
+	// 阻塞弹出列表元素时，等待各个列表有新元素的通道，受 mu 保护。
+	listWaiters map[string]*listWaiter
 }
 
 // NewMemoryCacheProvider 用来获取内存缓存提供器。
@@ -23,11 +34,31 @@ func NewMemoryCacheProvider(cleanupInterval time.Duration) *MemoryCacheProvider
 	if cleanupInterval < time.Second {
 		panic(fmt.Errorf("'cleanupInterval' must be greater than 1 second"))
 	}
-	return &MemoryCacheProvider{c.New(cleanupInterval, cleanupInterval), sync.RWMutex{}}
+	return &MemoryCacheProvider{c.New(cleanupInterval, cleanupInterval), &sync.RWMutex{}, ConvertDefault, nil, make(map[string]*listWaiter)}
+}
+
+// WithConvertMode 返回一个与当前对象共享缓存数据，但使用指定转换方式的内存缓存提供器。
+//  @mode: 读取非基础类型时，缓存值与接收值类型不一致的转换方式，默认为 ConvertDefault 。
+func (cp *MemoryCacheProvider) WithConvertMode(mode ConvertMode) *MemoryCacheProvider {
+	return &MemoryCacheProvider{cp.cache, cp.mu, mode, cp.codec, cp.listWaiters}
+}
+
+// WithCodec 返回一个与当前对象共享缓存数据，但工作在编码模式下的内存缓存提供器。
+// 编码模式下，缓存值经 codec 编码后存储，读取时再解码，与 RedisCacheProvider 的语义一致，
+// 缓存值与调用方持有的对象不再共享内存。
+// implement CodecCacheProvider.WithCodec .
+func (cp *MemoryCacheProvider) WithCodec(codec Codec) CacheProvider {
+	if codec == nil {
+		panic(fmt.Errorf("param 'codec' is nil"))
+	}
+	return &MemoryCacheProvider{cp.cache, cp.mu, cp.convertMode, codec, cp.listWaiters}
 }
 
 var (
-	_ CacheProvider = (*MemoryCacheProvider)(nil)
+	_ CacheProvider         = (*MemoryCacheProvider)(nil)
+	_ CodecCacheProvider    = (*MemoryCacheProvider)(nil)
+	_ MultiKeyCacheProvider = (*MemoryCacheProvider)(nil)
+	_ CompareCacheProvider  = (*MemoryCacheProvider)(nil)
 )
 
 // implement CacheProvider.Get .
@@ -50,16 +81,14 @@ func (cp *MemoryCacheProvider) TryGet(key string, value any) (bool, error) {
 	if !exists {
 		return false, nil
 	}
-	itemT := reflect.TypeOf(item)
 
-	// 基础类型使用转换。
-	if conv.IsPrimitiveKind(itemT.Kind()) {
-		err := conv.Convert(item, value)
+	if err := cp.assign(key, item, value); err != nil {
+		if errors.Is(err, ErrCacheMiss) { // 编解码器要求视为缓存不存在。
+			return false, nil
+		}
 		return true, err
 	}
 
-	// 非基础类型，直接设置值， 反射不能设置 unexposed field。
-	reflect.ValueOf(value).Elem().Set(reflect.ValueOf(item))
 	return true, nil
 }
 
@@ -71,8 +100,13 @@ func (cp *MemoryCacheProvider) Create(key string, value any, t time.Duration) (b
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
+	value, err := cp.encode(value)
+	if err != nil {
+		return false, err
+	}
+
 	t = cp.legalExpireTime(t)
-	err := cp.cache.Add(key, value, t)
+	err = cp.cache.Add(key, value, t)
 	if err != nil {
 		return false, nil
 	}
@@ -85,6 +119,11 @@ func (cp *MemoryCacheProvider) Set(key string, value any, t time.Duration) error
 	if key == "" {
 		return fmt.Errorf("key must not be empty")
 	}
+	value, err := cp.encode(value)
+	if err != nil {
+		return err
+	}
+
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
@@ -123,24 +162,13 @@ func (cp *MemoryCacheProvider) Increase(key string) (int64, error) {
 		return cp.cache.IncrementInt64(key, 1)
 	}
 
-	var v64 int64
-	switch v := v.(type) {
-	case int:
-		v64 = int64(v)
-	case int8:
-		v64 = int64(v)
-	case int16:
-		v64 = int64(v)
-	case int32:
-		v64 = int64(v)
-	case int64:
-		v64 = int64(v)
-	default:
-		return 0, fmt.Errorf("unsupport type to increase: %s", reflect.TypeOf(v).Kind())
+	v64, err := cp.counterValue(v)
+	if err != nil {
+		return 0, err
 	}
 
 	// 更新 key 的数据类型，并且避免过期时间重置。
-	cp.cache.Set(key, v64, time.Duration(expireTime.UnixNano()-time.Now().UnixNano()))
+	cp.cache.Set(key, v64, cp.remainingExpireTime(expireTime))
 
 	return cp.cache.IncrementInt64(key, 1)
 }
@@ -153,9 +181,10 @@ func (cp *MemoryCacheProvider) IncreaseOrCreate(key string, increment int64, t t
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
+	// 与 redis 一致，只有新创建的 key 才设置过期时间，已存在的 key 保持原有的过期时间。
 	v, expireTime, found := cp.cache.GetWithExpiration(key)
 	if !found {
-		cp.cache.Set(key, increment, t)
+		cp.cache.Set(key, increment, cp.legalExpireTime(t))
 		return increment, nil
 	}
 
@@ -163,26 +192,222 @@ func (cp *MemoryCacheProvider) IncreaseOrCreate(key string, increment int64, t t
 		return cp.cache.IncrementInt64(key, increment)
 	}
 
-	var v64 int64
+	v64, err := cp.counterValue(v)
+	if err != nil {
+		return 0, err
+	}
+
+	// 更新 key 的数据类型，并且避免过期时间重置。
+	r := v64 + increment
+	cp.cache.Set(key, r, cp.remainingExpireTime(expireTime))
+	return r, nil
+}
+
+// Update 在写锁内原子地读取并替换缓存值，用于实现缓存语义以外的原子操作。
+// 缓存值原样存储，不经过编解码器，通常只用于调用方私有的 key 。
+//  @key: cache key.
+//  @fn: 参数是当前的缓存值， key 不存在时 found 为 false ；
+//  返回新的缓存值和过期时长（0表不过期），返回 error 时缓存不做改变。
+func (cp *MemoryCacheProvider) Update(key string, fn func(value any, found bool) (any, time.Duration, error)) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	item, found := cp.cache.Get(key)
+	value, t, err := fn(item, found)
+	if err != nil {
+		return err
+	}
+
+	cp.cache.Set(key, value, cp.legalExpireTime(t))
+	return nil
+}
+
+// implement CompareCacheProvider.CompareAndRemove .
+func (cp *MemoryCacheProvider) CompareAndRemove(key string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	equal, _, err := cp.compare(key, value)
+	if !equal {
+		return false, err
+	}
+
+	cp.cache.Delete(key)
+	return true, nil
+}
+
+// implement CompareCacheProvider.CompareAndExpire .
+func (cp *MemoryCacheProvider) CompareAndExpire(key string, value any, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	equal, item, err := cp.compare(key, value)
+	if !equal {
+		return false, err
+	}
+
+	cp.cache.Set(key, item, cp.legalExpireTime(t))
+	return true, nil
+}
+
+// compare 比较缓存值与编码后的 value ，调用方需要持有锁。
+//  return: 是否相等，以及缓存值。
+func (cp *MemoryCacheProvider) compare(key string, value any) (bool, any, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return false, nil, nil
+	}
+
+	v, err := cp.encode(value)
+	if err != nil {
+		return false, nil, err
+	}
+	return reflect.DeepEqual(item, v), item, nil
+}
+
+// implement MultiKeyCacheProvider.TryGetMulti .
+func (cp *MemoryCacheProvider) TryGetMulti(keys []string, values []any) ([]bool, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("len(keys)(%d) != len(values)(%d)", len(keys), len(values))
+	}
+
+	if err := checkKeys(keys); err != nil {
+		return nil, err
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	result := make([]bool, len(keys))
+	for i, key := range keys {
+		item, exists := cp.cache.Get(key)
+		if !exists {
+			continue
+		}
+
+		if err := cp.assign(key, item, values[i]); err != nil {
+			if errors.Is(err, ErrCacheMiss) {
+				continue
+			}
+			return nil, err
+		}
+		result[i] = true
+	}
+
+	return result, nil
+}
+
+// implement MultiKeyCacheProvider.RemoveMulti .
+func (cp *MemoryCacheProvider) RemoveMulti(keys ...string) (int64, error) {
+	if err := checkKeys(keys); err != nil {
+		return 0, err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	var n int64
+	for _, key := range keys {
+		if _, exists := cp.cache.Get(key); exists {
+			n++
+		}
+		cp.cache.Delete(key)
+	}
+	return n, nil
+}
+
+// encode 在编码模式下将缓存值编码成字节，反之原样返回。
+func (cp *MemoryCacheProvider) encode(value any) (any, error) {
+	if cp.codec == nil {
+		return value, nil
+	}
+	return cp.codec.Marshal(value)
+}
+
+// counterValue 将缓存值转换成计数器的值。
+func (cp *MemoryCacheProvider) counterValue(v any) (int64, error) {
 	switch v := v.(type) {
 	case int:
-		v64 = int64(v)
+		return int64(v), nil
 	case int8:
-		v64 = int64(v)
+		return int64(v), nil
 	case int16:
-		v64 = int64(v)
+		return int64(v), nil
 	case int32:
-		v64 = int64(v)
+		return int64(v), nil
 	case int64:
-		v64 = int64(v)
-	default:
-		return 0, fmt.Errorf("unsupport type to increase: %s", reflect.TypeOf(v).Kind())
+		return int64(v), nil
+	case []byte:
+		// 编码模式下，与 redis 一致，只要编码结果是整数的字符串形式就可以增加。
+		if cp.codec != nil {
+			v64, err := strconv.ParseInt(string(v), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("value is not an integer: %w", err)
+			}
+			return v64, nil
+		}
 	}
+	return 0, fmt.Errorf("unsupport type to increase: %s", reflect.TypeOf(v).Kind())
+}
 
-	// 更新 key 的数据类型，并且避免过期时间重置。
-	r := v64 + increment
-	cp.cache.Set(key, r, time.Duration(expireTime.UnixNano()-time.Now().UnixNano()))
-	return r, nil
+// assign 将缓存值 item 写入接收值 value 。
+func (cp *MemoryCacheProvider) assign(key string, item any, value any) error {
+	ptr := reflect.ValueOf(value)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("value must be a non-nil pointer, got %T", value)
+	}
+
+	// 缓存的是 nil，与 json 的 null 一致，value 值不做改变。
+	if item == nil {
+		return nil
+	}
+
+	// 编码模式下，计数器以外的缓存值都是编码后的字节。
+	if b, ok := item.([]byte); ok && cp.codec != nil {
+		return cp.codec.Unmarshal(b, value)
+	}
+
+	// 基础类型使用转换。
+	if conv.IsPrimitiveKind(reflect.TypeOf(item).Kind()) {
+		return conv.Convert(item, value)
+	}
+
+	// 非基础类型，按照 convertMode 设置值， 反射不能设置 unexposed field。
+	ok, err := assignValue(ptr.Elem(), reflect.ValueOf(item), cp.convertMode)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &TypeMismatchError{key, reflect.TypeOf(item), ptr.Elem().Type()}
+	}
+	return nil
+}
+
+// errWrongType 与 redis 的 WRONGTYPE 错误一致，对数据结构不匹配的 key 执行操作时返回。
+func errWrongType(key string) error {
+	return fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value: %s", key)
+}
+
+// remainingExpireTime 根据过期时间点计算剩余的过期时长，用于更新缓存值时保持过期时间不变。
+func (*MemoryCacheProvider) remainingExpireTime(expireTime time.Time) time.Duration {
+	if expireTime.IsZero() {
+		return c.NoExpiration
+	}
+
+	d := time.Until(expireTime)
+	if d <= 0 { // 已经到期，尽快过期。
+		d = time.Nanosecond
+	}
+	return d
 }
 
 func (*MemoryCacheProvider) legalExpireTime(t time.Duration) time.Duration {
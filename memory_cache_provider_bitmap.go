@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ BitmapCacheProvider = (*MemoryCacheProvider)(nil)
+
+// implement BitmapCacheProvider.BitmapSet .
+func (cp *MemoryCacheProvider) BitmapSet(key string, offset int64, value bool, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	b, err := cp.getBitmap(key)
+	if err != nil {
+		return false, err
+	}
+
+	// 与 redis 一致，设置为 0 时也创建位图。
+	if b == nil {
+		b = newBitmap()
+		cp.cache.Set(key, b, cp.legalExpireTime(t))
+	}
+	return b.set(offset, value), nil
+}
+
+// implement BitmapCacheProvider.BitmapGet .
+func (cp *MemoryCacheProvider) BitmapGet(key string, offset int64) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	b, err := cp.getBitmap(key)
+	if b == nil {
+		return false, err
+	}
+	return b.get(offset), nil
+}
+
+// implement BitmapCacheProvider.BitmapCount .
+func (cp *MemoryCacheProvider) BitmapCount(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	b, err := cp.getBitmap(key)
+	if b == nil {
+		return 0, err
+	}
+	return b.count(), nil
+}
+
+// implement BitmapCacheProvider.BitmapOp .
+func (cp *MemoryCacheProvider) BitmapOp(op BitOp, dest string, keys []string, t time.Duration) (int64, error) {
+	if dest == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOp(op, keys); err != nil {
+		return 0, err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	srcs := make([]*bitmap, len(keys))
+	for i, key := range keys {
+		b, err := cp.getBitmap(key)
+		if err != nil {
+			return 0, err
+		}
+		srcs[i] = b
+	}
+
+	// 与 redis 一致，dest 被覆盖，不论原来是什么类型。
+	res := bitOp(op, srcs)
+	if res.length == 0 {
+		cp.cache.Delete(dest)
+	} else {
+		cp.cache.Set(dest, res, cp.legalExpireTime(t))
+	}
+	return res.length, nil
+}
+
+// implement BitmapCacheProvider.BitmapPos .
+func (cp *MemoryCacheProvider) BitmapPos(key string, bit bool) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	b, err := cp.getBitmap(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if b == nil {
+		b = newBitmap()
+	}
+	return b.pos(bit), nil
+}
+
+// getBitmap 获取 key 对应的位图，位图不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getBitmap(key string) (*bitmap, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	b, ok := item.(*bitmap)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return b, nil
+}
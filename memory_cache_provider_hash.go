@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var _ HashCacheProvider = (*MemoryCacheProvider)(nil)
+
+// memoryHash 内存缓存中的哈希，字段的值与普通缓存值一样，编码模式下是编码后的字节。
+type memoryHash map[string]any
+
+// implement HashCacheProvider.HashTryGet .
+func (cp *MemoryCacheProvider) HashTryGet(key, field string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	h, err := cp.getHash(key)
+	if h == nil {
+		return false, err
+	}
+
+	item, exists := h[field]
+	if !exists {
+		return false, nil
+	}
+
+	if err = cp.assign(key, item, value); err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			return false, nil
+		}
+		return true, err
+	}
+
+	return true, nil
+}
+
+// implement HashCacheProvider.HashSet .
+func (cp *MemoryCacheProvider) HashSet(key, field string, value any, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	value, err := cp.encode(value)
+	if err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	h, err := cp.getOrCreateHash(key, t)
+	if err != nil {
+		return err
+	}
+
+	h[field] = value
+	return nil
+}
+
+// implement HashCacheProvider.HashRemove .
+func (cp *MemoryCacheProvider) HashRemove(key string, fields ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	h, err := cp.getHash(key)
+	if h == nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, field := range fields {
+		if _, exists := h[field]; exists {
+			delete(h, field)
+			n++
+		}
+	}
+
+	// 与 redis 一致，没有字段的哈希不存在。
+	if len(h) == 0 {
+		cp.cache.Delete(key)
+	}
+
+	return n, nil
+}
+
+// implement HashCacheProvider.HashGetAll .
+func (cp *MemoryCacheProvider) HashGetAll(key string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	h, err := cp.getHash(key)
+	if h == nil {
+		return false, err
+	}
+
+	err = setMapValue(value, h, func(item any, elem any) error {
+		return cp.assign(key, item, elem)
+	})
+	return err == nil, err
+}
+
+// implement HashCacheProvider.HashIncreaseOrCreate .
+func (cp *MemoryCacheProvider) HashIncreaseOrCreate(key, field string, increment int64, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	h, err := cp.getOrCreateHash(key, t)
+	if err != nil {
+		return 0, err
+	}
+
+	v, exists := h[field]
+	if !exists {
+		h[field] = increment
+		return increment, nil
+	}
+
+	v64, err := cp.counterValue(v)
+	if err != nil {
+		return 0, err
+	}
+
+	r := v64 + increment
+	h[field] = r
+	return r, nil
+}
+
+// getHash 获取 key 对应的哈希，哈希不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getHash(key string) (memoryHash, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	h, ok := item.(memoryHash)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return h, nil
+}
+
+// getOrCreateHash 获取 key 对应的哈希，哈希不存在时创建，并设置过期时长 t 。
+func (cp *MemoryCacheProvider) getOrCreateHash(key string, t time.Duration) (memoryHash, error) {
+	h, err := cp.getHash(key)
+	if err != nil || h != nil {
+		return h, err
+	}
+
+	h = make(memoryHash)
+	cp.cache.Set(key, h, cp.legalExpireTime(t))
+	return h, nil
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var _ ListCacheProvider = (*MemoryCacheProvider)(nil)
+
+// memoryList 内存缓存中的列表，元素与普通缓存值一样，编码模式下是编码后的字节。
+type memoryList struct {
+	items []any
+}
+
+// implement ListCacheProvider.ListPush .
+func (cp *MemoryCacheProvider) ListPush(key string, left bool, values []any, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	items := make([]any, len(values))
+	for i, v := range values {
+		item, err := cp.encode(v)
+		if err != nil {
+			return 0, err
+		}
+		items[i] = item
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	l, err := cp.getList(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(items) == 0 {
+		if l == nil {
+			return 0, nil
+		}
+		return int64(len(l.items)), nil
+	}
+
+	if l == nil {
+		l = &memoryList{}
+		cp.cache.Set(key, l, cp.legalExpireTime(t))
+	}
+
+	if left {
+		// 与 redis 的 LPUSH 一致，依次插入头部，最后一个元素位于最前面。
+		reversed := make([]any, 0, len(items)+len(l.items))
+		for i := len(items) - 1; i >= 0; i-- {
+			reversed = append(reversed, items[i])
+		}
+		l.items = append(reversed, l.items...)
+	} else {
+		l.items = append(l.items, items...)
+	}
+
+	// 唤醒等待该列表的阻塞弹出。
+	if w, ok := cp.listWaiters[key]; ok {
+		close(w.ch)
+		delete(cp.listWaiters, key)
+	}
+
+	return int64(len(l.items)), nil
+}
+
+// listWaiter 等待列表有新元素的通道，插入元素时关闭，同一个列表的等待者共用。
+type listWaiter struct {
+	ch chan struct{}
+	n  int // 仍在等待的等待者个数，受 mu 保护。
+}
+
+// implement ListCacheProvider.ListPop .
+func (cp *MemoryCacheProvider) ListPop(key string, left bool, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return cp.listPop(key, left, value)
+}
+
+// implement ListCacheProvider.ListBlockingPop .
+func (cp *MemoryCacheProvider) ListBlockingPop(ctx context.Context, key string, left bool, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		cp.mu.Lock()
+		ok, err := cp.listPop(key, left, value)
+		if ok || err != nil {
+			cp.mu.Unlock()
+			return ok, err
+		}
+
+		w, exists := cp.listWaiters[key]
+		if !exists {
+			w = &listWaiter{ch: make(chan struct{})}
+			cp.listWaiters[key] = w
+		}
+		w.n++
+		cp.mu.Unlock()
+
+		select {
+		case <-w.ch:
+		case <-ctx.Done():
+			cp.mu.Lock()
+			// 最后一个等待者离开时移除通道，避免一直没有插入元素的列表的通道保留下来。
+			if w.n--; w.n == 0 && cp.listWaiters[key] == w {
+				delete(cp.listWaiters, key)
+			}
+			cp.mu.Unlock()
+			return false, ctx.Err()
+		}
+	}
+}
+
+// implement ListCacheProvider.ListRange .
+func (cp *MemoryCacheProvider) ListRange(key string, start, stop int64, values any) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	l, err := cp.getList(key)
+	if err != nil {
+		return err
+	}
+
+	var items []any
+	if l != nil {
+		if start, stop, ok := listRangeIndex(start, stop, int64(len(l.items))); ok {
+			items = l.items[start : stop+1]
+		}
+	}
+
+	return setSliceValue(values, items, func(item any, elem any) error {
+		return cp.assign(key, item, elem)
+	})
+}
+
+// implement ListCacheProvider.ListTrim .
+func (cp *MemoryCacheProvider) ListTrim(key string, start, stop int64) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	l, err := cp.getList(key)
+	if l == nil {
+		return err
+	}
+
+	start, stop, ok := listRangeIndex(start, stop, int64(len(l.items)))
+	if !ok { // 与 redis 一致，没有元素的列表不存在。
+		cp.cache.Delete(key)
+		return nil
+	}
+
+	// 复制一份，避免被截掉的元素无法回收。
+	l.items = append([]any(nil), l.items[start:stop+1]...)
+	return nil
+}
+
+// implement ListCacheProvider.ListLen .
+func (cp *MemoryCacheProvider) ListLen(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	l, err := cp.getList(key)
+	if l == nil {
+		return 0, err
+	}
+	return int64(len(l.items)), nil
+}
+
+// listPop 弹出列表的元素，调用方需要持有写锁。
+func (cp *MemoryCacheProvider) listPop(key string, left bool, value any) (bool, error) {
+	l, err := cp.getList(key)
+	if l == nil {
+		return false, err
+	}
+
+	var item any
+	if left {
+		item = l.items[0]
+		l.items[0] = nil
+		l.items = l.items[1:]
+	} else {
+		item = l.items[len(l.items)-1]
+		l.items[len(l.items)-1] = nil
+		l.items = l.items[:len(l.items)-1]
+	}
+
+	// 与 redis 一致，没有元素的列表不存在。
+	if len(l.items) == 0 {
+		cp.cache.Delete(key)
+	}
+
+	if err = cp.assign(key, item, value); err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// getList 获取 key 对应的列表，列表不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getList(key string) (*memoryList, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	l, ok := item.(*memoryList)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return l, nil
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ SetCacheProvider = (*MemoryCacheProvider)(nil)
+
+// memorySet 内存缓存中的集合。
+type memorySet map[string]struct{}
+
+// implement SetCacheProvider.SetAdd .
+func (cp *MemoryCacheProvider) SetAdd(key string, members []string, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	s, err := cp.getSet(key)
+	if err != nil || len(members) == 0 {
+		return 0, err
+	}
+
+	if s == nil {
+		s = make(memorySet, len(members))
+		cp.cache.Set(key, s, cp.legalExpireTime(t))
+	}
+
+	var n int64
+	for _, m := range members {
+		if _, exists := s[m]; !exists {
+			s[m] = struct{}{}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// implement SetCacheProvider.SetRemove .
+func (cp *MemoryCacheProvider) SetRemove(key string, members ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	s, err := cp.getSet(key)
+	if s == nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, m := range members {
+		if _, exists := s[m]; exists {
+			delete(s, m)
+			n++
+		}
+	}
+
+	// 与 redis 一致，没有成员的集合不存在。
+	if len(s) == 0 {
+		cp.cache.Delete(key)
+	}
+	return n, nil
+}
+
+// implement SetCacheProvider.SetContains .
+func (cp *MemoryCacheProvider) SetContains(key, member string) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	s, err := cp.getSet(key)
+	if s == nil {
+		return false, err
+	}
+
+	_, exists := s[member]
+	return exists, nil
+}
+
+// implement SetCacheProvider.SetMembers .
+func (cp *MemoryCacheProvider) SetMembers(key string) ([]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	s, err := cp.getSet(key)
+	if s == nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(s))
+	for m := range s {
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// implement SetCacheProvider.SetCard .
+func (cp *MemoryCacheProvider) SetCard(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	s, err := cp.getSet(key)
+	return int64(len(s)), err
+}
+
+// implement SetCacheProvider.SetInter .
+func (cp *MemoryCacheProvider) SetInter(keys ...string) ([]string, error) {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	sets, err := cp.getSets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for m := range sets[0] {
+		in := true
+		for _, s := range sets[1:] {
+			if _, exists := s[m]; !exists {
+				in = false
+				break
+			}
+		}
+		if in {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// implement SetCacheProvider.SetUnion .
+func (cp *MemoryCacheProvider) SetUnion(keys ...string) ([]string, error) {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	sets, err := cp.getSets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	union := make(map[string]struct{})
+	var res []string
+	for _, s := range sets {
+		for m := range s {
+			if _, exists := union[m]; !exists {
+				union[m] = struct{}{}
+				res = append(res, m)
+			}
+		}
+	}
+	return res, nil
+}
+
+// getSets 获取多个集合，不存在的集合为 nil ，调用方需要持有锁。
+func (cp *MemoryCacheProvider) getSets(keys []string) ([]memorySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keys must not be empty")
+	}
+
+	if err := checkKeys(keys); err != nil {
+		return nil, err
+	}
+
+	sets := make([]memorySet, len(keys))
+	for i, key := range keys {
+		s, err := cp.getSet(key)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = s
+	}
+	return sets, nil
+}
+
+// getSet 获取 key 对应的集合，集合不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getSet(key string) (memorySet, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	s, ok := item.(memorySet)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return s, nil
+}
@@ -0,0 +1,291 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ SortedSetCacheProvider = (*MemoryCacheProvider)(nil)
+
+// memorySortedSet 内存缓存中的有序集合，dict 用于按成员查找分数，zsl 用于排序。
+type memorySortedSet struct {
+	dict map[string]float64
+	zsl  *skipList
+}
+
+// add 添加成员或者更新成员的分数。
+// return: 新添加的成员返回 true 。
+func (zs *memorySortedSet) add(member string, score float64) bool {
+	old, exists := zs.dict[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		zs.zsl.delete(old, member)
+	}
+
+	zs.dict[member] = score
+	zs.zsl.insert(score, member)
+	return !exists
+}
+
+// implement SortedSetCacheProvider.SortedSetAdd .
+func (cp *MemoryCacheProvider) SortedSetAdd(key string, members []SortedSetMember, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if len(members) == 0 {
+		_, err := cp.getSortedSet(key)
+		return 0, err
+	}
+
+	zs, err := cp.getOrCreateSortedSet(key, t)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, m := range members {
+		if zs.add(m.Member, m.Score) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetIncrease .
+func (cp *MemoryCacheProvider) SortedSetIncrease(key, member string, increment float64, t time.Duration) (float64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	zs, err := cp.getOrCreateSortedSet(key, t)
+	if err != nil {
+		return 0, err
+	}
+
+	score := zs.dict[member] + increment
+	zs.add(member, score)
+	return score, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetScore .
+func (cp *MemoryCacheProvider) SortedSetScore(key, member string) (float64, bool, error) {
+	if key == "" {
+		return 0, false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return 0, false, err
+	}
+
+	score, exists := zs.dict[member]
+	return score, exists, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRank .
+func (cp *MemoryCacheProvider) SortedSetRank(key, member string, reverse bool) (int64, bool, error) {
+	if key == "" {
+		return 0, false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return 0, false, err
+	}
+
+	score, exists := zs.dict[member]
+	if !exists {
+		return 0, false, nil
+	}
+
+	rank := zs.zsl.rank(score, member)
+	if reverse {
+		return zs.zsl.length - rank, true, nil
+	}
+	return rank - 1, true, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRangeByRank .
+func (cp *MemoryCacheProvider) SortedSetRangeByRank(key string, start, stop int64, reverse bool) ([]SortedSetMember, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return nil, err
+	}
+
+	start, stop, ok := listRangeIndex(start, stop, zs.zsl.length)
+	if !ok {
+		return nil, nil
+	}
+
+	res := make([]SortedSetMember, 0, stop-start+1)
+	if reverse {
+		for x := zs.zsl.byRank(zs.zsl.length - start); x != nil && int64(len(res)) <= stop-start; x = x.backward {
+			res = append(res, SortedSetMember{x.member, x.score})
+		}
+	} else {
+		for x := zs.zsl.byRank(start + 1); x != nil && int64(len(res)) <= stop-start; x = x.level[0].forward {
+			res = append(res, SortedSetMember{x.member, x.score})
+		}
+	}
+	return res, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRangeByScore .
+func (cp *MemoryCacheProvider) SortedSetRangeByScore(key string, min, max float64, offset, count int64, reverse bool) ([]SortedSetMember, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil || count == 0 {
+		return nil, err
+	}
+
+	var res []SortedSetMember
+	if reverse {
+		for x := zs.zsl.lastInRange(min, max); x != nil && x.score >= min; x = x.backward {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if count >= 0 && int64(len(res)) >= count {
+				break
+			}
+			res = append(res, SortedSetMember{x.member, x.score})
+		}
+	} else {
+		for x := zs.zsl.firstInRange(min, max); x != nil && x.score <= max; x = x.level[0].forward {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if count >= 0 && int64(len(res)) >= count {
+				break
+			}
+			res = append(res, SortedSetMember{x.member, x.score})
+		}
+	}
+	return res, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRemove .
+func (cp *MemoryCacheProvider) SortedSetRemove(key string, members ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, member := range members {
+		if score, exists := zs.dict[member]; exists {
+			zs.zsl.delete(score, member)
+			delete(zs.dict, member)
+			n++
+		}
+	}
+
+	// 与 redis 一致，没有成员的有序集合不存在。
+	if len(zs.dict) == 0 {
+		cp.cache.Delete(key)
+	}
+	return n, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRemoveByScore .
+func (cp *MemoryCacheProvider) SortedSetRemoveByScore(key string, min, max float64) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return 0, err
+	}
+
+	removed := zs.zsl.deleteRangeByScore(min, max)
+	for _, member := range removed {
+		delete(zs.dict, member)
+	}
+
+	if len(zs.dict) == 0 {
+		cp.cache.Delete(key)
+	}
+	return int64(len(removed)), nil
+}
+
+// implement SortedSetCacheProvider.SortedSetCard .
+func (cp *MemoryCacheProvider) SortedSetCard(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	zs, err := cp.getSortedSet(key)
+	if zs == nil {
+		return 0, err
+	}
+	return zs.zsl.length, nil
+}
+
+// getSortedSet 获取 key 对应的有序集合，有序集合不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getSortedSet(key string) (*memorySortedSet, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	zs, ok := item.(*memorySortedSet)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return zs, nil
+}
+
+// getOrCreateSortedSet 获取 key 对应的有序集合，有序集合不存在时创建，并设置过期时长 t 。
+func (cp *MemoryCacheProvider) getOrCreateSortedSet(key string, t time.Duration) (*memorySortedSet, error) {
+	zs, err := cp.getSortedSet(key)
+	if err != nil || zs != nil {
+		return zs, err
+	}
+
+	zs = &memorySortedSet{make(map[string]float64), newSkipList()}
+	cp.cache.Set(key, zs, cp.legalExpireTime(t))
+	return zs, nil
+}
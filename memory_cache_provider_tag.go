@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ TagCacheProvider = (*MemoryCacheProvider)(nil)
+
+// implement TagCacheProvider.AddTags .
+// 标签的集合与 SetOperation 使用相同的 memorySet ，保存在同一个缓存中，随缓存一起过期。
+func (cp *MemoryCacheProvider) AddTags(cacheNamespace, key string, tags []string, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	for _, tag := range tags {
+		tk, err := tagKey(cacheNamespace, tag)
+		if err != nil {
+			return err
+		}
+
+		item, expireTime, exists := cp.cache.GetWithExpiration(tk)
+		if !exists {
+			cp.cache.Set(tk, memorySet{key: {}}, cp.legalExpireTime(t))
+			continue
+		}
+
+		s, ok := item.(memorySet)
+		if !ok {
+			return errWrongType(tk)
+		}
+		cp.pruneTag(s)
+		s[key] = struct{}{}
+
+		// 延长集合的过期时间，使其不早于 key 过期。
+		switch {
+		case expireTime.IsZero():
+		case t == NoExpiration:
+			cp.cache.Set(tk, s, cp.legalExpireTime(NoExpiration))
+		case time.Until(expireTime) < t:
+			cp.cache.Set(tk, s, cp.legalExpireTime(t))
+		}
+	}
+	return nil
+}
+
+// implement TagCacheProvider.InvalidateTag .
+func (cp *MemoryCacheProvider) InvalidateTag(cacheNamespace, tag string) (int64, error) {
+	tk, err := tagKey(cacheNamespace, tag)
+	if err != nil {
+		return 0, err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	s, err := cp.getSet(tk)
+	if s == nil {
+		return 0, err
+	}
+
+	var n int64
+	for key := range s {
+		if _, exists := cp.cache.Get(key); exists {
+			n++
+		}
+		cp.cache.Delete(key)
+	}
+	cp.cache.Delete(tk)
+	return n, nil
+}
+
+// pruneTag 抽查标签的集合中的 tagPruneCount 个 key ，移除已经过期的，调用方需要持有锁。
+func (cp *MemoryCacheProvider) pruneTag(s memorySet) {
+	n := 0
+	for key := range s { // map 的遍历顺序是随机的。
+		if n++; n > tagPruneCount {
+			break
+		}
+		if _, exists := cp.cache.Get(key); !exists {
+			delete(s, key)
+		}
+	}
+}
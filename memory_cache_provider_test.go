@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -425,3 +426,261 @@ func TestMemoryCacheProvider_IncreaseOrCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryCacheProvider_TryGetConvert(t *testing.T) {
+	type PersonDTO struct {
+		Name string
+		Age  int
+	}
+
+	p := NewMemoryCacheProvider(time.Second)
+	p.Set("convert_struct", data, NoExpiration)
+	p.Set("convert_person", data.Person, NoExpiration)
+	p.Set("convert_person_ptr", &data.Person, NoExpiration)
+
+	t.Run("default", func(t *testing.T) {
+		var dto PersonDTO
+		if ok, err := p.TryGet("convert_person", &dto); !ok || err != nil {
+			t.Fatalf("TryGet() = %v, %v", ok, err)
+		}
+		if dto.Name != data.Person.Name || dto.Age != data.Person.Age {
+			t.Errorf("TryGet() = %v, want %v", dto, data.Person)
+		}
+
+		var person Person
+		if ok, err := p.TryGet("convert_person_ptr", &person); !ok || err != nil {
+			t.Fatalf("TryGet() = %v, %v", ok, err)
+		}
+		if person != data.Person {
+			t.Errorf("TryGet() = %v, want %v", person, data.Person)
+		}
+
+		var personPtr *Person
+		if ok, err := p.TryGet("convert_person", &personPtr); !ok || err != nil {
+			t.Fatalf("TryGet() = %v, %v", ok, err)
+		}
+		if *personPtr != data.Person {
+			t.Errorf("TryGet() = %v, want %v", *personPtr, data.Person)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		m := map[string]any{}
+		_, err := p.TryGet("convert_struct", &m)
+
+		var mismatch *TypeMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("TryGet() error = %v, want *TypeMismatchError", err)
+		}
+		if mismatch.Key != "convert_struct" || mismatch.Source != reflect.TypeOf(data) || mismatch.Target != reflect.TypeOf(m) {
+			t.Errorf("TryGet() error = %v", mismatch)
+		}
+		if len(m) != 0 {
+			t.Errorf("when type mismatch, value cannot be modified")
+		}
+
+		var dto PersonDTO
+		if _, err := p.WithConvertMode(ConvertNone).TryGet("convert_person", &dto); !errors.As(err, &mismatch) {
+			t.Fatalf("TryGet() error = %v, want *TypeMismatchError", err)
+		}
+
+		if _, err := p.TryGet("convert_person", dto); err == nil {
+			t.Fatalf("TryGet() with non-pointer value should fail")
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		m := map[string]any{}
+		jp := p.WithConvertMode(ConvertDefault | ConvertJSON)
+		if ok, err := jp.TryGet("convert_struct", &m); !ok || err != nil {
+			t.Fatalf("TryGet() = %v, %v", ok, err)
+		}
+		if m["String"] != data.String || m["Person"].(map[string]any)["Name"] != data.Person.Name {
+			t.Errorf("TryGet() = %v", m)
+		}
+	})
+}
+
+func TestMemoryCacheProvider_WithCodec(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	cp := p.WithCodec(JSONCodec)
+
+	person := data.Person
+	if err := cp.Set("codec_person", &person, NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+
+	// 编码模式下，缓存值与调用方的对象不共享内存。
+	person.Age = 100
+
+	var got map[string]any
+	if ok, err := cp.TryGet("codec_person", &got); !ok || err != nil {
+		t.Fatalf("TryGet() = %v, %v", ok, err)
+	}
+	if got["Name"] != data.Person.Name || got["Age"] != float64(data.Person.Age) {
+		t.Errorf("TryGet() = %v", got)
+	}
+
+	cp.Set("codec_counter", 10, NoExpiration)
+	if v, err := cp.Increase("codec_counter"); err != nil || v != 11 {
+		t.Fatalf("Increase() = %v, %v", v, err)
+	}
+	var counter int
+	if ok, err := cp.TryGet("codec_counter", &counter); !ok || err != nil || counter != 11 {
+		t.Fatalf("TryGet() = %v, %v, %v", counter, ok, err)
+	}
+
+	cp.Set("codec_string", "s", NoExpiration)
+	if _, err := cp.Increase("codec_string"); err == nil {
+		t.Fatalf("Increase() not integer should fail")
+	}
+}
+
+// TestMemoryCacheProvider_IncreaseOrCreate_TTL 只有新创建的 key 才设置过期时间。
+func TestMemoryCacheProvider_IncreaseOrCreate_TTL(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+
+	t.Run("back_to_increment", func(t *testing.T) {
+		p.IncreaseOrCreate("ttl_back", 5, time.Minute)
+		p.IncreaseOrCreate("ttl_back", -5, time.Hour)
+		if v, err := p.IncreaseOrCreate("ttl_back", 5, time.Hour); err != nil || v != 5 {
+			t.Fatalf("IncreaseOrCreate() = %v, %v", v, err)
+		}
+
+		_, expireTime, _ := p.cache.GetWithExpiration("ttl_back")
+		if ttl := time.Until(expireTime); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("IncreaseOrCreate() ttl = %v, want <= 1m", ttl)
+		}
+	})
+
+	t.Run("exists_without_ttl", func(t *testing.T) {
+		p.Set("ttl_exists", int8(0), NoExpiration)
+		if v, err := p.IncreaseOrCreate("ttl_exists", 3, time.Minute); err != nil || v != 3 {
+			t.Fatalf("IncreaseOrCreate() = %v, %v", v, err)
+		}
+
+		_, expireTime, _ := p.cache.GetWithExpiration("ttl_exists")
+		if !expireTime.IsZero() {
+			t.Errorf("IncreaseOrCreate() expire at %v, want no expiration", expireTime)
+		}
+	})
+
+	t.Run("no_expiration", func(t *testing.T) {
+		// 之前 0 会被当作 go-cache 的默认过期时间（即清理周期）。
+		p.IncreaseOrCreate("ttl_no_expiration", 1, NoExpiration)
+
+		_, expireTime, _ := p.cache.GetWithExpiration("ttl_no_expiration")
+		if !expireTime.IsZero() {
+			t.Errorf("IncreaseOrCreate() expire at %v, want no expiration", expireTime)
+		}
+	})
+}
+
+func TestMemoryCacheProvider_Multi(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	keys := []string{"multi_a", "multi_not_exists", "multi_b"}
+	defer p.RemoveMulti(keys...)
+
+	p.Set("multi_a", 1, time.Minute)
+	p.Set("multi_b", data.Person, time.Minute)
+
+	var a int
+	var missing string
+	var b Person
+	got, err := p.TryGetMulti(keys, []any{&a, &missing, &b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("TryGetMulti() = %v", got)
+	}
+	if a != 1 || missing != "" || b != data.Person {
+		t.Errorf("TryGetMulti() values = %v, %v, %v", a, missing, b)
+	}
+
+	if _, err = p.TryGetMulti(keys, []any{&a}); err == nil {
+		t.Errorf("TryGetMulti() len(keys) != len(values) should fail")
+	}
+
+	if n, err := p.RemoveMulti(keys...); err != nil || n != 2 {
+		t.Errorf("RemoveMulti() = %v, %v", n, err)
+	}
+	if n, err := p.RemoveMulti(); err != nil || n != 0 {
+		t.Errorf("RemoveMulti() = %v, %v", n, err)
+	}
+	if _, err := p.RemoveMulti(""); err == nil {
+		t.Errorf("RemoveMulti() empty key should fail")
+	}
+}
+
+func TestMemoryCacheProvider_Update(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	key := "update_counter"
+	defer p.Remove(key)
+
+	increase := func(value any, found bool) (any, time.Duration, error) {
+		if !found {
+			return 1, time.Minute, nil
+		}
+		return value.(int) + 1, time.Minute, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := p.Update(key, increase); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var v int
+	if ok, err := p.TryGet(key, &v); !ok || err != nil || v != 2 {
+		t.Errorf("TryGet() after Update() = %v, %v, %v", v, ok, err)
+	}
+
+	// 返回 error 时缓存不做改变。
+	err := p.Update(key, func(value any, found bool) (any, time.Duration, error) {
+		return 100, time.Minute, errors.New("rejected")
+	})
+	if err == nil {
+		t.Errorf("Update() should return the error of fn")
+	}
+	if p.Get(key, &v); v != 2 {
+		t.Errorf("Get() after failed Update() = %v, want 2", v)
+	}
+
+	if err := p.Update("", increase); err == nil {
+		t.Errorf("Update() empty key should fail")
+	}
+}
+
+func TestMemoryCacheProvider_Compare(t *testing.T) {
+	for name, p := range map[string]CompareCacheProvider{
+		"raw":   NewMemoryCacheProvider(time.Second),
+		"codec": NewMemoryCacheProvider(time.Second).WithCodec(JSONCodec).(CompareCacheProvider),
+	} {
+		t.Run(name, func(t *testing.T) {
+			key := "compare_token"
+			defer p.Remove(key)
+
+			p.Set(key, data.Person, 50*time.Millisecond)
+			other := data.Person
+			other.Name = "other"
+			if ok, err := p.CompareAndExpire(key, other, time.Minute); ok || err != nil {
+				t.Errorf("CompareAndExpire() mismatch = %v, %v", ok, err)
+			}
+			if ok, err := p.CompareAndExpire(key, data.Person, time.Minute); !ok || err != nil {
+				t.Errorf("CompareAndExpire() = %v, %v", ok, err)
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			if ok, err := p.CompareAndRemove(key, other); ok || err != nil {
+				t.Errorf("CompareAndRemove() mismatch = %v, %v", ok, err)
+			}
+			if ok, err := p.CompareAndRemove(key, data.Person); !ok || err != nil {
+				t.Errorf("CompareAndRemove() after expire extended = %v, %v", ok, err)
+			}
+			if ok, err := p.CompareAndRemove(key, data.Person); ok || err != nil {
+				t.Errorf("CompareAndRemove() on missing key = %v, %v", ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ UniqueCounterCacheProvider = (*MemoryCacheProvider)(nil)
+
+// implement UniqueCounterCacheProvider.UniqueCounterAdd .
+func (cp *MemoryCacheProvider) UniqueCounterAdd(key string, members []string, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	h, err := cp.getHyperLogLog(key)
+	if err != nil {
+		return false, err
+	}
+
+	// 与 redis 一致，没有成员时也创建计数器。
+	changed := false
+	if h == nil {
+		h = &hyperLogLog{}
+		cp.cache.Set(key, h, cp.legalExpireTime(t))
+		changed = true
+	}
+
+	for _, m := range members {
+		if h.add([]byte(m)) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterCount .
+func (cp *MemoryCacheProvider) UniqueCounterCount(keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, fmt.Errorf("keys must not be empty")
+	}
+
+	if err := checkKeys(keys); err != nil {
+		return 0, err
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	if len(keys) == 1 {
+		h, err := cp.getHyperLogLog(keys[0])
+		if h == nil {
+			return 0, err
+		}
+		return h.count(), nil
+	}
+
+	union := &hyperLogLog{}
+	for _, key := range keys {
+		h, err := cp.getHyperLogLog(key)
+		if err != nil {
+			return 0, err
+		}
+		if h != nil {
+			union.merge(h)
+		}
+	}
+	return union.count(), nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterMerge .
+func (cp *MemoryCacheProvider) UniqueCounterMerge(dest string, sources []string, t time.Duration) error {
+	if err := checkKeys(append([]string{dest}, sources...)); err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	h, err := cp.getHyperLogLog(dest)
+	if err != nil {
+		return err
+	}
+
+	// 先合并到临时变量，出错时 dest 不做改变。
+	merged := &hyperLogLog{}
+	if h != nil {
+		merged.merge(h)
+	}
+	for _, key := range sources {
+		s, err := cp.getHyperLogLog(key)
+		if err != nil {
+			return err
+		}
+		if s != nil {
+			merged.merge(s)
+		}
+	}
+
+	if h == nil {
+		cp.cache.Set(dest, merged, cp.legalExpireTime(t))
+	} else {
+		*h = *merged
+	}
+	return nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterExport .
+func (cp *MemoryCacheProvider) UniqueCounterExport(key string) ([]byte, bool, error) {
+	if key == "" {
+		return nil, false, fmt.Errorf("key must not be empty")
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	h, err := cp.getHyperLogLog(key)
+	if h == nil {
+		return nil, false, err
+	}
+	return h.marshal(), true, nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterImport .
+func (cp *MemoryCacheProvider) UniqueCounterImport(key string, data []byte, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	h, err := unmarshalHyperLogLog(data)
+	if err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.cache.Set(key, h, cp.legalExpireTime(t))
+	return nil
+}
+
+// getHyperLogLog 获取 key 对应的计数器，计数器不存在时返回 nil 。
+func (cp *MemoryCacheProvider) getHyperLogLog(key string) (*hyperLogLog, error) {
+	item, exists := cp.cache.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	h, ok := item.(*hyperLogLog)
+	if !ok {
+		return nil, errWrongType(key)
+	}
+	return h, nil
+}
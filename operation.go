@@ -26,14 +26,49 @@ type Operation struct {
 	// [:unique flag] 部分的拼接元素的个数。
 	// 受支持的 [:unique flag] 类型: bool, int*, uint*, float*, string, time.time, UnixTime 。
 	uniqueFlagLen int
+
+	// 包含在哈希标签 {...} 中的 unique flag 的个数，-1 表示不使用哈希标签。
+	hashTagLen int
+
+	// 缓存key中的代数，nil 表示不使用代数，见 WithGeneration 。
+	gen *generation
+}
+
+// OperationOption 是创建缓存操作对象时的可选配置。
+type OperationOption func(*Operation)
+
+// WithCodec 指定缓存操作对象使用的编解码器，覆盖缓存提供器自身的编解码器，
+// 缓存提供器必须实现 CodecCacheProvider 。
+func WithCodec(codec Codec) OperationOption {
+	return func(c *Operation) {
+		p, ok := c.cacheProvider.(CodecCacheProvider)
+		if !ok {
+			panic(fmt.Errorf("cache provider %T does not support codec", c.cacheProvider))
+		}
+		c.cacheProvider = p.WithCodec(codec)
+	}
+}
+
+// WithHashTag 使用 Redis 的哈希标签 {...} 包裹 <CacheNamespace>:<Prefix> 以及前 n 个 unique flag ，
+// 使得这部分相同的 key 位于 Redis Cluster 的同一个哈希槽（Ring 的同一个节点）， 从而可以在一个命令中操作多个 key 。
+// 例如 n=1 时，key 为 {ns:prefix_a}_1 。
+//  @n: 包含在哈希标签中的 unique flag 的个数，0 <= n <= uniqueFlagLen 。
+func WithHashTag(n int) OperationOption {
+	return func(c *Operation) {
+		if n < 0 || n > c.uniqueFlagLen {
+			panic(fmt.Errorf("hash tag flag count(%d) must be in [0, uniqueFlagLen(%d)]", n, c.uniqueFlagLen))
+		}
+		c.hashTagLen = n
+	}
 }
 
 // NewOperation 创建一个缓存操作对象。
 // 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]。
 // expireTime: 过期时长， nil 或者 CacheExpirationZero 表不过期。
 // uniqueFlagLen: 指定用来拼接 [:unique flag] 部分的元素个数(>=0)。
+// opts: 可选配置，如 WithCodec, WithHashTag, WithGeneration 。
 // 受支持的 [:unique flag] 类型: bool, int*, uint*, float*, string, time.time, UnixTime 。
-func NewOperation(cacheNamespace, keyPrefix string, uniqueFlagLen int, cacheProvider CacheProvider, expireTime *Expiration) *Operation {
+func NewOperation(cacheNamespace, keyPrefix string, uniqueFlagLen int, cacheProvider CacheProvider, expireTime *Expiration, opts ...OperationOption) *Operation {
 	if cacheNamespace == "" || keyPrefix == "" {
 		panic(fmt.Errorf(`neither 'cacheNamespace' nor 'keyPrefix' can be zero value`))
 	}
@@ -58,6 +93,11 @@ func NewOperation(cacheNamespace, keyPrefix string, uniqueFlagLen int, cacheProv
 	}
 
 	cp.uniqueFlagLen = uniqueFlagLen
+	cp.hashTagLen = -1
+
+	for _, opt := range opts {
+		opt(cp)
+	}
 
 	return cp
 }
@@ -69,26 +109,54 @@ func (c *Operation) Key(keys ...interface{}) *KeyOperation {
 		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.uniqueFlagLen))
 	}
 
+	key, err := c.buildCacheKey(keys...)
 	return &KeyOperation{
 		p:   c.cacheProvider,
 		exp: c.expireTime,
-		Key: c.buildCacheKey(keys...),
+		ns:  c.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
-// buildCacheKey 构建缓存key。
-func (c *Operation) buildCacheKey(keys ...interface{}) string {
-	if len(keys) == 0 {
-		return c.keyBase // key：没有 [:unique flag]。
+// CacheProvider 获取缓存操作对象使用的缓存提供器，已经应用了 WithCodec 指定的编解码器。
+// 用于需要自行指定过期时长等 KeyOperation 不支持的操作。
+func (c *Operation) CacheProvider() CacheProvider {
+	return c.cacheProvider
+}
+
+// buildCacheKey 构建缓存key，只在使用 WithGeneration 且读取代数失败时返回错误。
+func (c *Operation) buildCacheKey(keys ...interface{}) (string, error) {
+	keyBase, err := c.currentKeyBase()
+	if err != nil {
+		return "", err
+	}
+	return c.joinCacheKey(keyBase, keys...), nil
+}
+
+// joinCacheKey 拼接 currentKeyBase 获取的 keyBase 和 [:unique flag] 。
+func (c *Operation) joinCacheKey(keyBase string, keys ...interface{}) string {
+	if len(keys) == 0 && c.hashTagLen < 0 {
+		return keyBase // key：没有 [:unique flag]。
 	}
 	sb := strings.Builder{}
-	sb.WriteString(c.keyBase)
+	if c.hashTagLen >= 0 {
+		sb.WriteString("{")
+	}
+	sb.WriteString(keyBase)
 
-	for _, v := range keys {
+	for i, v := range keys {
+		if i == c.hashTagLen {
+			sb.WriteString("}")
+		}
 		sb.WriteString("_")
 		sb.WriteString(oneKeyToStr(v))
 	}
 
+	if c.hashTagLen == len(keys) {
+		sb.WriteString("}")
+	}
+
 	return sb.String()
 }
 
@@ -111,21 +179,30 @@ func NewOperation0[TRes any](
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation0[TRes] {
 	return &Operation0[TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 0, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 0, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation0[TRes]) Key() *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey()
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation0[TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation1 表示 key 只由0个元素组成的缓存操作对象。
 type Operation1[TKey UniqueFlag, TRes any] struct {
 	op Operation
@@ -136,21 +213,30 @@ func NewOperation1[TKey UniqueFlag, TRes any](
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation1[TKey, TRes] {
 	return &Operation1[TKey, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 1, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 1, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation1[TKey, TRes]) Key(v TKey) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation1[TKey, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation2 表示 key 只由2个元素组成的缓存操作对象。
 type Operation2[TKey1 UniqueFlag, TKey2 UniqueFlag, TRes any] struct {
 	op Operation
@@ -161,21 +247,30 @@ func NewOperation2[TKey1 UniqueFlag, TKey2 UniqueFlag, TRes any](
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation2[TKey1, TKey2, TRes] {
 	return &Operation2[TKey1, TKey2, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 2, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 2, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation2[TKey1, TKey2, TRes]) Key(v1 TKey1, v2 TKey2) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation2[TKey1, TKey2, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation3 表示 key 只由3个元素组成的缓存操作对象。
 type Operation3[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TRes any] struct {
 	op Operation
@@ -186,21 +281,30 @@ func NewOperation3[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TRes an
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation3[TKey1, TKey2, TKey3, TRes] {
 	return &Operation3[TKey1, TKey2, TKey3, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 3, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 3, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation3[TKey1, TKey2, TKey3, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation3[TKey1, TKey2, TKey3, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation4 表示 key 只由4个元素组成的缓存操作对象。
 type Operation4[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 UniqueFlag, TRes any] struct {
 	op Operation
@@ -211,21 +315,30 @@ func NewOperation4[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 U
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation4[TKey1, TKey2, TKey3, TKey4, TRes] {
 	return &Operation4[TKey1, TKey2, TKey3, TKey4, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 4, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 4, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation4[TKey1, TKey2, TKey3, TKey4, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3, v4 TKey4) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3, v4)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3, v4),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation4[TKey1, TKey2, TKey3, TKey4, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation5 表示 key 只由5个元素组成的缓存操作对象。
 type Operation5[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 UniqueFlag, TKey5 UniqueFlag, TRes any] struct {
 	op Operation
@@ -236,21 +349,30 @@ func NewOperation5[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 U
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation5[TKey1, TKey2, TKey3, TKey4, TKey5, TRes] {
 	return &Operation5[TKey1, TKey2, TKey3, TKey4, TKey5, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 5, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 5, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation5[TKey1, TKey2, TKey3, TKey4, TKey5, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3, v4 TKey4, v5 TKey5) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3, v4, v5)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3, v4, v5),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation5[TKey1, TKey2, TKey3, TKey4, TKey5, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation6 表示 key 只由6个元素组成的缓存操作对象。
 type Operation6[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 UniqueFlag, TKey5 UniqueFlag, TKey6 UniqueFlag, TRes any] struct {
 	op Operation
@@ -261,21 +383,30 @@ func NewOperation6[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 U
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation6[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TRes] {
 	return &Operation6[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 6, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 6, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation6[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3, v4 TKey4, v5 TKey5, v6 TKey6) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3, v4, v5, v6)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3, v4, v5, v6),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation6[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation7 表示 key 只由7个元素组成的缓存操作对象。
 type Operation7[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 UniqueFlag, TKey5 UniqueFlag, TKey6 UniqueFlag, TKey7 UniqueFlag, TRes any] struct {
 	op Operation
@@ -286,21 +417,30 @@ func NewOperation7[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 U
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation7[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TRes] {
 	return &Operation7[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 7, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 7, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation7[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3, v4 TKey4, v5 TKey5, v6 TKey6, v7 TKey7) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3, v4, v5, v6, v7)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3, v4, v5, v6, v7),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation7[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 // Operation8 表示 key 只由8个元素组成的缓存操作对象。
 type Operation8[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 UniqueFlag, TKey5 UniqueFlag, TKey6 UniqueFlag, TKey7 UniqueFlag, TKey8 UniqueFlag, TRes any] struct {
 	op Operation
@@ -311,21 +451,30 @@ func NewOperation8[TKey1 UniqueFlag, TKey2 UniqueFlag, TKey3 UniqueFlag, TKey4 U
 	cacheNamespace, keyPrefix string,
 	cacheProvider CacheProvider,
 	expireTime *Expiration,
+	opts ...OperationOption,
 ) *Operation8[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TKey8, TRes] {
 	return &Operation8[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TKey8, TRes]{
-		*NewOperation(cacheNamespace, keyPrefix, 8, cacheProvider, expireTime),
+		*NewOperation(cacheNamespace, keyPrefix, 8, cacheProvider, expireTime, opts...),
 	}
 }
 
 // Key 获取指定key的缓存操作对象。
 func (c *Operation8[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TKey8, TRes]) Key(v1 TKey1, v2 TKey2, v3 TKey3, v4 TKey4, v5 TKey5, v6 TKey6, v7 TKey7, v8 TKey8) *KeyOperationT[TRes] {
+	key, err := c.op.buildCacheKey(v1, v2, v3, v4, v5, v6, v7, v8)
 	return &KeyOperationT[TRes]{
 		p:   c.op.cacheProvider,
 		exp: c.op.expireTime,
-		Key: c.op.buildCacheKey(v1, v2, v3, v4, v5, v6, v7, v8),
+		ns:  c.op.cacheNamespace,
+		Key: key,
+		err: err,
 	}
 }
 
+// BumpGeneration 使代数加 1 ，见 Operation.BumpGeneration 。
+func (c *Operation8[TKey1, TKey2, TKey3, TKey4, TKey5, TKey6, TKey7, TKey8, TRes]) BumpGeneration() (int64, error) {
+	return c.op.BumpGeneration()
+}
+
 /*
 	不支持的type
 		Array
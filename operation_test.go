@@ -218,3 +218,78 @@ func TestOperation8(t *testing.T) {
 		}
 	})
 }
+
+func TestOperation_WithCodec(t *testing.T) {
+	provider := NewMemoryCacheProvider(time.Second)
+
+	op := NewOperation1[string, Person]("ns", "codec", provider, CacheExpirationZero, WithCodec(GobCodec))
+	op.Key("a").MustSet(data.Person)
+
+	if v, ok := op.Key("a").MustTryGet(); !ok || v != data.Person {
+		t.Fatalf("MustTryGet() = %v, %v", v, ok)
+	}
+
+	// 底层存储的是 gob 编码后的字节。
+	var b []byte
+	if ok, err := provider.TryGet("ns:codec_a", &b); !ok || err != nil {
+		t.Fatalf("TryGet() = %v, %v", ok, err)
+	}
+
+	var person Person
+	if err := GobCodec.Unmarshal(b, &person); err != nil || person != data.Person {
+		t.Fatalf("Unmarshal() = %v, %v", person, err)
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("should panic")
+			}
+		}()
+		NewOperation("ns", "codec", 0, unsupportedProvider{provider}, nil, WithCodec(GobCodec))
+	})
+}
+
+// unsupportedProvider 仅实现 CacheProvider 。
+type unsupportedProvider struct {
+	CacheProvider
+}
+
+func TestOperation_WithHashTag(t *testing.T) {
+	provider := NewMemoryCacheProvider(time.Second)
+
+	tests := []struct {
+		name      string
+		flagLen   int
+		tagLen    int
+		keys      []any
+		want      string
+		wantPanic bool
+	}{
+		{"no_flag", 0, 0, nil, "{ns:prefix}", false},
+		{"base", 2, 0, []any{"a", 1}, "{ns:prefix}_a_1", false},
+		{"one", 2, 1, []any{"a", 1}, "{ns:prefix_a}_1", false},
+		{"all", 2, 2, []any{"a", 1}, "{ns:prefix_a_1}", false},
+		{"too_many", 2, 3, nil, "", true},
+		{"negative", 2, -1, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if (recover() != nil) != tt.wantPanic {
+					t.Errorf("WithHashTag() wantPanic %v", tt.wantPanic)
+				}
+			}()
+
+			op := NewOperation("ns", "prefix", tt.flagLen, provider, nil, WithHashTag(tt.tagLen))
+			if got := op.Key(tt.keys...).Key; got != tt.want {
+				t.Errorf("Key() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	op := NewOperation2[string, int, string]("ns", "prefix", provider, nil, WithHashTag(1))
+	if hashSlot(op.Key("a", 1).Key) != hashSlot(op.Key("a", 2).Key) {
+		t.Errorf("keys with the same hash tag should be in the same slot")
+	}
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// fixedWindowScript 固定窗口计数，超过限流时不计入。
+//  ARGV[1]: 请求数。
+//  ARGV[2]: 限流的请求数。
+//  ARGV[3]: 窗口剩余的时长（毫秒），新窗口的过期时间。
+//  return: {是否允许, 窗口内的计数}
+var fixedWindowScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+local n = tonumber(ARGV[1])
+if count + n > tonumber(ARGV[2]) then
+	return {0, count}
+end
+count = redis.call('INCRBY', KEYS[1], n)
+if count == n then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, count}
+`)
+
+// FixedWindow 固定窗口限流器，每个窗口内最多允许 limit 个请求。
+// 实现简单、状态最少，但在窗口的边界前后可能短时间内允许接近 2 倍的请求。
+// 缓存key为 <CacheNamespace>:<Prefix>_<key>_<窗口序号>，每个窗口一个计数器，窗口结束时过期。
+type FixedWindow struct {
+	baseLimiter
+}
+
+var _ Limiter = (*FixedWindow)(nil)
+
+// NewFixedWindow 创建一个固定窗口限流器。
+//  @limit: 每个窗口允许的请求数。
+//  @window: 窗口的时长，窗口按 Unix 时间对齐。
+//  @cacheProvider: 支持任意的 CacheProvider ，
+//  RedisCacheProvider 和 MemoryCacheProvider 以外的缓存提供器使用 IncreaseOrCreate 计数，超过限流时回退计数，回退不是原子的。
+func NewFixedWindow(
+	cacheNamespace, keyPrefix string,
+	limit int64,
+	window time.Duration,
+	cacheProvider cache.CacheProvider,
+	opts ...cache.OperationOption,
+) *FixedWindow {
+	return &FixedWindow{newBaseLimiter(cacheNamespace, keyPrefix, 2, limit, window, cacheProvider, opts)}
+}
+
+// implement Limiter.Allow .
+func (l *FixedWindow) Allow(key any) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// implement Limiter.AllowN .
+func (l *FixedWindow) AllowN(key any, n int64) (Result, error) {
+	if err := l.checkN(n); err != nil {
+		return Result{}, err
+	}
+
+	now, window := l.now().UnixMilli(), millis(l.window)
+	index := now / window
+	reset := (index+1)*window - now
+	keyOp := l.op.Key(key, index)
+	if err := keyOp.Err(); err != nil {
+		return Result{}, err
+	}
+	cacheKey := keyOp.Key
+
+	allowed, count, err := l.increase(cacheKey, n, reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Allowed: allowed, Limit: l.limit, Remaining: remaining(l.limit, float64(count))}
+	if !allowed {
+		res.RetryAfter = time.Duration(reset) * time.Millisecond
+	}
+	return res, nil
+}
+
+// increase 计数，超过限流时不计入。
+//  @reset: 窗口剩余的时长（毫秒）。
+//  return: 是否允许，以及窗口内的计数。
+func (l *FixedWindow) increase(cacheKey string, n, reset int64) (bool, int64, error) {
+	switch p := l.p.(type) {
+	case *cache.RedisCacheProvider:
+		vals, err := int64Slice(fixedWindowScript.Run(context.Background(), p.Client(), []string{cacheKey}, n, l.limit, reset))
+		if err != nil {
+			return false, 0, err
+		}
+		return vals[0] == 1, vals[1], nil
+
+	case *cache.MemoryCacheProvider:
+		var allowed bool
+		var count int64
+		err := p.Update(cacheKey, func(value any, found bool) (any, time.Duration, error) {
+			if found {
+				c, ok := value.(int64)
+				if !ok {
+					return nil, 0, fmt.Errorf("unexpected value type %T of key '%s'", value, cacheKey)
+				}
+				count = c
+			}
+
+			if count+n <= l.limit {
+				count += n
+				allowed = true
+			}
+			return count, time.Duration(reset) * time.Millisecond, nil
+		})
+		return allowed, count, err
+
+	default:
+		t := time.Duration(reset) * time.Millisecond
+		count, err := l.p.IncreaseOrCreate(cacheKey, n, t)
+		if err != nil {
+			return false, 0, err
+		}
+
+		if count <= l.limit {
+			return true, count, nil
+		}
+
+		// 回退超出限流的计数，使其不消耗额度。
+		count, err = l.p.IncreaseOrCreate(cacheKey, -n, t)
+		return false, count, err
+	}
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Middleware 返回 net/http 中间件，请求超过限流时返回 429 Too Many Requests 。
+// 响应头包含 X-RateLimit-Limit 和 X-RateLimit-Remaining ，超过限流时还包含 Retry-After （秒，向上取整）。
+// 限流器出错时返回 500 Internal Server Error 。
+//  @keyFunc: 从请求中获取限流的 key ，例如客户端 IP 、用户 ID ；返回空字符串时不限流。
+func Middleware(l Limiter, keyFunc func(r *http.Request) string) func(http.Handler) http.Handler {
+	if l == nil || keyFunc == nil {
+		panic(fmt.Errorf("param 'l' and 'keyFunc' must not be nil"))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := l.Allow(key)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			h := w.Header()
+			h.Set("X-RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+			h.Set("X-RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	clock := newFakeClock()
+	l := NewFixedWindow("ns", cachetest.Prefix("rl"), 2, time.Minute, cache.NewMemoryCacheProvider(time.Second))
+	l.now = clock.now
+
+	handler := Middleware(l, func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(user string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := serve("u1")
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Remaining") != remaining {
+			t.Errorf("request %d: code = %v, remaining = %v", i, w.Code, w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+
+	clock.add(100 * time.Millisecond)
+	w := serve("u1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" || w.Header().Get("X-RateLimit-Limit") != "2" {
+		t.Errorf("exceeded: code = %v, headers = %v", w.Code, w.Header())
+	}
+
+	// 空 key 不限流。
+	for i := 0; i < 3; i++ {
+		if w := serve(""); w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Errorf("empty key: code = %v, headers = %v", w.Code, w.Header())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package ratelimit 提供基于 cache.CacheProvider 的分布式限流器。
+//
+// 限流的状态保存在缓存中：RedisCacheProvider 使用 Lua 脚本在服务端原子地执行限流算法，
+// MemoryCacheProvider 在锁内执行同样的算法。限流器使用调用方的本地时间，多个节点共用 redis 时，
+// 各节点的时钟应当保持同步。
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/thisXYH/cache"
+)
+
+// Result 限流的结果。
+type Result struct {
+	// Allowed 是否允许本次请求。
+	Allowed bool
+
+	// Limit 限流规则允许的请求数。
+	Limit int64
+
+	// Remaining 本次请求之后，当前还允许的请求数。
+	Remaining int64
+
+	// RetryAfter 不允许本次请求时，至少需要等待的时长；允许时为 0 。
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器。
+type Limiter interface {
+	// Allow 判断是否允许 key 的一个请求，等同于 AllowN(key, 1) 。
+	Allow(key any) (Result, error)
+
+	// AllowN 判断是否允许 key 的 n 个请求，只有全部允许时才计入，不允许时不消耗额度。
+	//  @key: 限流的对象，例如客户端 IP 、用户 ID ，受支持的类型与 unique flag 相同。
+	AllowN(key any, n int64) (Result, error)
+}
+
+// baseLimiter 限流器的公共部分。
+type baseLimiter struct {
+	op     *cache.Operation
+	p      cache.CacheProvider
+	limit  int64
+	window time.Duration
+	now    func() time.Time // 获取当前时间，便于测试。
+}
+
+// newBaseLimiter 校验限流规则，uniqueFlagLen 为缓存 key 的 unique flag 个数。
+func newBaseLimiter(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	limit int64,
+	window time.Duration,
+	cacheProvider cache.CacheProvider,
+	opts []cache.OperationOption,
+) baseLimiter {
+	if limit <= 0 {
+		panic(fmt.Errorf("param 'limit' must be greater than 0"))
+	}
+
+	if window < time.Millisecond {
+		panic(fmt.Errorf("param 'window' must not be less than 1ms"))
+	}
+
+	op := cache.NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, nil, opts...)
+	return baseLimiter{op, cacheProvider, limit, window, time.Now}
+}
+
+// checkN 校验请求数。
+func (l *baseLimiter) checkN(n int64) error {
+	if n <= 0 {
+		return fmt.Errorf("param 'n' must be greater than 0")
+	}
+
+	if n > l.limit {
+		return fmt.Errorf("param 'n'(%d) exceeds the limit(%d)", n, l.limit)
+	}
+	return nil
+}
+
+// mustSupport 检查缓存提供器是否支持服务端原子操作。
+func mustSupport(p cache.CacheProvider) {
+	switch p.(type) {
+	case *cache.RedisCacheProvider, *cache.MemoryCacheProvider:
+	default:
+		panic(fmt.Errorf("cache provider %T is not supported, use RedisCacheProvider or MemoryCacheProvider", p))
+	}
+}
+
+// millis 将时长转换成毫秒，不足 1 毫秒的部分向上取整。
+func millis(d time.Duration) int64 {
+	return int64(math.Ceil(float64(d) / float64(time.Millisecond)))
+}
+
+// remaining 计算剩余的请求数，不小于 0 。
+func remaining(limit int64, used float64) int64 {
+	r := int64(math.Floor(float64(limit) - used))
+	if r < 0 {
+		return 0
+	}
+	return r
+}
+
+// slice 读取脚本返回的数组。
+func slice(cmd *redis.Cmd) ([]any, error) {
+	v, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	vals, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected script result %v", v)
+	}
+	return vals, nil
+}
+
+// int64Slice 读取脚本返回的整数数组。
+func int64Slice(cmd *redis.Cmd) ([]int64, error) {
+	vals, err := slice(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]int64, len(vals))
+	for i, v := range vals {
+		n, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected script result %v", vals)
+		}
+		res[i] = n
+	}
+	return res, nil
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+// fakeClock 可以手动调整的时钟。
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	// 窗口按 Unix 时间对齐，从一个窗口的起点开始。
+	return &fakeClock{time.UnixMilli(1651363200000)}
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+// assertResult 判断限流结果。
+func assertResult(t *testing.T, step string, got Result, allowed bool, remaining int64, retryAfter time.Duration) {
+	t.Helper()
+	if got.Allowed != allowed || got.Remaining != remaining || got.RetryAfter != retryAfter {
+		t.Errorf("%s: got %+v, want allowed=%v remaining=%v retryAfter=%v", step, got, allowed, remaining, retryAfter)
+	}
+}
+
+func TestFixedWindow(t *testing.T) {
+	providers := cachetest.Providers()
+	providers["generic"] = struct{ cache.CacheProvider }{cache.NewMemoryCacheProvider(time.Second)}
+
+	for name, p := range providers {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			l := NewFixedWindow("ns", cachetest.Prefix("rl"), 3, time.Second, p)
+			l.now = clock.now
+
+			assertResult(t, "first", must(l.AllowN("u1", 2)), true, 1, 0)
+			assertResult(t, "exceed", must(l.AllowN("u1", 2)), false, 1, time.Second)
+			assertResult(t, "rest", must(l.Allow("u1")), true, 0, 0)
+			assertResult(t, "other key", must(l.Allow("u2")), true, 2, 0)
+
+			clock.add(400 * time.Millisecond)
+			assertResult(t, "full", must(l.Allow("u1")), false, 0, 600*time.Millisecond)
+
+			clock.add(600 * time.Millisecond)
+			assertResult(t, "next window", must(l.Allow("u1")), true, 2, 0)
+
+			if _, err := l.AllowN("u1", 4); err == nil {
+				t.Errorf("AllowN() exceeding the limit should return error")
+			}
+		})
+	}
+}
+
+func TestSlidingLog(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			l := NewSlidingLog("ns", cachetest.Prefix("rl"), 3, time.Second, p)
+			l.now = clock.now
+
+			assertResult(t, "t0", must(l.Allow("u1")), true, 2, 0)
+			clock.add(300 * time.Millisecond)
+			assertResult(t, "t300", must(l.AllowN("u1", 2)), true, 0, 0)
+			clock.add(200 * time.Millisecond)
+			assertResult(t, "t500", must(l.Allow("u1")), false, 0, 500*time.Millisecond)
+			assertResult(t, "t500 n=2", must(l.AllowN("u1", 2)), false, 0, 800*time.Millisecond)
+
+			// t0 的请求移出窗口。
+			clock.add(500 * time.Millisecond)
+			assertResult(t, "t1000", must(l.Allow("u1")), true, 0, 0)
+			clock.add(300 * time.Millisecond)
+			assertResult(t, "t1300", must(l.AllowN("u1", 2)), true, 0, 0)
+		})
+	}
+}
+
+func TestSlidingCounter(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			l := NewSlidingCounter("ns", cachetest.Prefix("rl"), 10, time.Second, p)
+			l.now = clock.now
+
+			assertResult(t, "first window", must(l.AllowN("u1", 8)), true, 2, 0)
+
+			// 下一个窗口的 1/4 处，估算值为 8*0.75 = 6 。
+			clock.add(1250 * time.Millisecond)
+			assertResult(t, "weighted", must(l.AllowN("u1", 4)), true, 0, 0)
+
+			// 估算值 6+4 = 10 ，允许 2 个请求需要估算值降到 8 ，即上一个窗口的权重降到 0.5 。
+			assertResult(t, "exceed", must(l.AllowN("u1", 2)), false, 0, 250*time.Millisecond)
+
+			clock.add(250 * time.Millisecond)
+			assertResult(t, "retry", must(l.AllowN("u1", 2)), true, 0, 0)
+
+			// 当前窗口的计数已经达到 6 ，需要 5 个请求时，要等到下一个窗口并且估算值降到 5 ，即 500ms + 1000ms/6 。
+			assertResult(t, "next window", must(l.AllowN("u1", 5)), false, 0, 667*time.Millisecond)
+
+			// 两个窗口以后，计数清零。
+			clock.add(2 * time.Second)
+			assertResult(t, "reset", must(l.AllowN("u1", 10)), true, 0, 0)
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			// 每秒补充 10 个令牌，容量 5 。
+			l := NewTokenBucket("ns", cachetest.Prefix("rl"), 10, time.Second, 5, p)
+			l.now = clock.now
+
+			assertResult(t, "burst", must(l.AllowN("u1", 5)), true, 0, 0)
+			assertResult(t, "empty", must(l.AllowN("u1", 2)), false, 0, 200*time.Millisecond)
+
+			clock.add(150 * time.Millisecond)
+			assertResult(t, "refill", must(l.Allow("u1")), true, 0, 0)
+
+			clock.add(10 * time.Second)
+			assertResult(t, "full", must(l.Allow("u1")), true, 4, 0)
+
+			if _, err := l.AllowN("u1", 6); err == nil {
+				t.Errorf("AllowN() exceeding the burst should return error")
+			}
+		})
+	}
+}
+
+func TestUnsupportedProvider(t *testing.T) {
+	p := struct{ cache.CacheProvider }{cache.NewMemoryCacheProvider(time.Second)}
+	for name, fn := range map[string]func(){
+		"sliding_log":     func() { NewSlidingLog("ns", "rl", 1, time.Second, p) },
+		"sliding_counter": func() { NewSlidingCounter("ns", "rl", 1, time.Second, p) },
+		"token_bucket":    func() { NewTokenBucket("ns", "rl", 1, time.Second, 1, p) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("should panic with unsupported provider")
+				}
+			}()
+			fn()
+		})
+	}
+}
+
+func must(res Result, err error) Result {
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// slidingCounterScript 滑动窗口计数，使用哈希记录当前窗口和上一个窗口的计数，超过限流时不计入。
+//  ARGV[1]: 当前窗口的序号。
+//  ARGV[2]: 上一个窗口的序号。
+//  ARGV[3]: 上一个窗口的计数在滑动窗口中的权重。
+//  ARGV[4]: 请求数。
+//  ARGV[5]: 限流的请求数。
+//  ARGV[6]: 过期时长（毫秒），2 个窗口的时长。
+//  return: {是否允许, 上一个窗口的计数, 当前窗口的计数}
+var slidingCounterScript = redis.NewScript(`
+local curr = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+local prev = tonumber(redis.call('HGET', KEYS[1], ARGV[2]) or '0')
+local n = tonumber(ARGV[4])
+if prev * tonumber(ARGV[3]) + curr + n > tonumber(ARGV[5]) then
+	return {0, prev, curr}
+end
+curr = redis.call('HINCRBY', KEYS[1], ARGV[1], n)
+for _, field in ipairs(redis.call('HKEYS', KEYS[1])) do
+	if field ~= ARGV[1] and field ~= ARGV[2] then
+		redis.call('HDEL', KEYS[1], field)
+	end
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[6])
+return {1, prev, curr}
+`)
+
+// SlidingCounter 滑动窗口计数限流器，近似地限制任意 window 时长内最多允许 limit 个请求。
+// 只记录当前窗口和上一个窗口的计数，按上一个窗口与滑动窗口重叠的比例估算滑动窗口内的请求数，
+// 状态大小固定，适用于 limit 较大的场景。
+// 缓存key为 <CacheNamespace>:<Prefix>_<key> ，在 redis 中是一个哈希。
+type SlidingCounter struct {
+	baseLimiter
+}
+
+var _ Limiter = (*SlidingCounter)(nil)
+
+// slidingCounterState 内存缓存中的计数。
+type slidingCounterState struct {
+	index      int64 // 当前窗口的序号。
+	curr, prev int64 // 当前窗口和上一个窗口的计数。
+}
+
+// NewSlidingCounter 创建一个滑动窗口计数限流器。
+//  @limit: 窗口内允许的请求数。
+//  @window: 窗口的时长，窗口按 Unix 时间对齐。
+//  @cacheProvider: 必须是 RedisCacheProvider 或者 MemoryCacheProvider 。
+func NewSlidingCounter(
+	cacheNamespace, keyPrefix string,
+	limit int64,
+	window time.Duration,
+	cacheProvider cache.CacheProvider,
+	opts ...cache.OperationOption,
+) *SlidingCounter {
+	mustSupport(cacheProvider)
+	return &SlidingCounter{newBaseLimiter(cacheNamespace, keyPrefix, 1, limit, window, cacheProvider, opts)}
+}
+
+// implement Limiter.Allow .
+func (l *SlidingCounter) Allow(key any) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// implement Limiter.AllowN .
+func (l *SlidingCounter) AllowN(key any, n int64) (Result, error) {
+	if err := l.checkN(n); err != nil {
+		return Result{}, err
+	}
+
+	now, window := l.now().UnixMilli(), millis(l.window)
+	index := now / window
+	elapsed := now - index*window
+	weight := float64(window-elapsed) / float64(window)
+	keyOp := l.op.Key(key)
+	if err := keyOp.Err(); err != nil {
+		return Result{}, err
+	}
+	cacheKey := keyOp.Key
+
+	var allowed bool
+	var prev, curr int64
+	var err error
+	switch p := l.p.(type) {
+	case *cache.RedisCacheProvider:
+		var vals []int64
+		vals, err = int64Slice(slidingCounterScript.Run(context.Background(), p.Client(), []string{cacheKey},
+			index, index-1, strconv.FormatFloat(weight, 'f', -1, 64), n, l.limit, 2*window))
+		if err == nil {
+			allowed, prev, curr = vals[0] == 1, vals[1], vals[2]
+		}
+	case *cache.MemoryCacheProvider:
+		allowed, prev, curr, err = l.memory(p, cacheKey, index, weight, n, 2*window)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Allowed: allowed, Limit: l.limit, Remaining: remaining(l.limit, float64(prev)*weight+float64(curr))}
+	if !allowed {
+		res.RetryAfter = l.retryAfter(prev, curr, n, elapsed, window)
+	}
+	return res, nil
+}
+
+// memory 在锁内执行与 slidingCounterScript 相同的算法。
+//  return: 是否允许，上一个窗口的计数，当前窗口的计数。
+func (l *SlidingCounter) memory(p *cache.MemoryCacheProvider, cacheKey string, index int64, weight float64, n, ttl int64) (bool, int64, int64, error) {
+	var allowed bool
+	var prev, curr int64
+	err := p.Update(cacheKey, func(value any, found bool) (any, time.Duration, error) {
+		state := &slidingCounterState{index: index}
+		if found {
+			s, ok := value.(*slidingCounterState)
+			if !ok {
+				return nil, 0, fmt.Errorf("unexpected value type %T of key '%s'", value, cacheKey)
+			}
+
+			switch s.index {
+			case index:
+				state = s
+			case index - 1:
+				state.prev = s.curr
+			}
+		}
+
+		prev, curr = state.prev, state.curr
+		if float64(prev)*weight+float64(curr+n) > float64(l.limit) {
+			return state, time.Duration(ttl) * time.Millisecond, nil
+		}
+
+		state.curr += n
+		curr = state.curr
+		allowed = true
+		return state, time.Duration(ttl) * time.Millisecond, nil
+	})
+	return allowed, prev, curr, err
+}
+
+// retryAfter 计算估算的请求数降到允许 n 个请求所需的时长。
+// 当前窗口内，估算值随上一个窗口的权重线性减小；当前窗口的计数已经超出时，需要等到下一个窗口。
+func (l *SlidingCounter) retryAfter(prev, curr, n, elapsed, window int64) time.Duration {
+	w, limit := float64(window), float64(l.limit-n)
+
+	// 在窗口内经过 e 毫秒后，估算值 c*(1-e/w) + base 不超过 limit 。
+	wait := func(c, base int64) float64 {
+		if c == 0 {
+			return 0
+		}
+		return math.Max(0, w*(1-(limit-float64(base))/float64(c)))
+	}
+
+	var ms float64
+	if curr > l.limit-n {
+		ms = float64(window-elapsed) + wait(curr, 0)
+	} else {
+		ms = wait(prev, curr) - float64(elapsed)
+	}
+	return time.Duration(math.Ceil(math.Max(ms, 1))) * time.Millisecond
+}
@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// slidingLogScript 滑动窗口日志，使用有序集合记录窗口内每个请求的时间，超过限流时不记录。
+//  ARGV[1]: 当前时间（毫秒）。
+//  ARGV[2]: 窗口的时长（毫秒）。
+//  ARGV[3]: 请求数。
+//  ARGV[4]: 限流的请求数。
+//  ARGV[5]: 本次请求的成员前缀，保证成员唯一。
+//  return: {是否允许, 窗口内的请求数, 不允许时需要等待移出窗口的请求时间}
+var slidingLogScript = redis.NewScript(`
+local now, window = tonumber(ARGV[1]), tonumber(ARGV[2])
+local n, limit = tonumber(ARGV[3]), tonumber(ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count + n > limit then
+	local i = count + n - limit - 1
+	local first = redis.call('ZRANGE', KEYS[1], i, i, 'WITHSCORES')
+	return {0, count, first[2]}
+end
+for i = 1, n do
+	redis.call('ZADD', KEYS[1], now, ARGV[5] .. ':' .. i)
+end
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, count + n, '0'}
+`)
+
+// SlidingLog 滑动窗口日志限流器，任意 window 时长内最多允许 limit 个请求。
+// 记录窗口内每个请求的时间，结果精确，但状态大小与 limit 成正比，适用于 limit 较小的场景。
+// 缓存key为 <CacheNamespace>:<Prefix>_<key> ，在 redis 中是一个有序集合。
+type SlidingLog struct {
+	baseLimiter
+}
+
+var _ Limiter = (*SlidingLog)(nil)
+
+// slidingLogState 内存缓存中的请求时间，按时间升序。
+type slidingLogState struct {
+	times []int64
+}
+
+// NewSlidingLog 创建一个滑动窗口日志限流器。
+//  @limit: 窗口内允许的请求数。
+//  @window: 窗口的时长。
+//  @cacheProvider: 必须是 RedisCacheProvider 或者 MemoryCacheProvider 。
+func NewSlidingLog(
+	cacheNamespace, keyPrefix string,
+	limit int64,
+	window time.Duration,
+	cacheProvider cache.CacheProvider,
+	opts ...cache.OperationOption,
+) *SlidingLog {
+	mustSupport(cacheProvider)
+	return &SlidingLog{newBaseLimiter(cacheNamespace, keyPrefix, 1, limit, window, cacheProvider, opts)}
+}
+
+// implement Limiter.Allow .
+func (l *SlidingLog) Allow(key any) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// implement Limiter.AllowN .
+func (l *SlidingLog) AllowN(key any, n int64) (Result, error) {
+	if err := l.checkN(n); err != nil {
+		return Result{}, err
+	}
+
+	now, window := l.now().UnixMilli(), millis(l.window)
+	keyOp := l.op.Key(key)
+	if err := keyOp.Err(); err != nil {
+		return Result{}, err
+	}
+	cacheKey := keyOp.Key
+
+	var allowed bool
+	var count, first int64
+	var err error
+	switch p := l.p.(type) {
+	case *cache.RedisCacheProvider:
+		allowed, count, first, err = l.redis(p, cacheKey, now, window, n)
+	case *cache.MemoryCacheProvider:
+		allowed, count, first, err = l.memory(p, cacheKey, now, window, n)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Allowed: allowed, Limit: l.limit, Remaining: remaining(l.limit, float64(count))}
+	if !allowed {
+		res.RetryAfter = time.Duration(first+window-now) * time.Millisecond
+	}
+	return res, nil
+}
+
+// redis 在服务端执行，返回值与 memory 相同。
+func (l *SlidingLog) redis(p *cache.RedisCacheProvider, cacheKey string, now, window, n int64) (bool, int64, int64, error) {
+	member := strconv.FormatInt(now, 10) + "-" + strconv.FormatInt(rand.Int63(), 36)
+	vals, err := slice(slidingLogScript.Run(context.Background(), p.Client(), []string{cacheKey}, now, window, n, l.limit, member))
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	// 有序集合的分数以字符串返回。
+	first, err := strconv.ParseFloat(vals[2].(string), 64)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return vals[0].(int64) == 1, vals[1].(int64), int64(first), nil
+}
+
+// memory 在锁内执行与 slidingLogScript 相同的算法。
+//  return: 是否允许，窗口内的请求数，不允许时需要等待移出窗口的请求时间。
+func (l *SlidingLog) memory(p *cache.MemoryCacheProvider, cacheKey string, now, window, n int64) (bool, int64, int64, error) {
+	var allowed bool
+	var count, first int64
+	err := p.Update(cacheKey, func(value any, found bool) (any, time.Duration, error) {
+		state := &slidingLogState{}
+		if found {
+			s, ok := value.(*slidingLogState)
+			if !ok {
+				return nil, 0, fmt.Errorf("unexpected value type %T of key '%s'", value, cacheKey)
+			}
+			state = s
+		}
+
+		// 移除窗口以外的请求。
+		i := 0
+		for i < len(state.times) && state.times[i] <= now-window {
+			i++
+		}
+		state.times = state.times[i:]
+
+		count = int64(len(state.times))
+		if count+n > l.limit {
+			first = state.times[count+n-l.limit-1]
+		} else {
+			for i := int64(0); i < n; i++ {
+				state.times = append(state.times, now)
+			}
+			count += n
+			allowed = true
+		}
+		return state, time.Duration(window) * time.Millisecond, nil
+	})
+	return allowed, count, first, err
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thisXYH/cache"
+)
+
+// tokenBucketScript 令牌桶，使用哈希记录令牌数和上次补充的时间，令牌不足时不消耗。
+//  ARGV[1]: 每毫秒补充的令牌数。
+//  ARGV[2]: 桶的容量。
+//  ARGV[3]: 当前时间（毫秒）。
+//  ARGV[4]: 请求数。
+//  return: {是否允许, 剩余的令牌数}
+var tokenBucketScript = redis.NewScript(`
+local rate, burst = tonumber(ARGV[1]), tonumber(ARGV[2])
+local now, n = tonumber(ARGV[3]), tonumber(ARGV[4])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+	ts = now
+end
+local allowed = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// TokenBucket 令牌桶限流器，按固定的速率补充令牌，允许不超过桶容量的突发请求。
+// 缓存key为 <CacheNamespace>:<Prefix>_<key> ，在 redis 中是一个哈希，桶满后过期。
+type TokenBucket struct {
+	baseLimiter
+	rate float64 // 每毫秒补充的令牌数。
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// tokenBucketState 内存缓存中的令牌桶。
+type tokenBucketState struct {
+	tokens float64
+	ts     int64 // 上次补充令牌的时间（毫秒）。
+}
+
+// NewTokenBucket 创建一个令牌桶限流器，每个 window 时长补充 rate 个令牌，桶的容量为 burst 。
+// 新的令牌桶是满的， Result.Limit 为 burst 。
+//  @cacheProvider: 必须是 RedisCacheProvider 或者 MemoryCacheProvider 。
+func NewTokenBucket(
+	cacheNamespace, keyPrefix string,
+	rate int64,
+	window time.Duration,
+	burst int64,
+	cacheProvider cache.CacheProvider,
+	opts ...cache.OperationOption,
+) *TokenBucket {
+	mustSupport(cacheProvider)
+	if rate <= 0 {
+		panic(fmt.Errorf("param 'rate' must be greater than 0"))
+	}
+
+	if burst <= 0 {
+		panic(fmt.Errorf("param 'burst' must be greater than 0"))
+	}
+
+	base := newBaseLimiter(cacheNamespace, keyPrefix, 1, burst, window, cacheProvider, opts)
+	return &TokenBucket{base, float64(rate) / float64(millis(window))}
+}
+
+// implement Limiter.Allow .
+func (l *TokenBucket) Allow(key any) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// implement Limiter.AllowN .
+func (l *TokenBucket) AllowN(key any, n int64) (Result, error) {
+	if err := l.checkN(n); err != nil {
+		return Result{}, err
+	}
+
+	now := l.now().UnixMilli()
+	keyOp := l.op.Key(key)
+	if err := keyOp.Err(); err != nil {
+		return Result{}, err
+	}
+	cacheKey := keyOp.Key
+
+	var allowed bool
+	var tokens float64
+	var err error
+	switch p := l.p.(type) {
+	case *cache.RedisCacheProvider:
+		allowed, tokens, err = l.redis(p, cacheKey, now, n)
+	case *cache.MemoryCacheProvider:
+		allowed, tokens, err = l.memory(p, cacheKey, now, n)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Allowed: allowed, Limit: l.limit, Remaining: int64(math.Floor(tokens))}
+	if !allowed {
+		ms := math.Ceil((float64(n) - tokens) / l.rate)
+		res.RetryAfter = time.Duration(math.Max(ms, 1)) * time.Millisecond
+	}
+	return res, nil
+}
+
+// redis 在服务端执行，返回值与 memory 相同。
+func (l *TokenBucket) redis(p *cache.RedisCacheProvider, cacheKey string, now, n int64) (bool, float64, error) {
+	rate := strconv.FormatFloat(l.rate, 'g', -1, 64)
+	vals, err := slice(tokenBucketScript.Run(context.Background(), p.Client(), []string{cacheKey}, rate, l.limit, now, n))
+	if err != nil {
+		return false, 0, err
+	}
+
+	tokens, err := strconv.ParseFloat(vals[1].(string), 64)
+	if err != nil {
+		return false, 0, err
+	}
+	return vals[0].(int64) == 1, tokens, nil
+}
+
+// memory 在锁内执行与 tokenBucketScript 相同的算法。
+//  return: 是否允许，剩余的令牌数。
+func (l *TokenBucket) memory(p *cache.MemoryCacheProvider, cacheKey string, now, n int64) (bool, float64, error) {
+	var allowed bool
+	var tokens float64
+	err := p.Update(cacheKey, func(value any, found bool) (any, time.Duration, error) {
+		state := &tokenBucketState{float64(l.limit), now}
+		if found {
+			s, ok := value.(*tokenBucketState)
+			if !ok {
+				return nil, 0, fmt.Errorf("unexpected value type %T of key '%s'", value, cacheKey)
+			}
+			state = s
+		}
+
+		// 时钟回拨时不补充令牌。
+		if now > state.ts {
+			state.tokens = math.Min(float64(l.limit), state.tokens+float64(now-state.ts)*l.rate)
+			state.ts = now
+		}
+
+		if state.tokens >= float64(n) {
+			state.tokens -= float64(n)
+			allowed = true
+		}
+		tokens = state.tokens
+
+		ttl := math.Ceil((float64(l.limit)-state.tokens)/l.rate) + 1
+		return state, time.Duration(ttl) * time.Millisecond, nil
+	})
+	return allowed, tokens, err
+}
@@ -2,7 +2,6 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -13,17 +12,48 @@ import (
 // Redis 类型的缓存提供器。
 type RedisCacheProvider struct {
 	client redis.Cmdable
+
+	// 缓存值的编解码器。
+	codec Codec
 }
 
 var (
-	_ CacheProvider = (*RedisCacheProvider)(nil)
+	_ CacheProvider         = (*RedisCacheProvider)(nil)
+	_ CodecCacheProvider    = (*RedisCacheProvider)(nil)
+	_ MultiKeyCacheProvider = (*RedisCacheProvider)(nil)
+	_ CompareCacheProvider  = (*RedisCacheProvider)(nil)
 )
 
+// NewRedisCacheProvider 创建一个 Redis 缓存提供器，缓存值默认使用 JSONCodec 编解码。
+// 支持 *redis.Client（包括哨兵模式的 failover client）、*redis.ClusterClient、*redis.Ring 等任意的 redis.Cmdable ，
+// 批量操作多个 key 时，会按照 key 所在的节点自动拆分。
 func NewRedisCacheProvider(cli redis.Cmdable) *RedisCacheProvider {
 	if cli == nil {
 		panic(errors.New("param 'cli' is nil"))
 	}
-	return &RedisCacheProvider{cli}
+	return &RedisCacheProvider{cli, JSONCodec}
+}
+
+// NewUniversalRedisCacheProvider 根据 redis.UniversalOptions 创建 Redis 缓存提供器：
+// 指定了 MasterName 时使用哨兵模式，指定了多个 Addrs 时使用 Cluster 模式，反之使用单节点模式。
+func NewUniversalRedisCacheProvider(opt *redis.UniversalOptions) *RedisCacheProvider {
+	if opt == nil {
+		panic(errors.New("param 'opt' is nil"))
+	}
+	return NewRedisCacheProvider(redis.NewUniversalClient(opt))
+}
+
+// implement CodecCacheProvider.WithCodec .
+func (cli *RedisCacheProvider) WithCodec(codec Codec) CacheProvider {
+	if codec == nil {
+		panic(errors.New("param 'codec' is nil"))
+	}
+	return &RedisCacheProvider{cli.client, codec}
+}
+
+// Client 获取底层的 redis 客户端，用于执行缓存语义以外的命令，例如自定义的 Lua 脚本。
+func (cli *RedisCacheProvider) Client() redis.Cmdable {
+	return cli.client
 }
 
 // implement CacheProvider.Get .
@@ -39,7 +69,7 @@ func (cli *RedisCacheProvider) TryGet(key string, value any) (bool, error) {
 	}
 
 	cmd := cli.client.Get(context.Background(), key)
-	v, err := cmd.Result()
+	v, err := cmd.Bytes()
 	if err != nil {
 		if err == redis.Nil { //key 不存在
 			return false, nil
@@ -47,7 +77,10 @@ func (cli *RedisCacheProvider) TryGet(key string, value any) (bool, error) {
 		return false, err
 	}
 
-	if err = json.Unmarshal([]byte(v), value); err != nil {
+	if err = cli.codec.Unmarshal(v, value); err != nil {
+		if errors.Is(err, ErrCacheMiss) { // 编解码器要求视为缓存不存在。
+			return false, nil
+		}
 		return false, err
 	}
 
@@ -60,12 +93,12 @@ func (cli *RedisCacheProvider) Create(key string, value any, t time.Duration) (b
 		return false, fmt.Errorf("key must not be empty")
 	}
 
-	v, err := json.Marshal(value)
+	v, err := cli.codec.Marshal(value)
 	if err != nil {
 		return false, err
 	}
 
-	cmd := cli.client.SetNX(context.Background(), key, string(v), t)
+	cmd := cli.client.SetNX(context.Background(), key, v, t)
 	return cmd.Result()
 }
 
@@ -75,13 +108,13 @@ func (cli *RedisCacheProvider) Set(key string, value any, t time.Duration) error
 		return fmt.Errorf("key must not be empty")
 	}
 
-	v, err := json.Marshal(value)
+	v, err := cli.codec.Marshal(value)
 	if err != nil {
 		return err
 	}
 
 	const OK = `OK` // 执行成功的返回值。
-	cmd := cli.client.Set(context.Background(), key, string(v), t)
+	cmd := cli.client.Set(context.Background(), key, v, t)
 	cv, err := cmd.Result()
 	if err != nil {
 		return err
@@ -112,73 +145,227 @@ func (cli *RedisCacheProvider) Remove(key string) (bool, error) {
 	return true, nil
 }
 
+// increaseScript 仅当 key 存在时加1，key 不存在时返回 nil 。
+// 脚本在服务端原子执行，不受并发竞争的影响，也不依赖 WATCH ，适用于任意的 redis.Cmdable 。
+var increaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('INCR', KEYS[1])
+`)
+
 // implement CacheProvider.Increase .
 func (cli *RedisCacheProvider) Increase(key string) (int64, error) {
 	if key == "" {
 		return 0, fmt.Errorf("key must not be empty")
 	}
 
-	const MaxRetries = 2 // 最大重试次数。
-
-	var value int64 = 0
-	increaseIfExistsTrans := func(tx *redis.Tx) error {
-		cmd := tx.Get(tx.Context(), key)
-		kv, err := cmd.Int64() // 只关心key存不存在，以及是不是数字。
-		if err != nil {
-			if err == redis.Nil { //缓存不存在。
-				return fmt.Errorf("cache key does not exist: %s", key)
-			}
-			// 存在但不是数字，或者其他 error。
-			return err
+	// Run 优先使用 EVALSHA ，脚本未加载时自动使用 EVAL 执行并加载。
+	v, err := increaseScript.Run(context.Background(), cli.client, []string{key}).Int64()
+	if err != nil {
+		if err == redis.Nil { //缓存不存在。
+			return 0, fmt.Errorf("cache key does not exist: %s", key)
 		}
-		kv++
-		value = kv
-		_, err = tx.TxPipelined(tx.Context(), func(pipe redis.Pipeliner) error {
-			// 这边应该默认返回了空的结果，具体执行情况是需要管道执行结束才有的，
-			// 所以不需要接收。
-			pipe.Incr(tx.Context(), key)
-			return nil
-		})
-		return err
+		// 存在但不是数字，或者其他 error。
+		return 0, err
+	}
+
+	return v, nil
+}
+
+// increaseOrCreateScript 为 key 增加增量，只有 key 是新创建的才设置过期时间。
+// INCRBY 与 PEXPIRE 在服务端原子执行，不会留下永不过期的计数器，
+// 已存在的 key 即使增加后的值恰好等于增量，也不会重置过期时间。
+//  ARGV[1]: 增量。
+//  ARGV[2]: 过期时长（毫秒），0 表不过期。
+var increaseOrCreateScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if created and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return v
+`)
+
+// implement CacheProvider.IncreaseOrCreate .
+func (cli *RedisCacheProvider) IncreaseOrCreate(key string, increment int64, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cmd := increaseOrCreateScript.Run(context.Background(), cli.client, []string{key}, increment, milliseconds(t))
+	return cmd.Int64()
+}
+
+// compareAndRemoveScript 仅当缓存值等于 ARGV[1] 时移除缓存。
+var compareAndRemoveScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// compareAndExpireScript 仅当缓存值等于 ARGV[1] 时重新设置过期时间。
+//  ARGV[2]: 过期时长（毫秒），0 表不过期。
+var compareAndExpireScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+else
+	redis.call('PERSIST', KEYS[1])
+end
+return 1
+`)
+
+// implement CompareCacheProvider.CompareAndRemove .
+func (cli *RedisCacheProvider) CompareAndRemove(key string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	data, err := cli.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := compareAndRemoveScript.Run(context.Background(), cli.client, []string{key}, data).Int64()
+	return n == 1, err
+}
+
+// implement CompareCacheProvider.CompareAndExpire .
+func (cli *RedisCacheProvider) CompareAndExpire(key string, value any, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	data, err := cli.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := compareAndExpireScript.Run(context.Background(), cli.client, []string{key}, data, milliseconds(t)).Int64()
+	return n == 1, err
+}
+
+// milliseconds 将过期时长转换成毫秒，不足 1 毫秒的按 1 毫秒计算。
+func milliseconds(t time.Duration) int64 {
+	if t > 0 && t < time.Millisecond {
+		return 1
+	}
+	return int64(t / time.Millisecond)
+}
+
+// implement MultiKeyCacheProvider.TryGetMulti .
+func (cli *RedisCacheProvider) TryGetMulti(keys []string, values []any) ([]bool, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("len(keys)(%d) != len(values)(%d)", len(keys), len(values))
 	}
 
-	type Watcher interface {
-		Watch(context.Context, func(*redis.Tx) error, ...string) error
+	if err := checkKeys(keys); err != nil {
+		return nil, err
 	}
-	watcher, ok := cli.client.(Watcher)
-	if !ok {
-		return 0, fmt.Errorf("unsupport redis client type: %t", cli.client)
+
+	if len(keys) == 0 {
+		return []bool{}, nil
 	}
-	for i := 0; i < MaxRetries; i++ {
-		err := watcher.Watch(context.Background(), increaseIfExistsTrans, key)
-		if err == nil {
-			return value, err
+
+	ctx := context.Background()
+	groups := cli.splitKeys(keys)
+	cmds := make([]*redis.SliceCmd, len(groups))
+	err := cli.pipelined(ctx, len(groups), func(pipe redis.Cmdable) {
+		for i, g := range groups {
+			cmds[i] = pipe.MGet(ctx, pickKeys(keys, g)...)
 		}
-		if err == redis.TxFailedErr {
-			continue
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]bool, len(keys))
+	for i, g := range groups {
+		vals, err := cmds[i].Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for j, v := range vals {
+			s, ok := v.(string)
+			if !ok { // key 不存在。
+				continue
+			}
+
+			idx := g[j]
+			if err = cli.codec.Unmarshal([]byte(s), values[idx]); err != nil {
+				if errors.Is(err, ErrCacheMiss) {
+					continue
+				}
+				return nil, err
+			}
+			result[idx] = true
 		}
-		return 0, err
 	}
 
-	return 0, fmt.Errorf("increment reached maximum number of retries(%d)", MaxRetries)
+	return result, nil
 }
 
-// implement CacheProvider.IncreaseOrCreate .
-func (cli *RedisCacheProvider) IncreaseOrCreate(key string, increment int64, t time.Duration) (int64, error) {
-	if key == "" {
-		return 0, fmt.Errorf("key must not be empty")
+// implement MultiKeyCacheProvider.RemoveMulti .
+func (cli *RedisCacheProvider) RemoveMulti(keys ...string) (int64, error) {
+	if err := checkKeys(keys); err != nil {
+		return 0, err
 	}
 
-	cmd := cli.client.IncrBy(context.Background(), key, increment)
-	v, err := cmd.Result()
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	groups := cli.splitKeys(keys)
+	cmds := make([]*redis.IntCmd, len(groups))
+	err := cli.pipelined(ctx, len(groups), func(pipe redis.Cmdable) {
+		for i, g := range groups {
+			cmds[i] = pipe.Del(ctx, pickKeys(keys, g)...)
+		}
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	// 如果key是新创建的，指定过期时间。
-	if v == increment {
-		cli.client.Expire(context.Background(), key, t)
+	var n int64
+	for _, cmd := range cmds {
+		v, err := cmd.Result()
+		if err != nil {
+			return 0, err
+		}
+		n += v
+	}
+	return n, nil
+}
+
+// pipelined 执行多个命令，只有一个命令时直接执行，反之使用管道减少网络往返。
+// 各命令的执行结果需要通过命令自身获取。
+func (cli *RedisCacheProvider) pipelined(ctx context.Context, n int, fn func(pipe redis.Cmdable)) error {
+	if n == 1 {
+		fn(cli.client)
+		return nil
 	}
 
-	return v, err
+	_, err := cli.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		fn(pipe)
+		return nil
+	})
+	if err == redis.Nil { // 由各命令自行处理。
+		err = nil
+	}
+	return err
+}
+
+// pickKeys 获取指定下标的 key 。
+func pickKeys(keys []string, indexes []int) []string {
+	res := make([]string, len(indexes))
+	for i, idx := range indexes {
+		res[i] = keys[idx]
+	}
+	return res
 }
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ BitmapCacheProvider = (*RedisCacheProvider)(nil)
+
+// bitmapSetScript 设置位，只有位图是新创建的才设置过期时间。
+//  ARGV[1]: 偏移量。
+//  ARGV[2]: 位的值， 0 或 1 。
+//  ARGV[3]: 过期时长（毫秒），0 表不过期。
+var bitmapSetScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local old = redis.call('SETBIT', KEYS[1], ARGV[1], ARGV[2])
+if created and tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return old
+`)
+
+// bitmapOpScript 执行位运算，结果写入 KEYS[1] 并设置过期时间。
+//  ARGV[1]: 位运算。
+//  ARGV[2]: 过期时长（毫秒），0 表不过期。
+var bitmapOpScript = redis.NewScript(`
+local n = redis.call('BITOP', ARGV[1], unpack(KEYS))
+if n > 0 and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n
+`)
+
+// implement BitmapCacheProvider.BitmapSet .
+func (cli *RedisCacheProvider) BitmapSet(key string, offset int64, value bool, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+
+	bit := 0
+	if value {
+		bit = 1
+	}
+
+	old, err := bitmapSetScript.Run(context.Background(), cli.client, []string{key}, offset, bit, milliseconds(t)).Int64()
+	return old == 1, err
+}
+
+// implement BitmapCacheProvider.BitmapGet .
+func (cli *RedisCacheProvider) BitmapGet(key string, offset int64) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+
+	bit, err := cli.client.GetBit(context.Background(), key, offset).Result()
+	return bit == 1, err
+}
+
+// implement BitmapCacheProvider.BitmapCount .
+func (cli *RedisCacheProvider) BitmapCount(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.BitCount(context.Background(), key, nil).Result()
+}
+
+// implement BitmapCacheProvider.BitmapOp .
+// 不在同一个哈希槽的位图，读取后在客户端计算再写入 dest，此时运算不是原子的。
+func (cli *RedisCacheProvider) BitmapOp(op BitOp, dest string, keys []string, t time.Duration) (int64, error) {
+	if dest == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if err := checkBitOp(op, keys); err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	all := append([]string{dest}, keys...)
+	if len(cli.splitKeys(all)) == 1 {
+		return bitmapOpScript.Run(ctx, cli.client, all, string(op), milliseconds(t)).Int64()
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	err := cli.pipelined(ctx, len(keys), func(pipe redis.Cmdable) {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	srcs := make([][]byte, len(keys))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		srcs[i] = data
+	}
+
+	res := bitOpBytes(op, srcs)
+	if len(res) == 0 {
+		return 0, cli.client.Del(ctx, dest).Err()
+	}
+	return int64(len(res)), cli.client.Set(ctx, dest, res, t).Err()
+}
+
+// implement BitmapCacheProvider.BitmapPos .
+func (cli *RedisCacheProvider) BitmapPos(key string, bit bool) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	var b int64
+	if bit {
+		b = 1
+	}
+	return cli.client.BitPos(context.Background(), key, b).Result()
+}
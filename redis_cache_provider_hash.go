@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ HashCacheProvider = (*RedisCacheProvider)(nil)
+
+// hashSetScript 设置哈希字段，只有哈希是新创建的才设置过期时间。
+//  ARGV[1]: 字段。
+//  ARGV[2]: 字段的值。
+//  ARGV[3]: 过期时长（毫秒），0 表不过期。
+var hashSetScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+if created and tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// hashIncreaseOrCreateScript 为哈希字段增加增量，只有哈希是新创建的才设置过期时间。
+//  ARGV[1]: 字段。
+//  ARGV[2]: 增量。
+//  ARGV[3]: 过期时长（毫秒），0 表不过期。
+var hashIncreaseOrCreateScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local v = redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+if created and tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return v
+`)
+
+// implement HashCacheProvider.HashTryGet .
+func (cli *RedisCacheProvider) HashTryGet(key, field string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	v, err := cli.client.HGet(context.Background(), key, field).Bytes()
+	if err != nil {
+		if err == redis.Nil { // 哈希或者字段不存在。
+			return false, nil
+		}
+		return false, err
+	}
+
+	return cli.decode(v, value)
+}
+
+// implement HashCacheProvider.HashSet .
+func (cli *RedisCacheProvider) HashSet(key, field string, value any, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	v, err := cli.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return hashSetScript.Run(context.Background(), cli.client, []string{key}, field, v, milliseconds(t)).Err()
+}
+
+// implement HashCacheProvider.HashRemove .
+func (cli *RedisCacheProvider) HashRemove(key string, fields ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	return cli.client.HDel(context.Background(), key, fields...).Result()
+}
+
+// implement HashCacheProvider.HashGetAll .
+func (cli *RedisCacheProvider) HashGetAll(key string, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	fields, err := cli.client.HGetAll(context.Background(), key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if len(fields) == 0 { // 哈希不存在。
+		return false, nil
+	}
+
+	err = setMapValue(value, fields, func(item string, elem any) error {
+		return cli.codec.Unmarshal([]byte(item), elem)
+	})
+	return err == nil, err
+}
+
+// implement HashCacheProvider.HashIncreaseOrCreate .
+func (cli *RedisCacheProvider) HashIncreaseOrCreate(key, field string, increment int64, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cmd := hashIncreaseOrCreateScript.Run(context.Background(), cli.client, []string{key}, field, increment, milliseconds(t))
+	return cmd.Int64()
+}
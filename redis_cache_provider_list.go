@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ ListCacheProvider = (*RedisCacheProvider)(nil)
+
+// listPushScript 将元素依次插入列表，只有列表是新创建的才设置过期时间。
+//  ARGV[1]: LPUSH 或者 RPUSH 。
+//  ARGV[2]: 过期时长（毫秒），0 表不过期。
+//  ARGV[3...]: 元素。
+var listPushScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local n = redis.call(ARGV[1], KEYS[1], unpack(ARGV, 3))
+if created and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n
+`)
+
+// listBlockingTimeout 每次阻塞弹出的最长等待时间，
+// 阻塞弹出分多次进行，每次之间检查 ctx ，使 ctx 结束时能够及时返回。
+// 命令不使用 ctx ，由服务端而不是客户端的连接超时结束等待，不会丢失已经弹出的元素。
+const listBlockingTimeout = time.Second
+
+// listBlockingMargin ctx 有截止时间时，每次阻塞弹出的等待时间比剩余时间少 listBlockingMargin ，
+// 服务端在网络延迟之后才开始计时，留出余量使服务端在 ctx 结束之前返回。
+const listBlockingMargin = 50 * time.Millisecond
+
+// implement ListCacheProvider.ListPush .
+func (cli *RedisCacheProvider) ListPush(key string, left bool, values []any, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(values) == 0 {
+		return cli.ListLen(key)
+	}
+
+	cmd := "RPUSH"
+	if left {
+		cmd = "LPUSH"
+	}
+
+	args := make([]any, 0, len(values)+2)
+	args = append(args, cmd, milliseconds(t))
+	for _, v := range values {
+		b, err := cli.codec.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, b)
+	}
+
+	return listPushScript.Run(context.Background(), cli.client, []string{key}, args...).Int64()
+}
+
+// implement ListCacheProvider.ListPop .
+func (cli *RedisCacheProvider) ListPop(key string, left bool, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	var cmd *redis.StringCmd
+	if left {
+		cmd = cli.client.LPop(context.Background(), key)
+	} else {
+		cmd = cli.client.RPop(context.Background(), key)
+	}
+
+	v, err := cmd.Bytes()
+	if err != nil {
+		if err == redis.Nil { // 列表为空。
+			return false, nil
+		}
+		return false, err
+	}
+
+	return cli.decode(v, value)
+}
+
+// implement ListCacheProvider.ListBlockingPop .
+func (cli *RedisCacheProvider) ListBlockingPop(ctx context.Context, key string, left bool, value any) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		timeout := listBlockingTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			if d := time.Until(deadline) - listBlockingMargin; d < timeout {
+				timeout = d
+			}
+		}
+		if timeout < time.Millisecond { // 剩余时间不足以安全地等待。
+			return false, context.DeadlineExceeded
+		}
+
+		v, err := cli.blockingPop(key, left, timeout)
+		if err != nil {
+			if err == redis.Nil { // 等待超时，继续等待。
+				continue
+			}
+			return false, err
+		}
+
+		ok, err := cli.decode([]byte(v), value)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+}
+
+// redisDoer 可以执行任意命令的客户端， *redis.Client 、 *redis.ClusterClient 、 *redis.Ring 都实现了该接口。
+type redisDoer interface {
+	Do(ctx context.Context, args ...any) *redis.Cmd
+}
+
+// blockingPop 执行一次 BLPOP 或者 BRPOP ，等待超时返回 redis.Nil 。
+// go-redis 会把不足 1 秒的等待时间截断为 1 秒，这时直接发送带小数的秒数，需要 Redis 6.0 以上；
+// 客户端不支持直接执行命令时，仍然等待 1 秒。
+// 命令不使用调用方的 ctx ，避免客户端超时时服务端已经弹出了元素。
+func (cli *RedisCacheProvider) blockingPop(key string, left bool, timeout time.Duration) (string, error) {
+	ctx := context.Background()
+	name := "BRPOP"
+	if left {
+		name = "BLPOP"
+	}
+
+	doer, ok := cli.client.(redisDoer)
+	if timeout >= time.Second || !ok {
+		var cmd *redis.StringSliceCmd
+		if left {
+			cmd = cli.client.BLPop(ctx, timeout, key)
+		} else {
+			cmd = cli.client.BRPop(ctx, timeout, key)
+		}
+
+		kv, err := cmd.Result()
+		if err != nil {
+			return "", err
+		}
+		return kv[1], nil
+	}
+
+	// 按毫秒向下取整，等待时间不超过 timeout 。
+	ms := strconv.FormatFloat(float64(timeout.Milliseconds())/1000, 'f', 3, 64)
+	res, err := doer.Do(ctx, name, key, ms).Result()
+	if err != nil {
+		return "", err
+	}
+
+	kv, ok := res.([]any)
+	if !ok || len(kv) != 2 {
+		return "", fmt.Errorf("unexpected %s reply: %v", name, res)
+	}
+	v, _ := kv[1].(string)
+	return v, nil
+}
+
+// implement ListCacheProvider.ListRange .
+func (cli *RedisCacheProvider) ListRange(key string, start, stop int64, values any) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	items, err := cli.client.LRange(context.Background(), key, start, stop).Result()
+	if err != nil {
+		return err
+	}
+
+	return setSliceValue(values, items, func(item string, elem any) error {
+		return cli.codec.Unmarshal([]byte(item), elem)
+	})
+}
+
+// implement ListCacheProvider.ListTrim .
+func (cli *RedisCacheProvider) ListTrim(key string, start, stop int64) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.LTrim(context.Background(), key, start, stop).Err()
+}
+
+// implement ListCacheProvider.ListLen .
+func (cli *RedisCacheProvider) ListLen(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.LLen(context.Background(), key).Result()
+}
+
+// decode 使用编解码器将 data 解码到 value，编解码器要求视为不存在时返回 false 。
+func (cli *RedisCacheProvider) decode(data []byte, value any) (bool, error) {
+	if err := cli.codec.Unmarshal(data, value); err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ SetCacheProvider = (*RedisCacheProvider)(nil)
+
+// setAddScript 添加成员，只有集合是新创建的才设置过期时间。
+//  ARGV[1]: 过期时长（毫秒），0 表不过期。
+//  ARGV[2...]: 成员。
+var setAddScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local n = redis.call('SADD', KEYS[1], unpack(ARGV, 2))
+if created and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// implement SetCacheProvider.SetAdd .
+func (cli *RedisCacheProvider) SetAdd(key string, members []string, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	args := make([]any, 0, len(members)+1)
+	args = append(args, milliseconds(t))
+	args = append(args, stringsToAny(members)...)
+
+	return setAddScript.Run(context.Background(), cli.client, []string{key}, args...).Int64()
+}
+
+// implement SetCacheProvider.SetRemove .
+func (cli *RedisCacheProvider) SetRemove(key string, members ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	return cli.client.SRem(context.Background(), key, stringsToAny(members)...).Result()
+}
+
+// implement SetCacheProvider.SetContains .
+func (cli *RedisCacheProvider) SetContains(key, member string) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.SIsMember(context.Background(), key, member).Result()
+}
+
+// implement SetCacheProvider.SetMembers .
+func (cli *RedisCacheProvider) SetMembers(key string) ([]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.SMembers(context.Background(), key).Result()
+}
+
+// implement SetCacheProvider.SetCard .
+func (cli *RedisCacheProvider) SetCard(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.SCard(context.Background(), key).Result()
+}
+
+// implement SetCacheProvider.SetInter .
+// 不在同一个哈希槽的集合，分组计算后在客户端求交集。
+func (cli *RedisCacheProvider) SetInter(keys ...string) ([]string, error) {
+	groups, err := cli.setGroups(keys, func(ctx context.Context, pipe redis.Cmdable, keys []string) *redis.StringSliceCmd {
+		return pipe.SInter(ctx, keys...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+
+	res := groups[0]
+	for _, g := range groups[1:] {
+		in := make(map[string]struct{}, len(g))
+		for _, m := range g {
+			in[m] = struct{}{}
+		}
+
+		n := 0
+		for _, m := range res {
+			if _, ok := in[m]; ok {
+				res[n] = m
+				n++
+			}
+		}
+		res = res[:n]
+	}
+	return res, nil
+}
+
+// implement SetCacheProvider.SetUnion .
+// 不在同一个哈希槽的集合，分组计算后在客户端求并集。
+func (cli *RedisCacheProvider) SetUnion(keys ...string) ([]string, error) {
+	groups, err := cli.setGroups(keys, func(ctx context.Context, pipe redis.Cmdable, keys []string) *redis.StringSliceCmd {
+		return pipe.SUnion(ctx, keys...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+
+	union := make(map[string]struct{})
+	var res []string
+	for _, g := range groups {
+		for _, m := range g {
+			if _, ok := union[m]; !ok {
+				union[m] = struct{}{}
+				res = append(res, m)
+			}
+		}
+	}
+	return res, nil
+}
+
+// setGroups 按照 splitKeys 的分组，对每组集合执行 cmd ，返回每组的结果。
+func (cli *RedisCacheProvider) setGroups(
+	keys []string,
+	cmd func(ctx context.Context, pipe redis.Cmdable, keys []string) *redis.StringSliceCmd,
+) ([][]string, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keys must not be empty")
+	}
+
+	if err := checkKeys(keys); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	groups := cli.splitKeys(keys)
+	cmds := make([]*redis.StringSliceCmd, len(groups))
+	err := cli.pipelined(ctx, len(groups), func(pipe redis.Cmdable) {
+		for i, g := range groups {
+			cmds[i] = cmd(ctx, pipe, pickKeys(keys, g))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([][]string, len(cmds))
+	for i, c := range cmds {
+		if res[i], err = c.Result(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ SortedSetCacheProvider = (*RedisCacheProvider)(nil)
+
+// sortedSetAddScript 添加成员，只有有序集合是新创建的才设置过期时间。
+//  ARGV[1]: 过期时长（毫秒），0 表不过期。
+//  ARGV[2...]: 分数、成员交替排列。
+var sortedSetAddScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local n = redis.call('ZADD', KEYS[1], unpack(ARGV, 2))
+if created and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// sortedSetIncreaseScript 为成员的分数增加增量，只有有序集合是新创建的才设置过期时间。
+//  ARGV[1]: 成员。
+//  ARGV[2]: 增量。
+//  ARGV[3]: 过期时长（毫秒），0 表不过期。
+var sortedSetIncreaseScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local score = redis.call('ZINCRBY', KEYS[1], ARGV[2], ARGV[1])
+if created and tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return score
+`)
+
+// implement SortedSetCacheProvider.SortedSetAdd .
+func (cli *RedisCacheProvider) SortedSetAdd(key string, members []SortedSetMember, t time.Duration) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	args := make([]any, 0, len(members)*2+1)
+	args = append(args, milliseconds(t))
+	for _, m := range members {
+		args = append(args, formatScore(m.Score), m.Member)
+	}
+
+	return sortedSetAddScript.Run(context.Background(), cli.client, []string{key}, args...).Int64()
+}
+
+// implement SortedSetCacheProvider.SortedSetIncrease .
+func (cli *RedisCacheProvider) SortedSetIncrease(key, member string, increment float64, t time.Duration) (float64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	cmd := sortedSetIncreaseScript.Run(context.Background(), cli.client, []string{key}, member, formatScore(increment), milliseconds(t))
+	s, err := cmd.Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// implement SortedSetCacheProvider.SortedSetScore .
+func (cli *RedisCacheProvider) SortedSetScore(key, member string) (float64, bool, error) {
+	if key == "" {
+		return 0, false, fmt.Errorf("key must not be empty")
+	}
+
+	score, err := cli.client.ZScore(context.Background(), key, member).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRank .
+func (cli *RedisCacheProvider) SortedSetRank(key, member string, reverse bool) (int64, bool, error) {
+	if key == "" {
+		return 0, false, fmt.Errorf("key must not be empty")
+	}
+
+	var cmd *redis.IntCmd
+	if reverse {
+		cmd = cli.client.ZRevRank(context.Background(), key, member)
+	} else {
+		cmd = cli.client.ZRank(context.Background(), key, member)
+	}
+
+	rank, err := cmd.Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return rank, true, nil
+}
+
+// implement SortedSetCacheProvider.SortedSetRangeByRank .
+func (cli *RedisCacheProvider) SortedSetRangeByRank(key string, start, stop int64, reverse bool) ([]SortedSetMember, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	var cmd *redis.ZSliceCmd
+	if reverse {
+		cmd = cli.client.ZRevRangeWithScores(context.Background(), key, start, stop)
+	} else {
+		cmd = cli.client.ZRangeWithScores(context.Background(), key, start, stop)
+	}
+
+	return sortedSetMembers(cmd.Result())
+}
+
+// implement SortedSetCacheProvider.SortedSetRangeByScore .
+func (cli *RedisCacheProvider) SortedSetRangeByScore(key string, min, max float64, offset, count int64, reverse bool) ([]SortedSetMember, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	// count 为 0 时，go-redis 不会发送 LIMIT 。
+	if count == 0 {
+		return nil, nil
+	}
+
+	opt := &redis.ZRangeBy{Min: formatScore(min), Max: formatScore(max), Offset: offset, Count: count}
+	var cmd *redis.ZSliceCmd
+	if reverse {
+		cmd = cli.client.ZRevRangeByScoreWithScores(context.Background(), key, opt)
+	} else {
+		cmd = cli.client.ZRangeByScoreWithScores(context.Background(), key, opt)
+	}
+
+	return sortedSetMembers(cmd.Result())
+}
+
+// implement SortedSetCacheProvider.SortedSetRemove .
+func (cli *RedisCacheProvider) SortedSetRemove(key string, members ...string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	return cli.client.ZRem(context.Background(), key, stringsToAny(members)...).Result()
+}
+
+// implement SortedSetCacheProvider.SortedSetRemoveByScore .
+func (cli *RedisCacheProvider) SortedSetRemoveByScore(key string, min, max float64) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.ZRemRangeByScore(context.Background(), key, formatScore(min), formatScore(max)).Result()
+}
+
+// implement SortedSetCacheProvider.SortedSetCard .
+func (cli *RedisCacheProvider) SortedSetCard(key string) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("key must not be empty")
+	}
+
+	return cli.client.ZCard(context.Background(), key).Result()
+}
+
+// formatScore 将分数转换成 redis 接受的格式。
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+func sortedSetMembers(zs []redis.Z, err error) ([]SortedSetMember, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]SortedSetMember, len(zs))
+	for i, z := range zs {
+		res[i] = SortedSetMember{z.Member.(string), z.Score}
+	}
+	return res, nil
+}
+
+// stringsToAny 将字符串切片转换成 []any ，用于 go-redis 的可变参数。
+func stringsToAny(s []string) []any {
+	res := make([]any, len(s))
+	for i, v := range s {
+		res[i] = v
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ TagCacheProvider = (*RedisCacheProvider)(nil)
+
+// addTagScript 将 key 加入标签的集合，并延长集合的过期时间，使其不早于 key 过期。
+// 返回加入之前随机抽取的集合中的 key ，由调用方检查是否已经过期。
+// 被关联的 key 可能位于 Redis Cluster 的其他节点，不能在脚本中检查。
+//  ARGV[1]: key 的过期时长（毫秒），0 表不过期。
+//  ARGV[2]: key 。
+//  ARGV[3]: 抽取的 key 的个数。
+var addTagScript = redis.NewScript(`
+local sample = redis.call('SRANDMEMBER', KEYS[1], ARGV[3])
+local created = redis.call('EXISTS', KEYS[1]) == 0
+redis.call('SADD', KEYS[1], ARGV[2])
+local t = tonumber(ARGV[1])
+if created then
+	if t > 0 then
+		redis.call('PEXPIRE', KEYS[1], t)
+	end
+	return sample
+end
+
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	return sample
+end
+if t == 0 then
+	redis.call('PERSIST', KEYS[1])
+elseif ttl < t then
+	redis.call('PEXPIRE', KEYS[1], t)
+end
+return sample
+`)
+
+// tagScanCount InvalidateTag 每批读取的 key 的个数。
+const tagScanCount = 500
+
+// implement TagCacheProvider.AddTags .
+// 每个标签单独执行脚本，标签的集合与 key 可以位于 Redis Cluster 的不同节点。
+func (cli *RedisCacheProvider) AddTags(cacheNamespace, key string, tags []string, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	for _, tag := range tags {
+		tk, err := tagKey(cacheNamespace, tag)
+		if err != nil {
+			return err
+		}
+
+		res, err := addTagScript.Run(context.Background(), cli.client, []string{tk}, milliseconds(t), key, tagPruneCount).Result()
+		if err != nil && err != redis.Nil { // 抽取的 key 为空时脚本返回 nil 。
+			return err
+		}
+
+		var sample []string
+		if vs, ok := res.([]any); ok {
+			for _, v := range vs {
+				if s, ok := v.(string); ok {
+					sample = append(sample, s)
+				}
+			}
+		}
+
+		if err = cli.pruneTag(tk, sample, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneTag 从标签的集合中移除 sample 中已经过期的 key ，不检查刚刚关联的 key 。
+// 检查与移除之间重新写入并关联的 key 可能被移除，与 addTags 一样，这样的 key 不会被 InvalidateTag 移除。
+func (cli *RedisCacheProvider) pruneTag(tk string, sample []string, added string) error {
+	keys := make([]string, 0, len(sample))
+	for _, k := range sample {
+		if k != added {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	cmds := make([]*redis.IntCmd, len(keys))
+	err := cli.pipelined(ctx, len(keys), func(pipe redis.Cmdable) {
+		for i, k := range keys {
+			cmds[i] = pipe.Exists(ctx, k)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	var dead []any
+	for i, cmd := range cmds {
+		n, err := cmd.Result()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			dead = append(dead, keys[i])
+		}
+	}
+	if len(dead) == 0 {
+		return nil
+	}
+	return cli.client.SRem(ctx, tk, dead...).Err()
+}
+
+// implement TagCacheProvider.InvalidateTag .
+// 分批读取关联的 key ，先移除这些 key ，再从集合中移除，期间新关联的 key 保留在集合中，不会丢失。
+func (cli *RedisCacheProvider) InvalidateTag(cacheNamespace, tag string) (int64, error) {
+	tk, err := tagKey(cacheNamespace, tag)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	var n int64
+	var cursor uint64
+	for {
+		keys, next, err := cli.client.SScan(ctx, tk, cursor, "", tagScanCount).Result()
+		if err != nil {
+			return n, err
+		}
+
+		if len(keys) > 0 {
+			removed, err := cli.RemoveMulti(keys...)
+			if err != nil {
+				return n, err
+			}
+			n += removed
+
+			if err = cli.client.SRem(ctx, tk, stringsToAny(keys)...).Err(); err != nil {
+				return n, err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return n, nil
+		}
+	}
+}
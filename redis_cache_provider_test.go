@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"reflect"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -118,13 +119,13 @@ func TestNewRedisCacheProvider(t *testing.T) {
 		want    *RedisCacheProvider
 		wantErr bool
 	}{
-		{"Client", args{&redis.Client{}}, &RedisCacheProvider{&redis.Client{}}, false},
-		{"Ring client", args{&redis.Ring{}}, &RedisCacheProvider{&redis.Ring{}}, false},
-		{"ClusterClient client", args{&redis.ClusterClient{}}, &RedisCacheProvider{&redis.ClusterClient{}}, false},
-		{"Tx client", args{&redis.Tx{}}, &RedisCacheProvider{&redis.Tx{}}, false},
+		{"Client", args{&redis.Client{}}, &RedisCacheProvider{&redis.Client{}, JSONCodec}, false},
+		{"Ring client", args{&redis.Ring{}}, &RedisCacheProvider{&redis.Ring{}, JSONCodec}, false},
+		{"ClusterClient client", args{&redis.ClusterClient{}}, &RedisCacheProvider{&redis.ClusterClient{}, JSONCodec}, false},
+		{"Tx client", args{&redis.Tx{}}, &RedisCacheProvider{&redis.Tx{}, JSONCodec}, false},
 		{"nil value", args{nil}, nil, true},
 		// 哨兵客户端，不支持。
-		//{"client", args{&redis.SentinelClient{}}, &RedisCacheProvider{&redis.Client{}}},
+		//{"client", args{&redis.SentinelClient{}}, &RedisCacheProvider{&redis.Client{}, JSONCodec}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -411,7 +412,7 @@ func TestRedisCacheProvider_Remove(t *testing.T) {
 }
 
 // TestRedisCacheProvider_Increase
-// 因为是通过事务实现的，主要测试并发，事务的完整性
+// 因为是通过 lua 脚本实现的，主要测试并发下的原子性
 // 主要点：程序中的成功 必须等于 redis中的成功。
 func TestRedisCacheProvider_Increase(t *testing.T) {
 	p := getNewEveryTime()
@@ -444,6 +445,39 @@ func TestRedisCacheProvider_Increase(t *testing.T) {
 	if kv-startNumber-1 != successCount {
 		t.Errorf("kv: %d  !=   successCount: %d", kv, successCount)
 	}
+
+	// key 存在之后的调用都应该成功，不会因为竞争而失败。
+	if successCount < DoTimes-startNumber {
+		t.Errorf("successCount: %d < %d", successCount, DoTimes-startNumber)
+	}
+}
+
+func TestRedisCacheProvider_Increase_Script(t *testing.T) {
+	p := getNewEveryTime()
+	key := "key_" + fmt.Sprint(rand.Int31())
+	defer p.Remove(key)
+
+	if _, err := p.Increase(key); err == nil || !strings.HasPrefix(err.Error(), "cache key does not exist") {
+		t.Fatalf("Increase() error = %v", err)
+	}
+
+	p.Set(key, "not number", time.Minute)
+	if _, err := p.Increase(key); err == nil {
+		t.Fatalf("Increase() not number should fail")
+	}
+
+	// 脚本缓存被清空后，自动重新加载。
+	p.Set(key, 1, time.Minute)
+	p.client.ScriptFlush(context.Background())
+	if v, err := p.Increase(key); err != nil || v != 2 {
+		t.Fatalf("Increase() = %v, %v", v, err)
+	}
+
+	// 只实现了 redis.Cmdable ，不支持 WATCH 的包装类型。
+	wrapper := struct{ redis.Cmdable }{p.client}
+	if v, err := NewRedisCacheProvider(wrapper).Increase(key); err != nil || v != 3 {
+		t.Fatalf("Increase() = %v, %v", v, err)
+	}
 }
 
 func TestRedisCacheProvider_IncreaseOrCreate(t *testing.T) {
@@ -465,3 +499,207 @@ func TestRedisCacheProvider_IncreaseOrCreate(t *testing.T) {
 		t.Errorf("IncreaseOrCreate: number error, %d", after)
 	}
 }
+
+func TestRedisCacheProvider_WithCodec(t *testing.T) {
+	p := getNewEveryTime()
+	key := "key_" + fmt.Sprint(rand.Int31())
+	defer p.Remove(key)
+
+	for _, codec := range []Codec{GobCodec, MsgpackCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			cp := p.WithCodec(codec)
+			if err := cp.Set(key, data.Person, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			var person Person
+			if ok, err := cp.TryGet(key, &person); !ok || err != nil {
+				t.Fatalf("TryGet() = %v, %v", ok, err)
+			}
+			if person != data.Person {
+				t.Errorf("TryGet() = %v, want %v", person, data.Person)
+			}
+
+			// 默认的 json 无法解码。
+			if _, err := p.TryGet(key, &person); err == nil {
+				t.Errorf("TryGet() with json codec should fail")
+			}
+		})
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		// 其他语言写入的原始字符串。
+		p.client.Set(context.Background(), key, "written by java", time.Minute)
+
+		var s string
+		if ok, err := p.WithCodec(RawCodec).TryGet(key, &s); !ok || err != nil {
+			t.Fatalf("TryGet() = %v, %v", ok, err)
+		}
+		if s != "written by java" {
+			t.Errorf("TryGet() = %v", s)
+		}
+	})
+}
+
+// TestRedisCacheProvider_IncreaseOrCreate_TTL 只有新创建的 key 才设置过期时间。
+func TestRedisCacheProvider_IncreaseOrCreate_TTL(t *testing.T) {
+	p := getNewEveryTime()
+	ctx := context.Background()
+
+	t.Run("back_to_increment", func(t *testing.T) {
+		key := "key_" + fmt.Sprint(rand.Int31())
+		defer p.Remove(key)
+
+		p.IncreaseOrCreate(key, 5, time.Minute)
+		p.IncreaseOrCreate(key, -5, time.Hour)
+
+		// 增加后的值等于增量，但 key 不是新创建的，不能重置过期时间。
+		if v, err := p.IncreaseOrCreate(key, 5, time.Hour); err != nil || v != 5 {
+			t.Fatalf("IncreaseOrCreate() = %v, %v", v, err)
+		}
+		if ttl := p.client.PTTL(ctx, key).Val(); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("IncreaseOrCreate() ttl = %v, want <= 1m", ttl)
+		}
+	})
+
+	t.Run("exists_without_ttl", func(t *testing.T) {
+		key := "key_" + fmt.Sprint(rand.Int31())
+		defer p.Remove(key)
+
+		p.Set(key, 0, NoExpiration)
+		if v, err := p.IncreaseOrCreate(key, 3, time.Minute); err != nil || v != 3 {
+			t.Fatalf("IncreaseOrCreate() = %v, %v", v, err)
+		}
+		if ttl := p.client.PTTL(ctx, key).Val(); ttl > 0 {
+			t.Errorf("IncreaseOrCreate() ttl = %v, want no expiration", ttl)
+		}
+	})
+
+	t.Run("concurrent_create", func(t *testing.T) {
+		key := "key_" + fmt.Sprint(rand.Int31())
+		defer p.Remove(key)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.IncreaseOrCreate(key, 1, time.Minute)
+			}()
+		}
+		wg.Wait()
+
+		if ttl := p.client.PTTL(ctx, key).Val(); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("IncreaseOrCreate() ttl = %v, want <= 1m", ttl)
+		}
+		var v int
+		if p.Get(key, &v); v != 50 {
+			t.Errorf("IncreaseOrCreate() = %v, want 50", v)
+		}
+	})
+
+	t.Run("no_expiration", func(t *testing.T) {
+		key := "key_" + fmt.Sprint(rand.Int31())
+		defer p.Remove(key)
+
+		p.IncreaseOrCreate(key, 1, NoExpiration)
+		if ttl := p.client.PTTL(ctx, key).Val(); ttl > 0 {
+			t.Errorf("IncreaseOrCreate() ttl = %v, want no expiration", ttl)
+		}
+	})
+}
+
+func TestRedisCacheProvider_Multi(t *testing.T) {
+	p := getNewEveryTime()
+	keys := []string{"multi_a", "multi_not_exists", "multi_b"}
+	defer p.RemoveMulti(keys...)
+
+	p.Set("multi_a", 1, time.Minute)
+	p.Set("multi_b", data.Person, time.Minute)
+
+	var a int
+	var missing string
+	var b Person
+	got, err := p.TryGetMulti(keys, []any{&a, &missing, &b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("TryGetMulti() = %v", got)
+	}
+	if a != 1 || missing != "" || b != data.Person {
+		t.Errorf("TryGetMulti() values = %v, %v, %v", a, missing, b)
+	}
+
+	if _, err = p.TryGetMulti(keys, []any{&a}); err == nil {
+		t.Errorf("TryGetMulti() len(keys) != len(values) should fail")
+	}
+	if got, err := p.TryGetMulti(nil, nil); err != nil || len(got) != 0 {
+		t.Errorf("TryGetMulti() empty = %v, %v", got, err)
+	}
+
+	if n, err := p.RemoveMulti(keys...); err != nil || n != 2 {
+		t.Errorf("RemoveMulti() = %v, %v", n, err)
+	}
+	if n, err := p.RemoveMulti(); err != nil || n != 0 {
+		t.Errorf("RemoveMulti() = %v, %v", n, err)
+	}
+	if _, err := p.RemoveMulti(""); err == nil {
+		t.Errorf("RemoveMulti() empty key should fail")
+	}
+}
+
+func TestRedisCacheProvider_Multi_Ring(t *testing.T) {
+	ring := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"shard": "127.0.0.1:6379"}, MaxRetries: -1})
+	defer ring.Close()
+
+	// 不同的哈希标签分成多组，通过管道执行。
+	p := NewRedisCacheProvider(ring)
+	keys := []string{"{multi_ring_a}1", "{multi_ring_b}1", "{multi_ring_a}2"}
+	defer p.RemoveMulti(keys...)
+
+	for i, key := range keys {
+		p.Set(key, i, time.Minute)
+	}
+
+	values := make([]int, len(keys))
+	got, err := p.TryGetMulti(keys, []any{&values[0], &values[1], &values[2]})
+	if err != nil || !reflect.DeepEqual(got, []bool{true, true, true}) {
+		t.Fatalf("TryGetMulti() = %v, %v", got, err)
+	}
+	if !reflect.DeepEqual(values, []int{0, 1, 2}) {
+		t.Errorf("TryGetMulti() values = %v", values)
+	}
+
+	if n, err := p.RemoveMulti(keys...); err != nil || n != 3 {
+		t.Errorf("RemoveMulti() = %v, %v", n, err)
+	}
+}
+
+func TestRedisCacheProvider_Compare(t *testing.T) {
+	p := getNewEveryTime()
+	ctx := context.Background()
+	key := "compare_token"
+	defer p.Remove(key)
+
+	p.Set(key, "token_a", time.Minute)
+	if ok, err := p.CompareAndExpire(key, "token_b", time.Hour); ok || err != nil {
+		t.Errorf("CompareAndExpire() mismatch = %v, %v", ok, err)
+	}
+	if ok, err := p.CompareAndExpire(key, "token_a", time.Hour); !ok || err != nil {
+		t.Errorf("CompareAndExpire() = %v, %v", ok, err)
+	}
+	if ttl := p.client.PTTL(ctx, key).Val(); ttl <= time.Minute {
+		t.Errorf("ttl after CompareAndExpire() = %v, want about 1h", ttl)
+	}
+
+	if ok, err := p.CompareAndRemove(key, "token_b"); ok || err != nil {
+		t.Errorf("CompareAndRemove() mismatch = %v, %v", ok, err)
+	}
+	if ok, err := p.CompareAndRemove(key, "token_a"); !ok || err != nil {
+		t.Errorf("CompareAndRemove() = %v, %v", ok, err)
+	}
+	if ok, err := p.CompareAndRemove(key, "token_a"); ok || err != nil {
+		t.Errorf("CompareAndRemove() on missing key = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ UniqueCounterCacheProvider = (*RedisCacheProvider)(nil)
+
+// uniqueCounterAddScript 添加成员，只有计数器是新创建的才设置过期时间。
+//  ARGV[1]: 过期时长（毫秒），0 表不过期。
+//  ARGV[2...]: 成员。
+var uniqueCounterAddScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local changed = redis.call('PFADD', KEYS[1], unpack(ARGV, 2))
+if created and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return changed
+`)
+
+// uniqueCounterMergeScript 合并计数器，只有 KEYS[1] 是新创建的才设置过期时间。
+//  ARGV[1]: 过期时长（毫秒），0 表不过期。
+var uniqueCounterMergeScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+redis.call('PFMERGE', unpack(KEYS))
+if created and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// uniqueCounterStoreScript 写入在客户端合并的计数器，已存在的计数器保持原有的过期时间。
+//  ARGV[1]: 计数器的数据。
+//  ARGV[2]: 过期时长（毫秒），0 表不过期，只有计数器是新创建的才设置。
+var uniqueCounterStoreScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl == -2 then
+	ttl = tonumber(ARGV[2])
+end
+redis.call('SET', KEYS[1], ARGV[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// implement UniqueCounterCacheProvider.UniqueCounterAdd .
+func (cli *RedisCacheProvider) UniqueCounterAdd(key string, members []string, t time.Duration) (bool, error) {
+	if key == "" {
+		return false, fmt.Errorf("key must not be empty")
+	}
+
+	args := make([]any, 0, len(members)+1)
+	args = append(args, milliseconds(t))
+	args = append(args, stringsToAny(members)...)
+
+	changed, err := uniqueCounterAddScript.Run(context.Background(), cli.client, []string{key}, args...).Int64()
+	return changed == 1, err
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterCount .
+// 不在同一个哈希槽的计数器，读取后在客户端合并计数。
+func (cli *RedisCacheProvider) UniqueCounterCount(keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, fmt.Errorf("keys must not be empty")
+	}
+
+	if err := checkKeys(keys); err != nil {
+		return 0, err
+	}
+
+	if len(cli.splitKeys(keys)) == 1 {
+		return cli.client.PFCount(context.Background(), keys...).Result()
+	}
+
+	hs, err := cli.getHyperLogLogs(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	union := &hyperLogLog{}
+	for _, h := range hs {
+		if h != nil {
+			union.merge(h)
+		}
+	}
+	return union.count(), nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterMerge .
+// 不在同一个哈希槽的计数器，读取后在客户端合并再写入 dest，此时合并不是原子的。
+func (cli *RedisCacheProvider) UniqueCounterMerge(dest string, sources []string, t time.Duration) error {
+	keys := append([]string{dest}, sources...)
+	if err := checkKeys(keys); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if len(cli.splitKeys(keys)) == 1 {
+		return uniqueCounterMergeScript.Run(ctx, cli.client, keys, milliseconds(t)).Err()
+	}
+
+	hs, err := cli.getHyperLogLogs(keys)
+	if err != nil {
+		return err
+	}
+
+	merged := &hyperLogLog{}
+	for _, h := range hs {
+		if h != nil {
+			merged.merge(h)
+		}
+	}
+	return uniqueCounterStoreScript.Run(ctx, cli.client, []string{dest}, merged.marshal(), milliseconds(t)).Err()
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterExport .
+// redis 的 HyperLogLog 是字符串类型，直接读取，不经过编解码器。
+func (cli *RedisCacheProvider) UniqueCounterExport(key string) ([]byte, bool, error) {
+	if key == "" {
+		return nil, false, fmt.Errorf("key must not be empty")
+	}
+
+	data, err := cli.client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// implement UniqueCounterCacheProvider.UniqueCounterImport .
+func (cli *RedisCacheProvider) UniqueCounterImport(key string, data []byte, t time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	// 提前校验，避免写入 redis 无法识别的数据。
+	if _, err := unmarshalHyperLogLog(data); err != nil {
+		return err
+	}
+
+	return cli.client.Set(context.Background(), key, data, t).Err()
+}
+
+// getHyperLogLogs 读取并解析多个计数器，不存在的计数器为 nil 。
+func (cli *RedisCacheProvider) getHyperLogLogs(keys []string) ([]*hyperLogLog, error) {
+	ctx := context.Background()
+	cmds := make([]*redis.StringCmd, len(keys))
+	err := cli.pipelined(ctx, len(keys), func(pipe redis.Cmdable) {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hs := make([]*hyperLogLog, len(keys))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hs[i], err = unmarshalHyperLogLog(data); err != nil {
+			return nil, fmt.Errorf("key '%s': %w", keys[i], err)
+		}
+	}
+	return hs, nil
+}
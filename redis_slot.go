@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisSlotCount Redis Cluster 的哈希槽数量。
+const redisSlotCount = 16384
+
+// hashTag 获取 key 中参与计算哈希槽的部分：
+// 如果 key 中存在 {...} 且花括号内不为空，只有第一个花括号内的部分参与计算，反之使用整个 key 。
+func hashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return key
+	}
+
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 { // 没有 '}' 或者花括号内为空。
+		return key
+	}
+
+	return key[start+1 : start+1+end]
+}
+
+// hashSlot 计算 key 在 Redis Cluster 中的哈希槽。
+func hashSlot(key string) int {
+	return int(crc16(hashTag(key)) % redisSlotCount)
+}
+
+// crc16 CRC16-CCITT (XMODEM)，Redis Cluster 使用的哈希算法。
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// splitKeys 将多个 key 按照所在的节点分组，返回每组 key 在 keys 中的下标。
+// Cluster 按哈希槽分组，Ring 按哈希标签分组，其他客户端不需要分组。
+func (cli *RedisCacheProvider) splitKeys(keys []string) [][]int {
+	var groupOf func(key string) string
+	switch cli.client.(type) {
+	case *redis.ClusterClient:
+		groupOf = func(key string) string { return strconv.Itoa(hashSlot(key)) }
+	case *redis.Ring:
+		groupOf = hashTag
+	default:
+		all := make([]int, len(keys))
+		for i := range keys {
+			all[i] = i
+		}
+		return [][]int{all}
+	}
+
+	groups := make([][]int, 0)
+	index := make(map[string]int)
+	for i, key := range keys {
+		g := groupOf(key)
+		gi, ok := index[g]
+		if !ok {
+			gi = len(groups)
+			index[g] = gi
+			groups = append(groups, nil)
+		}
+		groups[gi] = append(groups[gi], i)
+	}
+	return groups
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func Test_hashSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		tag  string
+		slot int
+	}{
+		{"123456789", "123456789", 0x31C3 % redisSlotCount},
+		{"foo", "foo", 12182},
+		{"{user1000}.following", "user1000", hashSlot("user1000")},
+		{"{user1000}.followers", "user1000", hashSlot("user1000")},
+		{"foo{}{bar}", "foo{}{bar}", hashSlot("foo{}{bar}")},
+		{"foo{{bar}}zap", "{bar", hashSlot("{bar")},
+		{"foo{bar}{zap}", "bar", hashSlot("bar")},
+		{"{ns:prefix_a}_1", "ns:prefix_a", hashSlot("ns:prefix_a")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := hashTag(tt.key); got != tt.tag {
+				t.Errorf("hashTag() = %v, want %v", got, tt.tag)
+			}
+			if got := hashSlot(tt.key); got != tt.slot {
+				t.Errorf("hashSlot() = %v, want %v", got, tt.slot)
+			}
+		})
+	}
+}
+
+func TestRedisCacheProvider_splitKeys(t *testing.T) {
+	keys := []string{"{a}1", "{b}1", "{a}2", "c"}
+
+	tests := []struct {
+		name string
+		cli  redis.Cmdable
+		want [][]int
+	}{
+		{"client", &redis.Client{}, [][]int{{0, 1, 2, 3}}},
+		{"cluster", &redis.ClusterClient{}, [][]int{{0, 2}, {1}, {3}}},
+		{"ring", &redis.Ring{}, [][]int{{0, 2}, {1}, {3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRedisCacheProvider(tt.cli).splitKeys(keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
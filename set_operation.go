@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetCacheProvider 是支持集合结构的 CacheProvider ，成员是 encodeMember 的编码结果。
+type SetCacheProvider interface {
+	CacheProvider
+
+	// SetAdd 添加成员，集合不存在时创建。
+	//  @key: cache key.
+	//  @members: 成员。
+	//  @t: 过期时长， 0表不过期，只有集合是新创建的才设置。
+	// return: 新添加的成员个数，不包括已经存在的成员。
+	SetAdd(key string, members []string, t time.Duration) (int64, error)
+
+	// SetRemove 移除成员，所有成员都被移除后，集合也被移除。
+	// return: 成功移除的成员个数。
+	SetRemove(key string, members ...string) (int64, error)
+
+	// SetContains 判断成员是否存在。
+	SetContains(key, member string) (bool, error)
+
+	// SetMembers 获取所有成员，顺序不固定，集合不存在时返回空切片。
+	SetMembers(key string) ([]string, error)
+
+	// SetCard 获取成员个数，集合不存在时返回 0 。
+	SetCard(key string) (int64, error)
+
+	// SetInter 获取多个集合的交集，任意一个集合不存在时返回空切片。
+	SetInter(keys ...string) ([]string, error)
+
+	// SetUnion 获取多个集合的并集。
+	SetUnion(keys ...string) ([]string, error)
+}
+
+// SetOperation 集合缓存操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个集合。
+// 成员的基础类型转换成字符串存储，其他类型使用 JSON 编码，不受 Codec 影响。
+type SetOperation[T any] struct {
+	op Operation
+	p  SetCacheProvider
+}
+
+// NewSetOperation 创建一个集合缓存操作对象。
+// expireTime: 整个集合的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 SetCacheProvider 。
+func NewSetOperation[T any](
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *SetOperation[T] {
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(SetCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement SetCacheProvider", op.cacheProvider))
+	}
+
+	return &SetOperation[T]{*op, p}
+}
+
+// Key 获取指定集合的缓存操作对象。
+func (c *SetOperation[T]) Key(keys ...any) *SetKeyOperation[T] {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	key, err := c.op.buildCacheKey(keys...)
+	return &SetKeyOperation[T]{
+		owner: c,
+		p:     c.p,
+		exp:   c.op.expireTime,
+		Key:   key,
+		err:   err,
+	}
+}
+
+// Inter 获取多个集合的交集，集合必须由当前对象的 Key 方法创建。
+// 在 redis 集群中，不在同一个哈希槽的集合在客户端计算，可以通过 WithHashTag 使它们位于同一个哈希槽。
+func (c *SetOperation[T]) Inter(keyOps ...*SetKeyOperation[T]) ([]T, error) {
+	keys, err := c.keys(keyOps)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMembers[T](c.p.SetInter(keys...))
+}
+
+// MustInter 是 Inter 的 panic 版。
+func (c *SetOperation[T]) MustInter(keyOps ...*SetKeyOperation[T]) []T {
+	result, err := c.Inter(keyOps...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Union 获取多个集合的并集，集合必须由当前对象的 Key 方法创建。
+// 在 redis 集群中，不在同一个哈希槽的集合在客户端计算，可以通过 WithHashTag 使它们位于同一个哈希槽。
+func (c *SetOperation[T]) Union(keyOps ...*SetKeyOperation[T]) ([]T, error) {
+	keys, err := c.keys(keyOps)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMembers[T](c.p.SetUnion(keys...))
+}
+
+// MustUnion 是 Union 的 panic 版。
+func (c *SetOperation[T]) MustUnion(keyOps ...*SetKeyOperation[T]) []T {
+	result, err := c.Union(keyOps...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func (c *SetOperation[T]) keys(keyOps []*SetKeyOperation[T]) ([]string, error) {
+	if len(keyOps) == 0 {
+		return nil, fmt.Errorf("param 'keyOps' must not be empty")
+	}
+
+	keys := make([]string, len(keyOps))
+	for i, keyOp := range keyOps {
+		if keyOp.owner != c {
+			return nil, fmt.Errorf("key '%s' does not belong to this operation", keyOp.Key)
+		}
+		if keyOp.err != nil {
+			return nil, keyOp.err
+		}
+		keys[i] = keyOp.Key
+	}
+	return keys, nil
+}
+
+// SetKeyOperation 集合缓存 key 的操作对象。
+type SetKeyOperation[T any] struct {
+	owner *SetOperation[T]
+	p     SetCacheProvider
+	exp   *Expiration
+	err   error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// Add 添加成员，集合是新创建的时候，设置过期时间。
+//  return: 新添加的成员个数，不包括已经存在的成员。
+func (keyOp *SetKeyOperation[T]) Add(members ...T) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	ms, err := encodeMembers(members)
+	if err != nil {
+		return 0, err
+	}
+	return keyOp.p.SetAdd(keyOp.Key, ms, keyOp.exp.NextExpireTime())
+}
+
+// MustAdd 是 Add 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustAdd(members ...T) int64 {
+	result, err := keyOp.Add(members...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Remove 移除成员。
+//  return: 成功移除的成员个数。
+func (keyOp *SetKeyOperation[T]) Remove(members ...T) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	ms, err := encodeMembers(members)
+	if err != nil {
+		return 0, err
+	}
+	return keyOp.p.SetRemove(keyOp.Key, ms...)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustRemove(members ...T) int64 {
+	result, err := keyOp.Remove(members...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Contains 判断成员是否存在。
+func (keyOp *SetKeyOperation[T]) Contains(member T) (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	m, err := encodeMember(member)
+	if err != nil {
+		return false, err
+	}
+	return keyOp.p.SetContains(keyOp.Key, m)
+}
+
+// MustContains 是 Contains 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustContains(member T) bool {
+	result, err := keyOp.Contains(member)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Members 获取所有成员，顺序不固定，集合不存在时返回空切片。
+func (keyOp *SetKeyOperation[T]) Members() ([]T, error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	return decodeMembers[T](keyOp.p.SetMembers(keyOp.Key))
+}
+
+// MustMembers 是 Members 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustMembers() []T {
+	result, err := keyOp.Members()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Card 获取成员个数，集合不存在时返回 0 。
+func (keyOp *SetKeyOperation[T]) Card() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.SetCard(keyOp.Key)
+}
+
+// MustCard 是 Card 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustCard() int64 {
+	result, err := keyOp.Card()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RemoveAll 移除整个集合。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *SetKeyOperation[T]) RemoveAll() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemoveAll 是 RemoveAll 的 panic 版。
+func (keyOp *SetKeyOperation[T]) MustRemoveAll() bool {
+	result, err := keyOp.RemoveAll()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// decodeMembers 将 encodeMember 的编码结果批量解码。
+func decodeMembers[T any](members []string, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]T, len(members))
+	for i, m := range members {
+		if err = decodeMember(m, &res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
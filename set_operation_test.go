@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestSetOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			op := NewSetOperation[int]("ns", "set_"+fmt.Sprint(rand.Int31()), 1, p, NewExpiration(time.Minute, 0))
+			keyOp := op.Key("a")
+			defer keyOp.RemoveAll()
+
+			if got := keyOp.MustMembers(); len(got) != 0 {
+				t.Fatalf("Members() on missing set = %v, want empty", got)
+			}
+
+			if n := keyOp.MustAdd(1, 2, 3); n != 3 {
+				t.Errorf("Add() = %v, want 3", n)
+			}
+			if n := keyOp.MustAdd(3, 4); n != 1 {
+				t.Errorf("Add() = %v, want 1", n)
+			}
+			if !keyOp.MustContains(4) || keyOp.MustContains(5) {
+				t.Errorf("Contains() wrong")
+			}
+			if n := keyOp.MustCard(); n != 4 {
+				t.Errorf("Card() = %v, want 4", n)
+			}
+
+			got := keyOp.MustMembers()
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+				t.Errorf("Members() = %v", got)
+			}
+
+			if n := keyOp.MustRemove(1, 5); n != 1 {
+				t.Errorf("Remove() = %v, want 1", n)
+			}
+			keyOp.MustRemove(2, 3, 4)
+
+			// 所有成员都被移除后，集合也被移除。
+			if keyOp.MustRemoveAll() {
+				t.Errorf("RemoveAll() on empty set should return false")
+			}
+		})
+	}
+}
+
+func TestSetOperation_InterUnion(t *testing.T) {
+	providers := structureTestProviders()
+
+	ring := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"shard": "127.0.0.1:6379"}, MaxRetries: -1})
+	defer ring.Close()
+	providers["redis_ring"] = NewRedisCacheProvider(ring) // 没有哈希标签，分组后在客户端计算。
+
+	for name, p := range providers {
+		t.Run(name, func(t *testing.T) {
+			op := NewSetOperation[string]("ns", "set_"+fmt.Sprint(rand.Int31()), 1, p, nil)
+			a, b, c := op.Key("a"), op.Key("b"), op.Key("c")
+			defer a.RemoveAll()
+			defer b.RemoveAll()
+
+			a.MustAdd("x", "y", "z")
+			b.MustAdd("y", "z", "w")
+
+			sorted := func(s []string) []string {
+				sort.Strings(s)
+				return s
+			}
+
+			if got := sorted(op.MustInter(a, b)); !reflect.DeepEqual(got, []string{"y", "z"}) {
+				t.Errorf("Inter() = %v", got)
+			}
+			if got := sorted(op.MustUnion(a, b)); !reflect.DeepEqual(got, []string{"w", "x", "y", "z"}) {
+				t.Errorf("Union() = %v", got)
+			}
+			if got := op.MustInter(a, b, c); len(got) != 0 {
+				t.Errorf("Inter() with missing set = %v, want empty", got)
+			}
+			if got := sorted(op.MustUnion(a, c)); !reflect.DeepEqual(got, []string{"x", "y", "z"}) {
+				t.Errorf("Union() with missing set = %v", got)
+			}
+
+			if _, err := op.Inter(); err == nil {
+				t.Errorf("Inter() without keys should return error")
+			}
+			other := NewSetOperation[string]("ns", "other", 0, p, nil)
+			if _, err := op.Union(a, other.Key()); err == nil {
+				t.Errorf("Union() with key of another operation should return error")
+			}
+		})
+	}
+}
+
+func TestSetOperation_WrongType(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			op := NewSetOperation[int]("ns", "set_"+fmt.Sprint(rand.Int31()), 0, p, nil)
+			keyOp := op.Key()
+			defer p.Remove(keyOp.Key)
+			p.Set(keyOp.Key, 1, time.Minute)
+
+			if _, err := keyOp.Add(1); err == nil {
+				t.Errorf("Add() on non-set key should return error")
+			}
+			if _, err := op.Inter(keyOp); err == nil {
+				t.Errorf("Inter() on non-set key should return error")
+			}
+		})
+	}
+}
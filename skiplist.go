@@ -0,0 +1,237 @@
+package cache
+
+import "math/rand"
+
+const (
+	skipListMaxLevel = 32   // 跳表的最大层数，与 redis 一致。
+	skipListP        = 0.25 // 节点层数增加的概率。
+)
+
+// skipList 内存有序集合使用的跳表，按分数从小到大排序，分数相同时按成员的字典序排序。
+// 每层的指针记录跨越的节点数（span），用于按排名查找，实现参考 redis 的 zskiplist 。
+// 非线程安全，由调用方加锁。
+type skipList struct {
+	header *skipListNode
+	tail   *skipListNode
+	length int64
+	level  int
+}
+
+type skipListNode struct {
+	member   string
+	score    float64
+	backward *skipListNode
+	level    []skipListLevel
+}
+
+type skipListLevel struct {
+	forward *skipListNode
+	span    int64
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		header: &skipListNode{level: make([]skipListLevel, skipListMaxLevel)},
+		level:  1,
+	}
+}
+
+func skipListRandomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// less 节点 x 是否排在 (score, member) 之前。
+func (x *skipListNode) less(score float64, member string) bool {
+	return x.score < score || (x.score == score && x.member < member)
+}
+
+// insert 插入节点，调用方需要保证成员不存在。
+func (zsl *skipList) insert(score float64, member string) {
+	var update [skipListMaxLevel]*skipListNode
+	var rank [skipListMaxLevel]int64
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		if i != zsl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	level := skipListRandomLevel()
+	if level > zsl.level {
+		for i := zsl.level; i < level; i++ {
+			update[i] = zsl.header
+			update[i].level[i].span = zsl.length
+		}
+		zsl.level = level
+	}
+
+	x = &skipListNode{member: member, score: score, level: make([]skipListLevel, level)}
+	for i := 0; i < level; i++ {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+
+	// 更高层的指针跨过了新节点。
+	for i := level; i < zsl.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != zsl.header {
+		x.backward = update[0]
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		zsl.tail = x
+	}
+	zsl.length++
+}
+
+// delete 删除节点。
+// return: 节点存在返回 true 。
+func (zsl *skipList) delete(score float64, member string) bool {
+	var update [skipListMaxLevel]*skipListNode
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.level[0].forward
+	if x == nil || x.score != score || x.member != member {
+		return false
+	}
+
+	zsl.deleteNode(x, &update)
+	return true
+}
+
+// deleteRangeByScore 删除分数在 [min, max] 区间的节点。
+// return: 被删除的成员。
+func (zsl *skipList) deleteRangeByScore(min, max float64) []string {
+	var update [skipListMaxLevel]*skipListNode
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.score < min {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	var removed []string
+	x = x.level[0].forward
+	for x != nil && x.score <= max {
+		next := x.level[0].forward
+		zsl.deleteNode(x, &update)
+		removed = append(removed, x.member)
+		x = next
+	}
+	return removed
+}
+
+func (zsl *skipList) deleteNode(x *skipListNode, update *[skipListMaxLevel]*skipListNode) {
+	for i := 0; i < zsl.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		zsl.tail = x.backward
+	}
+
+	for zsl.level > 1 && zsl.header.level[zsl.level-1].forward == nil {
+		zsl.level--
+	}
+	zsl.length--
+}
+
+// rank 获取节点的排名，从 1 开始，节点不存在时返回 0 。
+func (zsl *skipList) rank(score float64, member string) int64 {
+	var rank int64
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil &&
+			(x.level[i].forward.less(score, member) ||
+				(x.level[i].forward.score == score && x.level[i].forward.member == member)) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if x != zsl.header && x.score == score && x.member == member {
+			return rank
+		}
+	}
+	return 0
+}
+
+// byRank 获取指定排名（从 1 开始）的节点，不存在时返回 nil 。
+func (zsl *skipList) byRank(rank int64) *skipListNode {
+	var traversed int64
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// firstInRange 获取分数在 [min, max] 区间的第一个节点，不存在时返回 nil 。
+func (zsl *skipList) firstInRange(min, max float64) *skipListNode {
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.score < min {
+			x = x.level[i].forward
+		}
+	}
+
+	x = x.level[0].forward
+	if x == nil || x.score > max {
+		return nil
+	}
+	return x
+}
+
+// lastInRange 获取分数在 [min, max] 区间的最后一个节点，不存在时返回 nil 。
+func (zsl *skipList) lastInRange(min, max float64) *skipListNode {
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.score <= max {
+			x = x.level[i].forward
+		}
+	}
+
+	if x == zsl.header || x.score < min {
+		return nil
+	}
+	return x
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestSkipList 随机插入、删除，与排序后的切片比较。
+func TestSkipList(t *testing.T) {
+	zsl := newSkipList()
+	dict := make(map[string]float64)
+
+	for i := 0; i < 2000; i++ {
+		member := fmt.Sprint(rand.Intn(300))
+		if score, ok := dict[member]; ok {
+			if !zsl.delete(score, member) {
+				t.Fatalf("delete(%v, %v) = false", score, member)
+			}
+			delete(dict, member)
+			continue
+		}
+
+		score := float64(rand.Intn(50))
+		zsl.insert(score, member)
+		dict[member] = score
+	}
+
+	want := make([]SortedSetMember, 0, len(dict))
+	for m, s := range dict {
+		want = append(want, SortedSetMember{m, s})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Score < want[j].Score || (want[i].Score == want[j].Score && want[i].Member < want[j].Member)
+	})
+
+	if zsl.length != int64(len(want)) {
+		t.Fatalf("length = %v, want %v", zsl.length, len(want))
+	}
+
+	for i, m := range want {
+		if rank := zsl.rank(m.Score, m.Member); rank != int64(i+1) {
+			t.Fatalf("rank(%v) = %v, want %v", m, rank, i+1)
+		}
+		if x := zsl.byRank(int64(i + 1)); x == nil || x.member != m.Member {
+			t.Fatalf("byRank(%v) = %v, want %v", i+1, x, m)
+		}
+	}
+
+	// 反向遍历。
+	i := len(want) - 1
+	for x := zsl.tail; x != nil; x = x.backward {
+		if x.member != want[i].Member {
+			t.Fatalf("backward[%v] = %v, want %v", i, x.member, want[i].Member)
+		}
+		i--
+	}
+
+	if x := zsl.firstInRange(10, 20); x != nil && x.score < 10 {
+		t.Errorf("firstInRange() = %v", x.score)
+	}
+	if x := zsl.lastInRange(10, 20); x != nil && x.score > 20 {
+		t.Errorf("lastInRange() = %v", x.score)
+	}
+
+	removed := zsl.deleteRangeByScore(10, 20)
+	for _, m := range want {
+		if (m.Score >= 10 && m.Score <= 20) != (zsl.rank(m.Score, m.Member) == 0) {
+			t.Fatalf("deleteRangeByScore() member %v", m)
+		}
+	}
+	if zsl.length+int64(len(removed)) != int64(len(want)) {
+		t.Errorf("deleteRangeByScore() length = %v, removed %v", zsl.length, len(removed))
+	}
+}
@@ -0,0 +1,429 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// SortedSetMember 有序集合的成员及其分数，成员是 encodeMember 的编码结果。
+type SortedSetMember struct {
+	Member string
+	Score  float64
+}
+
+// SortedSetCacheProvider 是支持有序集合的 CacheProvider ，用于排行榜等场景。
+// 成员按分数从小到大排序，分数相同时按成员的字典序排序，与 redis 一致。
+type SortedSetCacheProvider interface {
+	CacheProvider
+
+	// SortedSetAdd 添加成员，成员已经存在时更新其分数。
+	//  @key: cache key.
+	//  @members: 成员及其分数。
+	//  @t: 过期时长， 0表不过期，只有有序集合是新创建的才设置。
+	// return: 新添加的成员个数，不包括更新分数的成员。
+	SortedSetAdd(key string, members []SortedSetMember, t time.Duration) (int64, error)
+
+	// SortedSetIncrease 为成员的分数增加一个增量(负数==减法)，成员不存在时以增量为分数添加。
+	//  @t: 过期时长， 0表不过期，只有有序集合是新创建的才设置。
+	// return: 增加后的分数。
+	SortedSetIncrease(key, member string, increment float64, t time.Duration) (float64, error)
+
+	// SortedSetScore 获取成员的分数。
+	// return: 成员存在时返回分数和 true，反之返回 false。
+	SortedSetScore(key, member string) (float64, bool, error)
+
+	// SortedSetRank 获取成员的排名，从 0 开始。
+	//  @reverse: true 按分数从大到小排名。
+	// return: 成员存在时返回排名和 true，反之返回 false。
+	SortedSetRank(key, member string, reverse bool) (int64, bool, error)
+
+	// SortedSetRangeByRank 获取指定排名区间的成员，下标的含义与 redis 的 ZRANGE 一致，负数表示从末尾开始计算。
+	//  @start: 开始排名（包含）。
+	//  @stop: 结束排名（包含）。
+	//  @reverse: true 按分数从大到小排名。
+	SortedSetRangeByRank(key string, start, stop int64, reverse bool) ([]SortedSetMember, error)
+
+	// SortedSetRangeByScore 获取分数在 [min, max] 区间的成员，可以使用 math.Inf 表示不限制。
+	//  @offset: 跳过的成员个数，用于分页。
+	//  @count: 最多返回的成员个数，负数表示不限制。
+	//  @reverse: true 按分数从大到小返回。
+	SortedSetRangeByScore(key string, min, max float64, offset, count int64, reverse bool) ([]SortedSetMember, error)
+
+	// SortedSetRemove 移除成员，所有成员都被移除后，有序集合也被移除。
+	// return: 成功移除的成员个数。
+	SortedSetRemove(key string, members ...string) (int64, error)
+
+	// SortedSetRemoveByScore 移除分数在 [min, max] 区间的成员。
+	// return: 成功移除的成员个数。
+	SortedSetRemoveByScore(key string, min, max float64) (int64, error)
+
+	// SortedSetCard 获取成员个数，有序集合不存在时返回 0 。
+	SortedSetCard(key string) (int64, error)
+}
+
+// ScoredMember 有序集合的成员及其分数。
+type ScoredMember[T any] struct {
+	Member T
+	Score  float64
+}
+
+// SortedSetOperation 有序集合缓存操作对象。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个有序集合。
+// 成员的基础类型转换成字符串存储，其他类型使用 JSON 编码，不受 Codec 影响。
+type SortedSetOperation[TMember any] struct {
+	op Operation
+	p  SortedSetCacheProvider
+}
+
+// NewSortedSetOperation 创建一个有序集合缓存操作对象。
+// expireTime: 整个有序集合的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 SortedSetCacheProvider 。
+func NewSortedSetOperation[TMember any](
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *SortedSetOperation[TMember] {
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(SortedSetCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement SortedSetCacheProvider", op.cacheProvider))
+	}
+
+	return &SortedSetOperation[TMember]{*op, p}
+}
+
+// Key 获取指定有序集合的缓存操作对象。
+func (c *SortedSetOperation[TMember]) Key(keys ...any) *SortedSetKeyOperation[TMember] {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	key, err := c.op.buildCacheKey(keys...)
+	return &SortedSetKeyOperation[TMember]{
+		p:   c.p,
+		exp: c.op.expireTime,
+		Key: key,
+		err: err,
+	}
+}
+
+// SortedSetKeyOperation 有序集合缓存 key 的操作对象。
+type SortedSetKeyOperation[TMember any] struct {
+	p   SortedSetCacheProvider
+	exp *Expiration
+	err error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// Add 添加成员，成员已经存在时更新其分数，有序集合是新创建的时候，设置过期时间。
+//  return: true 表示新添加了成员，false 表示更新了已有成员的分数。
+func (keyOp *SortedSetKeyOperation[TMember]) Add(member TMember, score float64) (bool, error) {
+	n, err := keyOp.AddMulti(ScoredMember[TMember]{member, score})
+	return n > 0, err
+}
+
+// MustAdd 是 Add 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustAdd(member TMember, score float64) bool {
+	result, err := keyOp.Add(member, score)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// AddMulti 添加多个成员，成员已经存在时更新其分数，有序集合是新创建的时候，设置过期时间。
+//  return: 新添加的成员个数。
+func (keyOp *SortedSetKeyOperation[TMember]) AddMulti(members ...ScoredMember[TMember]) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	ms := make([]SortedSetMember, len(members))
+	for i, m := range members {
+		s, err := encodeMember(m.Member)
+		if err != nil {
+			return 0, err
+		}
+		ms[i] = SortedSetMember{s, m.Score}
+	}
+	return keyOp.p.SortedSetAdd(keyOp.Key, ms, keyOp.exp.NextExpireTime())
+}
+
+// MustAddMulti 是 AddMulti 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustAddMulti(members ...ScoredMember[TMember]) int64 {
+	result, err := keyOp.AddMulti(members...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// IncreaseScore 为成员的分数增加一个增量(负数==减法)，成员不存在时以增量为分数添加。
+//  return: 增加后的分数。
+func (keyOp *SortedSetKeyOperation[TMember]) IncreaseScore(member TMember, increment float64) (float64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	s, err := encodeMember(member)
+	if err != nil {
+		return 0, err
+	}
+	return keyOp.p.SortedSetIncrease(keyOp.Key, s, increment, keyOp.exp.NextExpireTime())
+}
+
+// MustIncreaseScore 是 IncreaseScore 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustIncreaseScore(member TMember, increment float64) float64 {
+	result, err := keyOp.IncreaseScore(member, increment)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Score 获取成员的分数。
+// 若成员存在，返回分数和 true，反之返回 false。
+func (keyOp *SortedSetKeyOperation[TMember]) Score(member TMember) (float64, bool, error) {
+	if keyOp.err != nil {
+		return 0, false, keyOp.err
+	}
+
+	s, err := encodeMember(member)
+	if err != nil {
+		return 0, false, err
+	}
+	return keyOp.p.SortedSetScore(keyOp.Key, s)
+}
+
+// MustScore 是 Score 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustScore(member TMember) (float64, bool) {
+	score, result, err := keyOp.Score(member)
+	if err != nil {
+		panic(err)
+	}
+	return score, result
+}
+
+// Rank 获取成员按分数从小到大的排名，从 0 开始。
+// 若成员存在，返回排名和 true，反之返回 false。
+func (keyOp *SortedSetKeyOperation[TMember]) Rank(member TMember) (int64, bool, error) {
+	return keyOp.rank(member, false)
+}
+
+// MustRank 是 Rank 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRank(member TMember) (int64, bool) {
+	rank, result, err := keyOp.Rank(member)
+	if err != nil {
+		panic(err)
+	}
+	return rank, result
+}
+
+// RevRank 获取成员按分数从大到小的排名，从 0 开始，排行榜通常使用这个排名。
+// 若成员存在，返回排名和 true，反之返回 false。
+func (keyOp *SortedSetKeyOperation[TMember]) RevRank(member TMember) (int64, bool, error) {
+	return keyOp.rank(member, true)
+}
+
+// MustRevRank 是 RevRank 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRevRank(member TMember) (int64, bool) {
+	rank, result, err := keyOp.RevRank(member)
+	if err != nil {
+		panic(err)
+	}
+	return rank, result
+}
+
+// RangeByRank 获取按分数从小到大排名在 [start, stop] 区间的成员，负数表示从末尾开始计算。
+func (keyOp *SortedSetKeyOperation[TMember]) RangeByRank(start, stop int64) ([]ScoredMember[TMember], error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	return keyOp.decode(keyOp.p.SortedSetRangeByRank(keyOp.Key, start, stop, false))
+}
+
+// MustRangeByRank 是 RangeByRank 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRangeByRank(start, stop int64) []ScoredMember[TMember] {
+	result, err := keyOp.RangeByRank(start, stop)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RevRangeByRank 获取按分数从大到小排名在 [start, stop] 区间的成员，负数表示从末尾开始计算。
+// 例如 RevRangeByRank(0, 9) 获取排行榜的前 10 名。
+func (keyOp *SortedSetKeyOperation[TMember]) RevRangeByRank(start, stop int64) ([]ScoredMember[TMember], error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	return keyOp.decode(keyOp.p.SortedSetRangeByRank(keyOp.Key, start, stop, true))
+}
+
+// MustRevRangeByRank 是 RevRangeByRank 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRevRangeByRank(start, stop int64) []ScoredMember[TMember] {
+	result, err := keyOp.RevRangeByRank(start, stop)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RangeByScore 按分数从小到大获取分数在 [min, max] 区间的成员。
+//  @offset: 跳过的成员个数，用于分页。
+//  @count: 最多返回的成员个数，负数表示不限制。
+func (keyOp *SortedSetKeyOperation[TMember]) RangeByScore(min, max float64, offset, count int64) ([]ScoredMember[TMember], error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("'offset' must not be less than 0")
+	}
+	return keyOp.decode(keyOp.p.SortedSetRangeByScore(keyOp.Key, min, max, offset, count, false))
+}
+
+// MustRangeByScore 是 RangeByScore 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRangeByScore(min, max float64, offset, count int64) []ScoredMember[TMember] {
+	result, err := keyOp.RangeByScore(min, max, offset, count)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RevRangeByScore 按分数从大到小获取分数在 [min, max] 区间的成员。
+//  @offset: 跳过的成员个数，用于分页。
+//  @count: 最多返回的成员个数，负数表示不限制。
+func (keyOp *SortedSetKeyOperation[TMember]) RevRangeByScore(max, min float64, offset, count int64) ([]ScoredMember[TMember], error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("'offset' must not be less than 0")
+	}
+	return keyOp.decode(keyOp.p.SortedSetRangeByScore(keyOp.Key, min, max, offset, count, true))
+}
+
+// MustRevRangeByScore 是 RevRangeByScore 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRevRangeByScore(max, min float64, offset, count int64) []ScoredMember[TMember] {
+	result, err := keyOp.RevRangeByScore(max, min, offset, count)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Remove 移除成员。
+//  return: 成功移除的成员个数。
+func (keyOp *SortedSetKeyOperation[TMember]) Remove(members ...TMember) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	ms, err := encodeMembers(members)
+	if err != nil {
+		return 0, err
+	}
+	return keyOp.p.SortedSetRemove(keyOp.Key, ms...)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRemove(members ...TMember) int64 {
+	result, err := keyOp.Remove(members...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RemoveByScore 移除分数在 [min, max] 区间的成员。
+//  return: 成功移除的成员个数。
+func (keyOp *SortedSetKeyOperation[TMember]) RemoveByScore(min, max float64) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.SortedSetRemoveByScore(keyOp.Key, min, max)
+}
+
+// MustRemoveByScore 是 RemoveByScore 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRemoveByScore(min, max float64) int64 {
+	result, err := keyOp.RemoveByScore(min, max)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Card 获取成员个数，有序集合不存在时返回 0 。
+func (keyOp *SortedSetKeyOperation[TMember]) Card() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.SortedSetCard(keyOp.Key)
+}
+
+// MustCard 是 Card 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustCard() int64 {
+	result, err := keyOp.Card()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RemoveAll 移除整个有序集合。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *SortedSetKeyOperation[TMember]) RemoveAll() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemoveAll 是 RemoveAll 的 panic 版。
+func (keyOp *SortedSetKeyOperation[TMember]) MustRemoveAll() bool {
+	result, err := keyOp.RemoveAll()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func (keyOp *SortedSetKeyOperation[TMember]) rank(member TMember, reverse bool) (int64, bool, error) {
+	if keyOp.err != nil {
+		return 0, false, keyOp.err
+	}
+
+	s, err := encodeMember(member)
+	if err != nil {
+		return 0, false, err
+	}
+	return keyOp.p.SortedSetRank(keyOp.Key, s, reverse)
+}
+
+func (keyOp *SortedSetKeyOperation[TMember]) decode(members []SortedSetMember, err error) ([]ScoredMember[TMember], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ScoredMember[TMember], len(members))
+	for i, m := range members {
+		if err = decodeMember(m.Member, &res[i].Member); err != nil {
+			return nil, err
+		}
+		res[i].Score = m.Score
+	}
+	return res, nil
+}
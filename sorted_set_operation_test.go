@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortedSetOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			op := NewSortedSetOperation[string]("ns", "zset_"+fmt.Sprint(rand.Int31()), 1, p, NewExpiration(time.Minute, 0))
+			keyOp := op.Key(1)
+			defer keyOp.RemoveAll()
+
+			if n := keyOp.MustCard(); n != 0 {
+				t.Fatalf("Card() on missing set = %v, want 0", n)
+			}
+
+			n := keyOp.MustAddMulti(
+				ScoredMember[string]{"a", 10},
+				ScoredMember[string]{"b", 20},
+				ScoredMember[string]{"c", 30},
+				ScoredMember[string]{"d", 20},
+			)
+			if n != 4 {
+				t.Errorf("AddMulti() = %v, want 4", n)
+			}
+			if keyOp.MustAdd("a", 5) {
+				t.Errorf("Add() existing member should return false")
+			}
+			if v := keyOp.MustIncreaseScore("a", 35); v != 40 {
+				t.Errorf("IncreaseScore() = %v, want 40", v)
+			}
+			if v := keyOp.MustIncreaseScore("e", 1.5); v != 1.5 {
+				t.Errorf("IncreaseScore() = %v, want 1.5", v)
+			}
+
+			// e:1.5 b:20 d:20 c:30 a:40
+			if score, ok := keyOp.MustScore("d"); !ok || score != 20 {
+				t.Errorf("Score() = %v, %v", score, ok)
+			}
+			if _, ok := keyOp.MustScore("x"); ok {
+				t.Errorf("Score() on missing member should return false")
+			}
+			if rank, ok := keyOp.MustRank("d"); !ok || rank != 2 {
+				t.Errorf("Rank() = %v, %v, want 2", rank, ok)
+			}
+			if rank, ok := keyOp.MustRevRank("a"); !ok || rank != 0 {
+				t.Errorf("RevRank() = %v, %v, want 0", rank, ok)
+			}
+			if _, ok := keyOp.MustRank("x"); ok {
+				t.Errorf("Rank() on missing member should return false")
+			}
+
+			want := []ScoredMember[string]{{"a", 40}, {"c", 30}, {"d", 20}}
+			if got := keyOp.MustRevRangeByRank(0, 2); !reflect.DeepEqual(got, want) {
+				t.Errorf("RevRangeByRank() = %v, want %v", got, want)
+			}
+			want = []ScoredMember[string]{{"c", 30}, {"a", 40}}
+			if got := keyOp.MustRangeByRank(-2, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("RangeByRank() = %v, want %v", got, want)
+			}
+			if got := keyOp.MustRangeByRank(10, 20); len(got) != 0 {
+				t.Errorf("RangeByRank() out of range = %v, want empty", got)
+			}
+
+			want = []ScoredMember[string]{{"d", 20}, {"c", 30}}
+			if got := keyOp.MustRangeByScore(20, 35, 1, 10); !reflect.DeepEqual(got, want) {
+				t.Errorf("RangeByScore() = %v, want %v", got, want)
+			}
+			want = []ScoredMember[string]{{"a", 40}, {"c", 30}}
+			if got := keyOp.MustRevRangeByScore(math.Inf(1), 2, 0, 2); !reflect.DeepEqual(got, want) {
+				t.Errorf("RevRangeByScore() = %v, want %v", got, want)
+			}
+			if got := keyOp.MustRangeByScore(math.Inf(-1), math.Inf(1), 0, -1); len(got) != 5 {
+				t.Errorf("RangeByScore() all = %v, want 5 members", got)
+			}
+			if got := keyOp.MustRangeByScore(math.Inf(-1), math.Inf(1), 0, 0); len(got) != 0 {
+				t.Errorf("RangeByScore() count 0 = %v, want empty", got)
+			}
+			if _, err := keyOp.RangeByScore(0, 1, -1, 1); err == nil {
+				t.Errorf("RangeByScore() negative offset should return error")
+			}
+
+			if n := keyOp.MustRemoveByScore(20, 30); n != 3 {
+				t.Errorf("RemoveByScore() = %v, want 3", n)
+			}
+			if n := keyOp.MustRemove("a", "x"); n != 1 {
+				t.Errorf("Remove() = %v, want 1", n)
+			}
+			if n := keyOp.MustCard(); n != 1 {
+				t.Errorf("Card() = %v, want 1", n)
+			}
+			keyOp.MustRemove("e")
+
+			// 所有成员都被移除后，有序集合也被移除。
+			if keyOp.MustRemoveAll() {
+				t.Errorf("RemoveAll() on empty set should return false")
+			}
+		})
+	}
+}
+
+func TestSortedSetOperation_StructMember(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewSortedSetOperation[Person]("ns", "zset_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer keyOp.RemoveAll()
+
+			keyOp.MustAdd(Person{"Tom", 1}, 1)
+			keyOp.MustAdd(Person{"Jerry", 2}, 2)
+			keyOp.MustIncreaseScore(Person{"Tom", 1}, 2)
+
+			want := []ScoredMember[Person]{{Person{"Tom", 1}, 3}, {Person{"Jerry", 2}, 2}}
+			if got := keyOp.MustRevRangeByRank(0, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("RevRangeByRank() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSortedSetOperation_WrongType(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			keyOp := NewSortedSetOperation[int]("ns", "zset_"+fmt.Sprint(rand.Int31()), 0, p, nil).Key()
+			defer p.Remove(keyOp.Key)
+			p.Set(keyOp.Key, 1, time.Minute)
+
+			if _, err := keyOp.Add(1, 1); err == nil {
+				t.Errorf("Add() on non-zset key should return error")
+			}
+			if _, err := keyOp.RangeByRank(0, -1); err == nil {
+				t.Errorf("RangeByRank() on non-zset key should return error")
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package sqlcache
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeDriver 测试使用的驱动，每个 DSN 对应一个内存中的 users 表。
+// 支持的语句：
+//  SELECT ... FROM users WHERE id >= ?
+//  INSERT INTO users ... (id, name)
+type fakeDriver struct{}
+
+func init() {
+	sql.Register("sqlcache_fake", fakeDriver{})
+}
+
+var (
+	fakeMu  sync.Mutex
+	fakeDBs = map[string]*fakeDB{}
+)
+
+type fakeUser struct {
+	id      int64
+	name    string
+	score   float64
+	active  bool
+	created time.Time
+	avatar  []byte
+}
+
+type fakeDB struct {
+	users   []fakeUser
+	queries int
+}
+
+// openFake 打开一个新的测试数据库。
+func openFake(dsn string, users ...fakeUser) (*sql.DB, *fakeDB) {
+	fakeMu.Lock()
+	fdb := &fakeDB{users: users}
+	fakeDBs[dsn] = fdb
+	fakeMu.Unlock()
+
+	db, _ := sql.Open("sqlcache_fake", dsn)
+	return db, fdb
+}
+
+// queryCount 获取执行过的查询次数。
+func (db *fakeDB) queryCount() int {
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	return db.queries
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+
+	db, ok := fakeDBs[dsn]
+	if !ok {
+		return nil, errors.New("unknown dsn")
+	}
+	return &fakeConn{db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.db, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "INSERT INTO users") || len(args) != 2 {
+		return nil, errors.New("unsupported statement: " + s.query)
+	}
+
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	s.db.users = append(s.db.users, fakeUser{id: args[0].(int64), name: args[1].(string)})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasSuffix(s.query, "FROM users WHERE id >= ?") || len(args) != 1 {
+		return nil, errors.New("unsupported statement: " + s.query)
+	}
+
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	s.db.queries++
+
+	rows := &fakeRows{}
+	for _, u := range s.db.users {
+		if u.id >= args[0].(int64) {
+			var created driver.Value
+			if !u.created.IsZero() {
+				created = u.created
+			}
+			rows.rows = append(rows.rows, []driver.Value{u.id, u.name, u.score, u.active, created, u.avatar})
+		}
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "name", "score", "active", "created", "avatar"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,294 @@
+package sqlcache
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// value 缓存的一个值，记录驱动返回的值的类型，经过 JSON 等编解码器之后仍然可以还原。
+type value struct {
+	Kind  byte // 'n' NULL, 'i' int64, 'f' float64, 'b' bool, 'y' []byte, 's' string, 't' time.Time
+	Int   int64     `json:",omitempty"`
+	Float float64   `json:",omitempty"`
+	Bytes []byte    `json:",omitempty"`
+	Str   string    `json:",omitempty"`
+	Time  time.Time `json:",omitempty"`
+}
+
+// newValue 转换驱动返回的值。
+func newValue(v any) (value, error) {
+	switch x := v.(type) {
+	case nil:
+		return value{Kind: 'n'}, nil
+	case int64:
+		return value{Kind: 'i', Int: x}, nil
+	case float64:
+		return value{Kind: 'f', Float: x}, nil
+	case bool:
+		if x {
+			return value{Kind: 'b', Int: 1}, nil
+		}
+		return value{Kind: 'b'}, nil
+	case []byte:
+		return value{Kind: 'y', Bytes: x}, nil
+	case string:
+		return value{Kind: 's', Str: x}, nil
+	case time.Time:
+		return value{Kind: 't', Time: x}, nil
+	default:
+		return value{}, fmt.Errorf("unsupported column value type %T", v)
+	}
+}
+
+// driverValue 还原驱动返回的值。
+func (v value) driverValue() driver.Value {
+	switch v.Kind {
+	case 'i':
+		return v.Int
+	case 'f':
+		return v.Float
+	case 'b':
+		return v.Int == 1
+	case 'y':
+		if v.Bytes == nil {
+			return []byte{}
+		}
+		return v.Bytes
+	case 's':
+		return v.Str
+	case 't':
+		return v.Time
+	default:
+		return nil
+	}
+}
+
+// result 缓存的查询结果。
+type result struct {
+	Columns []string
+	Rows    [][]value
+}
+
+// capture 读取全部的行并关闭 rows 。
+func capture(rows *sql.Rows) (result, error) {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return result{}, err
+	}
+
+	res := result{Columns: cols}
+	raw := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return result{}, err
+		}
+
+		row := make([]value, len(cols))
+		for i, v := range raw {
+			if row[i], err = newValue(v); err != nil {
+				return result{}, err
+			}
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
+
+// Rows 查询结果的迭代器，用法与 *sql.Rows 相同。
+type Rows struct {
+	res    result
+	i      int // 下一行的下标，当前行是 i-1 。
+	closed bool
+}
+
+// Columns 获取列名。
+func (r *Rows) Columns() ([]string, error) {
+	if r.closed {
+		return nil, errors.New("sqlcache: Rows are closed")
+	}
+	return r.res.Columns, nil
+}
+
+// Next 移动到下一行，没有更多的行时返回 false 并关闭 Rows 。
+func (r *Rows) Next() bool {
+	if r.closed || r.i >= len(r.res.Rows) {
+		r.closed = true
+		return false
+	}
+	r.i++
+	return true
+}
+
+// Scan 将当前行的各列赋值给 dest ，支持 *sql.Rows.Scan 常用的目标类型和 sql.Scanner 。
+func (r *Rows) Scan(dest ...any) error {
+	if r.closed || r.i == 0 {
+		return errors.New("sqlcache: Scan called without calling Next")
+	}
+
+	row := r.res.Rows[r.i-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("sqlcache: expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+
+	for i, v := range row {
+		if err := convertAssign(dest[i], v.driverValue()); err != nil {
+			return fmt.Errorf("sqlcache: Scan error on column index %d, name %q: %w", i, r.res.Columns[i], err)
+		}
+	}
+	return nil
+}
+
+// Err 迭代过程中的错误，缓存的结果已经完整读取，总是返回 nil 。
+func (r *Rows) Err() error {
+	return nil
+}
+
+// Close 关闭 Rows 。
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// convertAssign 将驱动返回的值赋值给 dest 。
+func convertAssign(dest any, src driver.Value) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	switch d := dest.(type) {
+	case *any:
+		if b, ok := src.([]byte); ok {
+			src = cloneBytes(b)
+		}
+		*d = src
+		return nil
+	case *[]byte:
+		switch s := src.(type) {
+		case nil:
+			*d = nil
+			return nil
+		case []byte:
+			*d = cloneBytes(s)
+			return nil
+		case string:
+			*d = []byte(s)
+			return nil
+		}
+	case *sql.RawBytes:
+		if b, ok := src.([]byte); ok {
+			*d = append((*d)[:0], b...)
+			return nil
+		}
+	case *time.Time:
+		if t, ok := src.(time.Time); ok {
+			*d = t
+			return nil
+		}
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return errors.New("destination not a pointer")
+	}
+	dv = dv.Elem()
+
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.Set(reflect.Zero(dv.Type()))
+			return nil
+		}
+		return fmt.Errorf("converting NULL to %s is unsupported", dv.Kind())
+	}
+
+	// 指针目标，例如 **int ，分配后递归赋值。
+	if dv.Kind() == reflect.Pointer {
+		p := reflect.New(dv.Type().Elem())
+		if err := convertAssign(p.Interface(), src); err != nil {
+			return err
+		}
+		dv.Set(p)
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	s := asString(src)
+	switch dv.Kind() {
+	case reflect.String:
+		dv.SetString(s)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("converting %T %q to %s: %w", src, s, dv.Kind(), err)
+		}
+		dv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("converting %T %q to %s: %w", src, s, dv.Kind(), err)
+		}
+		dv.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, dv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("converting %T %q to %s: %w", src, s, dv.Kind(), err)
+		}
+		dv.SetFloat(n)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("converting %T %q to %s: %w", src, s, dv.Kind(), err)
+		}
+		dv.SetBool(b)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported Scan, storing driver.Value type %T into type %T", src, dest)
+}
+
+// cloneBytes 复制 b ，与 database/sql 一致，空的 []byte 复制后不是 nil 。
+func cloneBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// asString 将驱动返回的值转换成字符串。
+func asString(src driver.Value) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(src)
+}
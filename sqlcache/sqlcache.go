@@ -0,0 +1,184 @@
+// Package sqlcache 提供 database/sql 查询结果的缓存。
+//
+// 只有通过 Cache.Query 执行的查询才会被缓存，查询的结果按 SQL 文本和参数缓存，保存列名和每一行的值，
+// 命中缓存时返回与 *sql.Rows 用法相同的 Rows 。
+// 每个查询声明其读取的表，每张表有一个版本号，版本号是缓存 key 的一部分；
+// 通过 Cache.Exec 执行的写操作，或者调用 Cache.Invalidate ，使相关表的版本号加 1 ，旧的查询结果随之失效，在过期后被移除。
+package sqlcache
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thisXYH/cache"
+)
+
+// Querier 执行查询，*sql.DB 、*sql.Tx 、*sql.Conn 都实现了该接口。
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Execer 执行写操作，*sql.DB 、*sql.Tx 、*sql.Conn 都实现了该接口。
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Cache 查询结果的缓存。
+// 查询结果的缓存key为 <CacheNamespace>:<Prefix>_<SQL 和参数的摘要>_<表的版本号> ，
+// 表的版本号的缓存key为 <CacheNamespace>:<Prefix>#table_<table> ，不会过期。
+type Cache struct {
+	op      *cache.Operation
+	tableOp *cache.Operation
+}
+
+// NewCache 创建一个查询结果的缓存。
+//  @cacheProvider: 需要支持 IncreaseOrCreate ，用于表的版本号。
+//  @expireTime: 查询结果的过期时长。
+func NewCache(
+	cacheNamespace, keyPrefix string,
+	cacheProvider cache.CacheProvider,
+	expireTime *cache.Expiration,
+	opts ...cache.OperationOption,
+) *Cache {
+	return &Cache{
+		op:      cache.NewOperation(cacheNamespace, keyPrefix, 2, cacheProvider, expireTime, opts...),
+		tableOp: cache.NewOperation(cacheNamespace, keyPrefix+"#table", 1, cacheProvider, nil, opts...),
+	}
+}
+
+// Query 执行查询并缓存结果，缓存存在时直接返回缓存的结果。
+// 结果会被完整读取到内存中，只应当用于结果集较小的查询。
+// 缓存出错时视为没有缓存，直接查询数据库；写入缓存失败时仍然返回查询的结果。
+//  @tables: 查询读取的表，其中任意一张表被 Exec 或者 Invalidate 之后，缓存的结果失效。
+func (c *Cache) Query(ctx context.Context, q Querier, tables []string, query string, args ...any) (*Rows, error) {
+	keyOp, err := c.key(tables, query, args)
+	if err == nil {
+		var res result
+		if found, err := keyOp.TryGet(&res); err == nil && found {
+			return &Rows{res: res}, nil
+		}
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := capture(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// 读取表的版本号失败时不写入缓存，否则结果可能被写入旧版本号的 key 。
+	if keyOp != nil {
+		keyOp.Set(&res)
+	}
+	return &Rows{res: res}, nil
+}
+
+// Exec 执行写操作，成功后使 tables 的缓存失效。
+// 在事务中执行时，提交之前其他连接可能把旧的数据缓存到新的版本号下，应当在 Commit 之后再调用一次 Invalidate 。
+func (c *Cache) Exec(ctx context.Context, e Execer, tables []string, query string, args ...any) (sql.Result, error) {
+	res, err := e.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.Invalidate(tables...); err != nil {
+		return res, fmt.Errorf("invalidate tables %v: %w", tables, err)
+	}
+	return res, nil
+}
+
+// Invalidate 使读取 tables 的查询的缓存失效。
+func (c *Cache) Invalidate(tables ...string) error {
+	for _, table := range tables {
+		if _, err := c.tableOp.Key(table).IncreaseOrCreate(1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key 获取查询结果的缓存 key ，先读取表的版本号，再执行查询，写操作之后的查询总是使用新的版本号。
+func (c *Cache) key(tables []string, query string, args []any) (*cache.KeyOperation, error) {
+	tables = normalizeTables(tables)
+
+	versions := make([]string, len(tables))
+	for i, table := range tables {
+		// 增加 0 用于读取版本号，与 IncreaseOrCreate 写入的值一致，不受编解码器的影响，版本号不存在时创建为 0 。
+		v, err := c.tableOp.Key(table).IncreaseOrCreate(0)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = strconv.FormatInt(v, 10)
+	}
+
+	digest, err := digest(tables, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.op.Key(digest, strings.Join(versions, ".")), nil
+}
+
+// normalizeTables 去重并排序。
+func normalizeTables(tables []string) []string {
+	set := make(map[string]bool, len(tables))
+	res := make([]string, 0, len(tables))
+	for _, t := range tables {
+		if !set[t] {
+			set[t] = true
+			res = append(res, t)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// digest 计算表、 SQL 文本和参数的摘要。
+func digest(tables []string, query string, args []any) (string, error) {
+	h := sha1.New()
+	fmt.Fprintf(h, "%q\n%q\n", tables, query)
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			fmt.Fprintf(h, "@%s=", named.Name)
+			arg = named.Value
+		}
+
+		if valuer, ok := arg.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return "", err
+			}
+			arg = v
+		}
+
+		// 与 database/sql 一致，指针参数使用其指向的值。
+		for rv := reflect.ValueOf(arg); rv.Kind() == reflect.Pointer; rv = rv.Elem() {
+			if rv.IsNil() {
+				arg = nil
+				break
+			}
+			arg = rv.Elem().Interface()
+		}
+
+		switch v := arg.(type) {
+		case time.Time:
+			fmt.Fprintf(h, "time.Time:%s\n", v.Format(time.RFC3339Nano))
+		case []byte:
+			fmt.Fprintf(h, "[]byte:%x\n", v)
+		default:
+			fmt.Fprintf(h, "%T:%#v\n", v, v)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,224 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thisXYH/cache"
+	"github.com/thisXYH/cache/internal/cachetest"
+)
+
+const selectUsers = "SELECT id, name, score, active, created, avatar FROM users WHERE id >= ?"
+
+type user struct {
+	ID      int
+	Name    string
+	Score   float64
+	Active  bool
+	Created *time.Time
+	Avatar  []byte
+}
+
+// scanUsers 读取全部的用户， rows 可以是 *sql.Rows 或者 *Rows 。
+func scanUsers(t *testing.T, rows interface {
+	Next() bool
+	Scan(...any) error
+	Close() error
+}) []user {
+	t.Helper()
+	defer rows.Close()
+
+	var users []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.ID, &u.Name, &u.Score, &u.Active, &u.Created, &u.Avatar); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		users = append(users, u)
+	}
+	return users
+}
+
+func TestCache(t *testing.T) {
+	created := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := openFake(name, fakeUser{1, "a", 9.5, true, created, []byte{1, 2}}, fakeUser{2, "b", 0, false, time.Time{}, []byte{}})
+			defer db.Close()
+
+			c := NewCache("ns", cachetest.Prefix("sqlcache"), p, cache.NewExpiration(time.Minute, 0))
+			ctx := context.Background()
+
+			sqlRows, err := db.Query(selectUsers, int64(1))
+			if err != nil {
+				t.Fatalf("db.Query() error = %v", err)
+			}
+			want := scanUsers(t, sqlRows)
+			fdb.queries = 0
+
+			for i := 0; i < 3; i++ {
+				rows, err := c.Query(ctx, db, []string{"users"}, selectUsers, int64(1))
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				if got := scanUsers(t, rows); !reflect.DeepEqual(got, want) {
+					t.Errorf("Query() #%d = %+v, want %+v", i, got, want)
+				}
+			}
+			if n := fdb.queryCount(); n != 1 {
+				t.Errorf("queries = %d, want 1", n)
+			}
+
+			// 参数不同的查询分别缓存。
+			rows, _ := c.Query(ctx, db, []string{"users"}, selectUsers, 2)
+			if got := scanUsers(t, rows); len(got) != 1 || got[0].Name != "b" {
+				t.Errorf("Query(2) = %+v", got)
+			}
+			if n := fdb.queryCount(); n != 2 {
+				t.Errorf("queries = %d, want 2", n)
+			}
+
+			// 写操作使缓存失效。
+			if _, err := c.Exec(ctx, db, []string{"users"}, "INSERT INTO users (id, name) VALUES (?, ?)", int64(3), "c"); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+			rows, _ = c.Query(ctx, db, []string{"users"}, selectUsers, int64(1))
+			if got := scanUsers(t, rows); len(got) != 3 {
+				t.Errorf("Query() after Exec() = %+v", got)
+			}
+			rows, _ = c.Query(ctx, db, []string{"users"}, selectUsers, int64(1))
+			rows.Close()
+			if n := fdb.queryCount(); n != 3 {
+				t.Errorf("queries = %d, want 3", n)
+			}
+
+			// 不相关的表不影响缓存。
+			c.Invalidate("orders")
+			rows, _ = c.Query(ctx, db, []string{"users"}, selectUsers, int64(1))
+			rows.Close()
+			if n := fdb.queryCount(); n != 3 {
+				t.Errorf("queries after unrelated invalidate = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestCache_WithCodec(t *testing.T) {
+	for name, p := range cachetest.Providers() {
+		t.Run(name, func(t *testing.T) {
+			db, fdb := openFake("codec_"+name, fakeUser{1, "a", 9.5, true, time.Time{}, []byte{1}})
+			defer db.Close()
+
+			c := NewCache("ns", cachetest.Prefix("sqlcache"), p, cache.NewExpiration(time.Minute, 0), cache.WithCodec(cache.GobCodec))
+			ctx := context.Background()
+
+			// 表的版本号不受编解码器的影响，失效之后的查询重新使用缓存。
+			c.Invalidate("users")
+			for i := 0; i < 2; i++ {
+				rows, err := c.Query(ctx, db, []string{"users"}, selectUsers, int64(1))
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				rows.Close()
+			}
+			if n := fdb.queryCount(); n != 1 {
+				t.Errorf("queries = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestRows_Scan(t *testing.T) {
+	now := time.Now()
+	rows := &Rows{res: result{
+		Columns: []string{"n", "s", "b", "null"},
+		Rows:    [][]value{{{Kind: 'i', Int: 42}, {Kind: 's', Str: "7"}, {Kind: 'y', Bytes: []byte("1.5")}, {Kind: 'n'}}},
+	}}
+
+	if err := rows.Scan(new(int)); err == nil {
+		t.Errorf("Scan() before Next() should fail")
+	}
+	if !rows.Next() {
+		t.Fatalf("Next() = false")
+	}
+
+	var (
+		n    string
+		s    uint8
+		b    float32
+		null sql.NullTime
+	)
+	if err := rows.Scan(&n, &s, &b, &null); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if n != "42" || s != 7 || b != 1.5 || null.Valid {
+		t.Errorf("Scan() = %v, %v, %v, %v", n, s, b, null)
+	}
+
+	var i int
+	if err := rows.Scan(&i, &s, &b, &now); err == nil {
+		t.Errorf("Scan() NULL into time.Time should fail")
+	}
+	if err := rows.Scan(&i); err == nil {
+		t.Errorf("Scan() with wrong number of destinations should fail")
+	}
+
+	if rows.Next() {
+		t.Errorf("Next() after last row = true")
+	}
+}
+
+// failingProvider 读取或者写入总是失败的缓存提供器。
+type failingProvider struct {
+	cache.CacheProvider
+	failGet, failSet bool
+}
+
+func (p failingProvider) TryGet(key string, value any) (bool, error) {
+	if p.failGet {
+		return false, errors.New("cache unavailable")
+	}
+	return p.CacheProvider.TryGet(key, value)
+}
+
+func (p failingProvider) Set(key string, value any, t time.Duration) error {
+	if p.failSet {
+		return errors.New("cache unavailable")
+	}
+	return p.CacheProvider.Set(key, value, t)
+}
+
+func TestCache_ProviderError(t *testing.T) {
+	tests := []struct {
+		name             string
+		failGet, failSet bool
+	}{
+		{"get", true, false},
+		{"set", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fdb := openFake("error_"+tt.name, fakeUser{1, "a", 0, false, time.Time{}, nil})
+			defer db.Close()
+
+			p := failingProvider{cache.NewMemoryCacheProvider(time.Second), tt.failGet, tt.failSet}
+			c := NewCache("ns", cachetest.Prefix("sqlcache"), p, cache.NewExpiration(time.Minute, 0))
+			for i := 0; i < 2; i++ {
+				rows, err := c.Query(context.Background(), db, []string{"users"}, selectUsers, int64(1))
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				if got := scanUsers(t, rows); len(got) != 1 || got[0].Name != "a" {
+					t.Errorf("Query() = %+v", got)
+				}
+			}
+			if n := fdb.queryCount(); n != 2 {
+				t.Errorf("queries = %d, want 2", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// TagCacheProvider 是支持标签的 CacheProvider 。
+// 缓存 key 可以关联到多个标签，通过 InvalidateTag 一次移除关联到某个标签的全部缓存，
+// 即使这些缓存属于不同的 Operation 。标签属于某个 CacheNamespace ，不同 CacheNamespace 下的同名标签互不影响。
+// 标签以集合的形式保存被关联的 key ，集合的缓存key为 <CacheNamespace>#tag:<tag> ，集合至少保留到被关联的 key 过期；
+// 每次关联时抽查集合中的少量 key ，移除已经过期的，使集合的大小不会随着写入次数无限增长。
+type TagCacheProvider interface {
+	CacheProvider
+
+	// AddTags 将缓存 key 关联到 tags 。
+	//  @cacheNamespace: 标签所属的 CacheNamespace 。
+	//  @key: cache key.
+	//  @tags: 标签。
+	//  @t: key 的过期时长， 0表不过期，标签的集合的过期时间不会早于 key 。
+	AddTags(cacheNamespace, key string, tags []string, t time.Duration) error
+
+	// InvalidateTag 移除关联到 tag 的全部缓存，以及标签本身。
+	//  @cacheNamespace: 标签所属的 CacheNamespace 。
+	//  @tag: 标签。
+	// return: 成功移除的缓存数量，不包括已经过期的缓存。
+	InvalidateTag(cacheNamespace, tag string) (int64, error)
+}
+
+// tagPruneCount 每次关联标签时抽查的 key 的个数。
+const tagPruneCount = 2
+
+// tagKey 获取标签的集合的缓存key。
+func tagKey(cacheNamespace, tag string) (string, error) {
+	if cacheNamespace == "" || tag == "" {
+		return "", fmt.Errorf("neither 'cacheNamespace' nor 'tag' can be empty")
+	}
+	return cacheNamespace + "#tag:" + tag, nil
+}
+
+// checkTags 检查 tags 不能为空，并且缓存提供器支持标签，反之 panic 。
+func checkTags(p CacheProvider, tags []string) {
+	for _, tag := range tags {
+		if tag == "" {
+			panic(fmt.Errorf("tag must not be empty"))
+		}
+	}
+
+	if _, ok := p.(TagCacheProvider); !ok {
+		panic(fmt.Errorf("cache provider %T does not implement TagCacheProvider", p))
+	}
+}
+
+// addTags 写入缓存之后，将 key 关联到 tags ，没有标签时不做任何事。
+// 写入与关联不是原子的，二者之间执行的 InvalidateTag 不会移除这次写入的值。
+func addTags(p CacheProvider, cacheNamespace, key string, tags []string, t time.Duration) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return p.(TagCacheProvider).AddTags(cacheNamespace, key, tags, t)
+}
+
+// appendTags 返回 tags 与 more 合并后的新切片，不修改 tags 。
+func appendTags(tags []string, more []string) []string {
+	res := make([]string, 0, len(tags)+len(more))
+	res = append(res, tags...)
+	return append(res, more...)
+}
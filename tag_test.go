@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestKeyOperation_Tag(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			suffix := fmt.Sprint(time.Now().UnixNano())
+			tag, otherTag := "product:1_"+suffix, "product:2_"+suffix
+			detail := NewOperation1[int, Person]("ns", "tag_detail_"+suffix, p, NewExpiration(time.Minute, 0))
+			list := NewOperation("ns", "tag_list_"+suffix, 1, p, NewExpiration(time.Minute, 0))
+			views := NewOperation("ns", "tag_views_"+suffix, 1, p, nil)
+
+			if err := detail.Key(1).Tag(tag).Set(Person{"a", 1}); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := detail.Key(2).Tag(otherTag).Set(Person{"b", 2}); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if _, err := list.Key("hot").Tag(tag, otherTag).Create([]int{1, 2}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if _, err := views.Key(1).Tag(tag).IncreaseOrCreate(1); err != nil {
+				t.Fatalf("IncreaseOrCreate() error = %v", err)
+			}
+			list.Key("new").MustSet([]int{2}) // 没有标签。
+
+			tp := p.(TagCacheProvider)
+			n, err := tp.InvalidateTag("ns", tag)
+			if err != nil || n != 3 {
+				t.Fatalf("InvalidateTag() = %v, %v, want 3", n, err)
+			}
+			if _, ok, _ := detail.Key(1).TryGet(); ok {
+				t.Errorf("detail 1 should be invalidated")
+			}
+			if list.Key("hot").MustTryGet(new([]int)) || views.Key(1).MustTryGet(new(int)) {
+				t.Errorf("list and views should be invalidated")
+			}
+			if _, ok, _ := detail.Key(2).TryGet(); !ok {
+				t.Errorf("detail 2 should not be invalidated")
+			}
+			if !list.Key("new").MustTryGet(new([]int)) {
+				t.Errorf("untagged key should not be invalidated")
+			}
+
+			// 标签只生效一次，再次写入需要重新关联。
+			if n, _ := tp.InvalidateTag("ns", tag); n != 0 {
+				t.Errorf("InvalidateTag() again = %v, want 0", n)
+			}
+			if n, _ := tp.InvalidateTag("ns", otherTag); n != 1 {
+				t.Errorf("InvalidateTag(other) = %v, want 1", n)
+			}
+
+			// 标签属于 CacheNamespace ，不同 CacheNamespace 的同名标签互不影响。
+			other := NewOperation("ns2", "tag_detail_"+suffix, 1, p, nil)
+			other.Key(1).Tag(tag).MustSet(1)
+			detail.Key(1).Tag(tag).MustSet(Person{"a", 1})
+			if n, _ := tp.InvalidateTag("ns2", tag); n != 1 {
+				t.Errorf("InvalidateTag(ns2) = %v, want 1", n)
+			}
+			if _, ok, _ := detail.Key(1).TryGet(); !ok {
+				t.Errorf("tag of another namespace should not be invalidated")
+			}
+			if _, err := tp.InvalidateTag("", tag); err == nil {
+				t.Errorf("InvalidateTag() without namespace should fail")
+			}
+		})
+	}
+}
+
+func TestKeyOperation_Tag_Copy(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	keyOp := NewOperation("ns", "tag_copy", 0, p, nil).Key()
+	a := keyOp.Tag("a")
+	ab := a.Tag("b")
+	if len(keyOp.tags) != 0 || len(a.tags) != 1 || len(ab.tags) != 2 {
+		t.Errorf("tags = %v, %v, %v", keyOp.tags, a.tags, ab.tags)
+	}
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"unsupported", func() { NewOperation("ns", "tag_copy", 0, unsupportedProvider{p}, nil).Key().Tag("a") }},
+		{"empty", func() { keyOp.Tag("") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Tag() should panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+func TestMemoryCacheProvider_AddTags_Expiration(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	exp := func() time.Time {
+		tk, _ := tagKey("ns", "t")
+		_, exp, _ := p.cache.GetWithExpiration(tk)
+		return exp
+	}
+
+	p.AddTags("ns", "k1", []string{"t"}, time.Minute)
+	first := exp()
+	p.AddTags("ns", "k2", []string{"t"}, time.Second)
+	if !exp().Equal(first) {
+		t.Errorf("shorter expiration should not shorten the tag")
+	}
+	p.AddTags("ns", "k3", []string{"t"}, time.Hour)
+	if !exp().After(first) {
+		t.Errorf("longer expiration should extend the tag")
+	}
+	p.AddTags("ns", "k4", []string{"t"}, 0)
+	if !exp().IsZero() {
+		t.Errorf("no expiration should persist the tag")
+	}
+	p.AddTags("ns", "k5", []string{"t"}, time.Second)
+	if !exp().IsZero() {
+		t.Errorf("persisted tag should not expire")
+	}
+}
+
+func TestRedisCacheProvider_AddTags_Expiration(t *testing.T) {
+	p := getNewEveryTime()
+	cli := p.Client().(*redis.Client)
+	tag := fmt.Sprint("expiration_", time.Now().UnixNano())
+	tk, _ := tagKey("ns", tag)
+	ttl := func() time.Duration {
+		return cli.PTTL(context.Background(), tk).Val()
+	}
+
+	p.AddTags("ns", "k1", []string{tag}, time.Minute)
+	if d := ttl(); d <= 50*time.Second || d > time.Minute {
+		t.Errorf("ttl = %v", d)
+	}
+	p.AddTags("ns", "k2", []string{tag}, time.Second)
+	if d := ttl(); d <= 50*time.Second {
+		t.Errorf("shorter expiration should not shorten the tag, ttl = %v", d)
+	}
+	p.AddTags("ns", "k3", []string{tag}, time.Hour)
+	if d := ttl(); d <= time.Minute {
+		t.Errorf("longer expiration should extend the tag, ttl = %v", d)
+	}
+	p.AddTags("ns", "k4", []string{tag}, 0)
+	if d := ttl(); d != -1 {
+		t.Errorf("no expiration should persist the tag, ttl = %v", d)
+	}
+	p.AddTags("ns", "k5", []string{tag}, time.Second)
+	if d := ttl(); d != -1 {
+		t.Errorf("persisted tag should not expire, ttl = %v", d)
+	}
+}
+
+func TestTagCacheProvider_Prune(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			tp, sp := p.(TagCacheProvider), p.(SetCacheProvider)
+			suffix := fmt.Sprint(time.Now().UnixNano())
+			tag := "prune_" + suffix
+			tk, _ := tagKey("ns", tag)
+
+			// 关联后移除的 key 在之后关联其他 key 时被逐渐清理。
+			for i := 0; i < 10; i++ {
+				key := fmt.Sprint("prune_", suffix, "_", i)
+				p.Set(key, i, time.Minute)
+				tp.AddTags("ns", key, []string{tag}, time.Minute)
+				p.Remove(key)
+			}
+			live := "prune_" + suffix + "_live"
+			p.Set(live, 1, time.Minute)
+			for i := 0; i < 50; i++ {
+				tp.AddTags("ns", live, []string{tag}, time.Minute)
+			}
+
+			if n, err := sp.SetCard(tk); err != nil || n != 1 {
+				t.Errorf("SetCard() = %v, %v, want 1", n, err)
+			}
+			if n, err := tp.InvalidateTag("ns", tag); err != nil || n != 1 {
+				t.Errorf("InvalidateTag() = %v, %v, want 1", n, err)
+			}
+		})
+	}
+}
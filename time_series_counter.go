@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTimeSeriesBuckets 一次查询最多读取的时间桶个数，避免误传时间范围导致读取大量 key 。
+const maxTimeSeriesBuckets = 100000
+
+// TimeResolution 时间序列计数器的时间桶精度。
+type TimeResolution int
+
+const (
+	ResolutionSecond TimeResolution = iota + 1 // 按秒分桶。
+	ResolutionMinute                           // 按分钟分桶。
+	ResolutionHour                             // 按小时分桶。
+)
+
+// Duration 获取时间桶的时长。
+func (r TimeResolution) Duration() time.Duration {
+	switch r {
+	case ResolutionSecond:
+		return time.Second
+	case ResolutionMinute:
+		return time.Minute
+	case ResolutionHour:
+		return time.Hour
+	}
+	panic(fmt.Errorf("unknown time resolution %d", r))
+}
+
+// layout 获取时间桶在缓存 key 中的格式。
+func (r TimeResolution) layout() string {
+	switch r {
+	case ResolutionSecond:
+		return "20060102150405"
+	case ResolutionMinute:
+		return "200601021504"
+	case ResolutionHour:
+		return "2006010215"
+	}
+	panic(fmt.Errorf("unknown time resolution %d", r))
+}
+
+// truncate 获取 t 在 loc 时区所在时间桶的起始时间。
+func (r TimeResolution) truncate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	switch r {
+	case ResolutionSecond:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc)
+	case ResolutionMinute:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case ResolutionHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	}
+	panic(fmt.Errorf("unknown time resolution %d", r))
+}
+
+// TimeSeriesPoint 时间序列中的一个时间桶。
+type TimeSeriesPoint struct {
+	Time  time.Time // 时间桶的起始时间。
+	Value int64     // 计数，时间桶不存在时为 0 。
+}
+
+// TimeSeriesCounter 按时间分桶的计数器，例如按分钟统计页面的访问量。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]_<bucket>，每个时间桶是一个独立的计数器，
+// bucket 是时间桶的起始时间在指定时区下的格式化结果，例如按分钟分桶为 200601021504 。
+// 时间桶在创建时设置过期时间，旧的时间桶自动过期，过期时长应当覆盖需要查询的时间范围。
+type TimeSeriesCounter struct {
+	op         Operation
+	resolution TimeResolution
+	loc        *time.Location
+}
+
+// NewTimeSeriesCounter 创建一个按时间分桶的计数器。
+// uniqueFlagLen: 指定 unique flag 的元素个数，不包括时间桶。
+// resolution: 时间桶的精度。
+// loc: 时间桶的时区，影响缓存 key 以及按小时分桶时的边界， nil 表示 time.Local 。
+// expireTime: 每个时间桶的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// 使用 WithHashTag(uniqueFlagLen) 可以使同一个计数器的所有时间桶位于 redis 集群的同一个哈希槽。
+func NewTimeSeriesCounter(
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	resolution TimeResolution,
+	loc *time.Location,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *TimeSeriesCounter {
+	resolution.Duration() // 校验精度。
+
+	if loc == nil {
+		loc = time.Local
+	}
+
+	// 最后一个 unique flag 是时间桶。
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen+1, cacheProvider, expireTime, opts...)
+	return &TimeSeriesCounter{*op, resolution, loc}
+}
+
+// Key 获取指定计数器的操作对象。
+func (c *TimeSeriesCounter) Key(keys ...any) *TimeSeriesKeyOperation {
+	if len(keys) != c.op.uniqueFlagLen-1 {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen-1))
+	}
+
+	keyBase, err := c.op.currentKeyBase()
+	return &TimeSeriesKeyOperation{c, keys, keyBase, err}
+}
+
+// TimeSeriesKeyOperation 时间序列计数器的操作对象。
+type TimeSeriesKeyOperation struct {
+	owner   *TimeSeriesCounter
+	keys    []any
+	keyBase string // 所有时间桶使用创建时获取的 keyBase ，使用 WithGeneration 时属于同一个代数。
+	err     error  // 获取 keyBase 时的错误，不为 nil 时访问缓存的方法都返回该错误。
+}
+
+// BucketKey 获取 t 所在时间桶的缓存 key 。
+func (keyOp *TimeSeriesKeyOperation) BucketKey(t time.Time) string {
+	c := keyOp.owner
+	bucket := c.resolution.truncate(t, c.loc).Format(c.resolution.layout())
+	return c.op.joinCacheKey(keyOp.keyBase, append(keyOp.keys[:len(keyOp.keys):len(keyOp.keys)], bucket)...)
+}
+
+// IncreaseOrCreate 为 t 所在时间桶的计数增加一个增量(负数==减法)，时间桶不存在时创建并设置过期时间。
+//  return: 返回增加后时间桶的计数。
+func (keyOp *TimeSeriesKeyOperation) IncreaseOrCreate(t time.Time, increment int64) (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	c := keyOp.owner
+	return c.op.cacheProvider.IncreaseOrCreate(keyOp.BucketKey(t), increment, c.op.expireTime.NextExpireTime())
+}
+
+// MustIncreaseOrCreate 是 IncreaseOrCreate 的 panic 版。
+func (keyOp *TimeSeriesKeyOperation) MustIncreaseOrCreate(t time.Time, increment int64) int64 {
+	result, err := keyOp.IncreaseOrCreate(t, increment)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Series 获取时间范围内每个时间桶的计数，包括 from 和 to 所在的时间桶，按时间升序。
+// 时间桶按 loc 的本地时间划分，夏令时结束时重复的本地时间属于同一个时间桶，只出现一次。
+// CacheProvider 实现了 MultiKeyCacheProvider 时，一次批量读取所有时间桶。
+func (keyOp *TimeSeriesKeyOperation) Series(from, to time.Time) ([]TimeSeriesPoint, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("param 'to' must not be before 'from'")
+	}
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	c := keyOp.owner
+	start, end := c.resolution.truncate(from, c.loc), c.resolution.truncate(to, c.loc)
+	step := c.resolution.Duration()
+	if n := end.Sub(start)/step + 1; n > maxTimeSeriesBuckets {
+		return nil, fmt.Errorf("time range contains %d buckets, exceeds the limit %d", n, maxTimeSeriesBuckets)
+	}
+
+	// 夏令时结束时，同一段本地时间会出现两次，对应同一个时间桶，只保留第一次出现的。
+	var points []TimeSeriesPoint
+	var keys []string
+	seen := make(map[string]bool)
+	for t := start; !t.After(end); t = t.Add(step) {
+		key := keyOp.BucketKey(t)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		points = append(points, TimeSeriesPoint{Time: t})
+		keys = append(keys, key)
+	}
+
+	values := make([]any, len(points))
+	for i := range points {
+		values[i] = &points[i].Value
+	}
+
+	if mp, ok := c.op.cacheProvider.(MultiKeyCacheProvider); ok {
+		if _, err := mp.TryGetMulti(keys, values); err != nil {
+			return nil, err
+		}
+		return points, nil
+	}
+
+	for i, key := range keys {
+		if _, err := c.op.cacheProvider.TryGet(key, values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+// MustSeries 是 Series 的 panic 版。
+func (keyOp *TimeSeriesKeyOperation) MustSeries(from, to time.Time) []TimeSeriesPoint {
+	result, err := keyOp.Series(from, to)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Sum 获取时间范围内所有时间桶的计数之和，时间范围的规则与 Series 相同。
+func (keyOp *TimeSeriesKeyOperation) Sum(from, to time.Time) (int64, error) {
+	points, err := keyOp.Series(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum int64
+	for _, p := range points {
+		sum += p.Value
+	}
+	return sum, nil
+}
+
+// MustSum 是 Sum 的 panic 版。
+func (keyOp *TimeSeriesKeyOperation) MustSum(from, to time.Time) int64 {
+	result, err := keyOp.Sum(from, to)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTimeSeriesCounter(t *testing.T) {
+	providers := structureTestProviders()
+	providers["single_key"] = unsupportedProvider{NewMemoryCacheProvider(time.Second)} // 逐个读取时间桶。
+
+	for name, p := range providers {
+		t.Run(name, func(t *testing.T) {
+			c := NewTimeSeriesCounter("ns", "pv_"+fmt.Sprint(rand.Int31()), 1, ResolutionMinute, nil, p, NewExpiration(time.Minute, 0))
+			page := c.Key("index")
+			base := time.Date(2022, 5, 1, 8, 30, 0, 0, time.Local)
+
+			if key := page.BucketKey(base.Add(59 * time.Second)); key != page.BucketKey(base) {
+				t.Fatalf("BucketKey() in the same minute = %v, want %v", key, page.BucketKey(base))
+			}
+
+			if n := page.MustIncreaseOrCreate(base, 2); n != 2 {
+				t.Errorf("IncreaseOrCreate() = %v, want 2", n)
+			}
+			if n := page.MustIncreaseOrCreate(base.Add(30*time.Second), 1); n != 3 {
+				t.Errorf("IncreaseOrCreate() = %v, want 3", n)
+			}
+			page.MustIncreaseOrCreate(base.Add(2*time.Minute), 5)
+			c.Key("other").MustIncreaseOrCreate(base, 100)
+
+			series := page.MustSeries(base.Add(-time.Minute), base.Add(2*time.Minute+10*time.Second))
+			want := []int64{0, 3, 0, 5}
+			if len(series) != len(want) {
+				t.Fatalf("Series() len = %v, want %v", len(series), len(want))
+			}
+			for i, point := range series {
+				if point.Value != want[i] || !point.Time.Equal(base.Add(time.Duration(i-1)*time.Minute)) {
+					t.Errorf("Series()[%d] = %v, want %v at %v", i, point, want[i], base.Add(time.Duration(i-1)*time.Minute))
+				}
+			}
+
+			if sum := page.MustSum(base, base.Add(time.Hour)); sum != 8 {
+				t.Errorf("Sum() = %v, want 8", sum)
+			}
+
+			if _, err := page.Series(base, base.Add(-time.Second)); err == nil {
+				t.Errorf("Series() with to before from should return error")
+			}
+			if _, err := page.Series(base, base.AddDate(1, 0, 0)); err == nil {
+				t.Errorf("Series() with too many buckets should return error")
+			}
+		})
+	}
+}
+
+func TestTimeSeriesCounter_Location(t *testing.T) {
+	// 半小时时区，按小时分桶的边界与 UTC 不同。
+	loc := time.FixedZone("IST", 5*3600+1800)
+	c := NewTimeSeriesCounter("ns", "pv", 0, ResolutionHour, loc, NewMemoryCacheProvider(time.Second), nil)
+	keyOp := c.Key()
+
+	at := time.Date(2022, 5, 1, 3, 10, 0, 0, time.UTC) // 08:40 IST 。
+	if key := keyOp.BucketKey(at); key != "ns:pv_2022050108" {
+		t.Errorf("BucketKey() = %v, want ns:pv_2022050108", key)
+	}
+
+	keyOp.MustIncreaseOrCreate(at, 1)
+	series := keyOp.MustSeries(at, at)
+	if len(series) != 1 || !series[0].Time.Equal(time.Date(2022, 5, 1, 8, 0, 0, 0, loc)) || series[0].Value != 1 {
+		t.Errorf("Series() = %v", series)
+	}
+}
+
+func TestTimeSeriesCounter_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("LoadLocation() error = %v", err)
+	}
+
+	for name, resolution := range map[string]TimeResolution{"hour": ResolutionHour, "minute": ResolutionMinute} {
+		t.Run(name, func(t *testing.T) {
+			c := NewTimeSeriesCounter("ns", "dst_"+fmt.Sprint(rand.Int31()), 0, resolution, loc, NewMemoryCacheProvider(time.Second), nil)
+			keyOp := c.Key()
+
+			// 2022-11-06 02:00 EDT 回拨到 01:00 EST ， 01:30 出现两次，属于同一个时间桶。
+			edt := time.Date(2022, 11, 6, 5, 30, 0, 0, time.UTC)
+			est := edt.Add(time.Hour)
+			if keyOp.BucketKey(edt) != keyOp.BucketKey(est) {
+				t.Fatalf("BucketKey() of repeated local time should be equal")
+			}
+			keyOp.MustIncreaseOrCreate(edt, 1)
+			keyOp.MustIncreaseOrCreate(est, 1)
+
+			from, to := edt.Add(-time.Hour), est.Add(time.Hour)
+			points := keyOp.MustSeries(from, to)
+			seen := map[string]bool{}
+			for _, p := range points {
+				key := keyOp.BucketKey(p.Time)
+				if seen[key] {
+					t.Errorf("Series() contains bucket %s twice", key)
+				}
+				seen[key] = true
+			}
+			if sum, err := keyOp.Sum(from, to); err != nil || sum != 2 {
+				t.Errorf("Sum() = %v, %v, want 2", sum, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// UniqueCounterCacheProvider 是支持 HyperLogLog 近似去重计数的 CacheProvider ，
+// 标准误差约为 0.81%，每个计数器最多占用约 12KB，成员是 encodeMember 的编码结果。
+type UniqueCounterCacheProvider interface {
+	CacheProvider
+
+	// UniqueCounterAdd 添加成员，计数器不存在时创建。
+	//  @key: cache key.
+	//  @members: 成员。
+	//  @t: 过期时长， 0表不过期，只有计数器是新创建的才设置。
+	// return: 计数的估计值可能发生变化时返回 true 。
+	UniqueCounterAdd(key string, members []string, t time.Duration) (bool, error)
+
+	// UniqueCounterCount 获取多个计数器并集的去重计数的估计值，不存在的计数器视为空。
+	UniqueCounterCount(keys ...string) (int64, error)
+
+	// UniqueCounterMerge 将多个计数器合并到 dest，dest 原有的计数会被保留。
+	//  @t: 过期时长， 0表不过期，只有 dest 是新创建的才设置。
+	UniqueCounterMerge(dest string, sources []string, t time.Duration) error
+
+	// UniqueCounterExport 导出计数器，格式与 redis 的 HyperLogLog 相同，用于在缓存提供器之间迁移数据。
+	// return: 计数器存在时返回导出的数据和 true，反之返回 false。
+	UniqueCounterExport(key string) ([]byte, bool, error)
+
+	// UniqueCounterImport 导入 UniqueCounterExport 导出的数据，覆盖已有的计数器。
+	//  @t: 过期时长， 0表不过期。
+	UniqueCounterImport(key string, data []byte, t time.Duration) error
+}
+
+// UniqueCounterOperation HyperLogLog 近似去重计数的缓存操作对象，例如统计页面每天的独立访客数。
+// 缓存key分三段 <CacheNamespace>:<Prefix>[:unique flag]，每个缓存 key 对应一个计数器。
+// 成员的基础类型转换成字符串，其他类型使用 JSON 编码后参与计数，不受 Codec 影响。
+type UniqueCounterOperation[T any] struct {
+	op Operation
+	p  UniqueCounterCacheProvider
+}
+
+// NewUniqueCounterOperation 创建一个近似去重计数的缓存操作对象。
+// expireTime: 计数器的过期时长， nil 或者 CacheExpirationZero 表不过期。
+// uniqueFlagLen: 指定 unique flag 的元素个数。
+// cacheProvider: 必须实现 UniqueCounterCacheProvider 。
+func NewUniqueCounterOperation[T any](
+	cacheNamespace, keyPrefix string,
+	uniqueFlagLen int,
+	cacheProvider CacheProvider,
+	expireTime *Expiration,
+	opts ...OperationOption,
+) *UniqueCounterOperation[T] {
+	op := NewOperation(cacheNamespace, keyPrefix, uniqueFlagLen, cacheProvider, expireTime, opts...)
+	p, ok := op.cacheProvider.(UniqueCounterCacheProvider)
+	if !ok {
+		panic(fmt.Errorf("cache provider %T does not implement UniqueCounterCacheProvider", op.cacheProvider))
+	}
+
+	return &UniqueCounterOperation[T]{*op, p}
+}
+
+// Key 获取指定计数器的缓存操作对象。
+func (c *UniqueCounterOperation[T]) Key(keys ...any) *UniqueCounterKeyOperation[T] {
+	if len(keys) != c.op.uniqueFlagLen {
+		panic(fmt.Errorf("param 'keys' len(%d)  != uniqueFlagLen(%d)", len(keys), c.op.uniqueFlagLen))
+	}
+
+	key, err := c.op.buildCacheKey(keys...)
+	return &UniqueCounterKeyOperation[T]{
+		owner: c,
+		p:     c.p,
+		exp:   c.op.expireTime,
+		Key:   key,
+		err:   err,
+	}
+}
+
+// Count 获取多个计数器并集的去重计数，例如一周内每天的计数器合计的独立访客数。
+// 计数器必须由当前对象的 Key 方法创建。
+func (c *UniqueCounterOperation[T]) Count(keyOps ...*UniqueCounterKeyOperation[T]) (int64, error) {
+	keys, err := c.keys(keyOps)
+	if err != nil {
+		return 0, err
+	}
+	return c.p.UniqueCounterCount(keys...)
+}
+
+// MustCount 是 Count 的 panic 版。
+func (c *UniqueCounterOperation[T]) MustCount(keyOps ...*UniqueCounterKeyOperation[T]) int64 {
+	result, err := c.Count(keyOps...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Merge 将多个计数器合并到 dest，dest 是新创建的时候，设置过期时间。
+// 计数器必须由当前对象的 Key 方法创建。
+func (c *UniqueCounterOperation[T]) Merge(dest *UniqueCounterKeyOperation[T], sources ...*UniqueCounterKeyOperation[T]) error {
+	keys, err := c.keys(append([]*UniqueCounterKeyOperation[T]{dest}, sources...))
+	if err != nil {
+		return err
+	}
+	return c.p.UniqueCounterMerge(keys[0], keys[1:], dest.exp.NextExpireTime())
+}
+
+// MustMerge 是 Merge 的 panic 版。
+func (c *UniqueCounterOperation[T]) MustMerge(dest *UniqueCounterKeyOperation[T], sources ...*UniqueCounterKeyOperation[T]) {
+	err := c.Merge(dest, sources...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (c *UniqueCounterOperation[T]) keys(keyOps []*UniqueCounterKeyOperation[T]) ([]string, error) {
+	if len(keyOps) == 0 {
+		return nil, fmt.Errorf("param 'keyOps' must not be empty")
+	}
+
+	keys := make([]string, len(keyOps))
+	for i, keyOp := range keyOps {
+		if keyOp == nil || keyOp.owner != c {
+			return nil, fmt.Errorf("key does not belong to this operation")
+		}
+		if keyOp.err != nil {
+			return nil, keyOp.err
+		}
+		keys[i] = keyOp.Key
+	}
+	return keys, nil
+}
+
+// UniqueCounterKeyOperation 近似去重计数缓存 key 的操作对象。
+type UniqueCounterKeyOperation[T any] struct {
+	owner *UniqueCounterOperation[T]
+	p     UniqueCounterCacheProvider
+	exp   *Expiration
+	err   error // 构建缓存key时的错误，不为 nil 时访问缓存的方法都返回该错误。
+
+	// 缓存key。
+	Key string
+}
+
+// Add 添加成员，计数器是新创建的时候，设置过期时间。
+//  return: 计数的估计值可能发生变化时返回 true 。
+func (keyOp *UniqueCounterKeyOperation[T]) Add(members ...T) (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	ms, err := encodeMembers(members)
+	if err != nil {
+		return false, err
+	}
+	return keyOp.p.UniqueCounterAdd(keyOp.Key, ms, keyOp.exp.NextExpireTime())
+}
+
+// MustAdd 是 Add 的 panic 版。
+func (keyOp *UniqueCounterKeyOperation[T]) MustAdd(members ...T) bool {
+	result, err := keyOp.Add(members...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Count 获取去重计数的估计值，计数器不存在时返回 0 。
+func (keyOp *UniqueCounterKeyOperation[T]) Count() (int64, error) {
+	if keyOp.err != nil {
+		return 0, keyOp.err
+	}
+
+	return keyOp.p.UniqueCounterCount(keyOp.Key)
+}
+
+// MustCount 是 Count 的 panic 版。
+func (keyOp *UniqueCounterKeyOperation[T]) MustCount() int64 {
+	result, err := keyOp.Count()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Export 导出计数器，格式与 redis 的 HyperLogLog 相同，计数器不存在时返回 nil 。
+func (keyOp *UniqueCounterKeyOperation[T]) Export() ([]byte, error) {
+	if keyOp.err != nil {
+		return nil, keyOp.err
+	}
+
+	data, _, err := keyOp.p.UniqueCounterExport(keyOp.Key)
+	return data, err
+}
+
+// MustExport 是 Export 的 panic 版。
+func (keyOp *UniqueCounterKeyOperation[T]) MustExport() []byte {
+	result, err := keyOp.Export()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Import 导入 Export 导出的数据，覆盖已有的计数器，并设置过期时间。
+// 可以用于在 redis 和 memory 缓存之间迁移计数器。
+func (keyOp *UniqueCounterKeyOperation[T]) Import(data []byte) error {
+	if keyOp.err != nil {
+		return keyOp.err
+	}
+
+	return keyOp.p.UniqueCounterImport(keyOp.Key, data, keyOp.exp.NextExpireTime())
+}
+
+// MustImport 是 Import 的 panic 版。
+func (keyOp *UniqueCounterKeyOperation[T]) MustImport(data []byte) {
+	err := keyOp.Import(data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Remove 移除计数器。
+//  return: true成功移除，false缓存不存在。
+func (keyOp *UniqueCounterKeyOperation[T]) Remove() (bool, error) {
+	if keyOp.err != nil {
+		return false, keyOp.err
+	}
+
+	return keyOp.p.Remove(keyOp.Key)
+}
+
+// MustRemove 是 Remove 的 panic 版。
+func (keyOp *UniqueCounterKeyOperation[T]) MustRemove() bool {
+	result, err := keyOp.Remove()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
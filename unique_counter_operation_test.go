@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// skipIfHyperLogLogNotString 一些 redis 的模拟实现（例如 miniredis）中 HyperLogLog 不是字符串，无法导入导出。
+func skipIfHyperLogLogNotString(t *testing.T, p CacheProvider) {
+	cli, ok := p.(*RedisCacheProvider)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	key := "hll_probe_" + fmt.Sprint(rand.Int31())
+	defer cli.client.Del(ctx, key)
+
+	cli.client.PFAdd(ctx, key, "a")
+	if err := cli.client.Get(ctx, key).Err(); err != nil {
+		t.Skipf("HyperLogLog is not a string in this redis server: %v", err)
+	}
+}
+
+func TestUniqueCounterOperation(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			op := NewUniqueCounterOperation[int]("ns", "uv_"+fmt.Sprint(rand.Int31()), 1, p, NewExpiration(time.Minute, 0))
+			day1, day2, day3 := op.Key(1), op.Key(2), op.Key(3)
+			defer day1.Remove()
+			defer day2.Remove()
+			defer day3.Remove()
+
+			if n := day1.MustCount(); n != 0 {
+				t.Fatalf("Count() on missing counter = %v, want 0", n)
+			}
+
+			if !day1.MustAdd(1, 2, 3) {
+				t.Errorf("Add() new members should return true")
+			}
+			if day1.MustAdd(1, 2) {
+				t.Errorf("Add() existing members should return false")
+			}
+			if n := day1.MustCount(); n != 3 {
+				t.Errorf("Count() = %v, want 3", n)
+			}
+
+			for i := 0; i < 1000; i++ {
+				day2.MustAdd(i)
+			}
+			if n := day2.MustCount(); math.Abs(float64(n)-1000) > 30 {
+				t.Errorf("Count() = %v, want about 1000", n)
+			}
+
+			if n := op.MustCount(day1, day2, day3); math.Abs(float64(n)-1000) > 30 {
+				t.Errorf("Count() union = %v, want about 1000", n)
+			}
+
+			day3.MustAdd(5000)
+			op.MustMerge(day3, day1, day2)
+			if n := day3.MustCount(); math.Abs(float64(n)-1001) > 30 {
+				t.Errorf("Merge() count = %v, want about 1001", n)
+			}
+
+			if _, err := op.Count(); err == nil {
+				t.Errorf("Count() without keys should return error")
+			}
+			other := NewUniqueCounterOperation[int]("ns", "other", 0, p, nil)
+			if _, err := op.Count(day1, other.Key()); err == nil {
+				t.Errorf("Count() with key of another operation should return error")
+			}
+		})
+	}
+}
+
+func TestUniqueCounterOperation_ExportImport(t *testing.T) {
+	for name, p := range structureTestProviders() {
+		t.Run(name, func(t *testing.T) {
+			skipIfHyperLogLogNotString(t, p)
+
+			mem := NewMemoryCacheProvider(time.Second)
+			prefix := "uv_" + fmt.Sprint(rand.Int31())
+			from := NewUniqueCounterOperation[string]("ns", prefix, 0, mem, nil).Key()
+			to := NewUniqueCounterOperation[string]("ns", prefix, 0, p, nil).Key()
+			defer to.Remove()
+
+			if data := to.MustExport(); data != nil {
+				t.Fatalf("Export() on missing counter = %v, want nil", data)
+			}
+
+			for i := 0; i < 500; i++ {
+				from.MustAdd(fmt.Sprint("user_", i))
+			}
+			want := from.MustCount()
+
+			// memory -> p -> memory 。
+			to.MustImport(from.MustExport())
+			if n := to.MustCount(); n != want {
+				t.Errorf("Count() after Import() = %v, want %v", n, want)
+			}
+
+			// 导入后继续添加，与在 memory 中添加的结果一致。
+			to.MustAdd("user_new")
+			from.MustAdd("user_new")
+			from.MustImport(to.MustExport())
+			if n, m := from.MustCount(), to.MustCount(); n != m {
+				t.Errorf("Count() after round trip = %v, want %v", n, m)
+			}
+
+			if err := to.Import([]byte("not a HyperLogLog")); err == nil {
+				t.Errorf("Import() invalid data should return error")
+			}
+		})
+	}
+}
+
+func TestUniqueCounterOperation_Ring(t *testing.T) {
+	ring := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"shard": "127.0.0.1:6379"}, MaxRetries: -1})
+	defer ring.Close()
+
+	p := NewRedisCacheProvider(ring)
+	skipIfHyperLogLogNotString(t, p)
+
+	// 没有哈希标签，分组后在客户端合并。
+	op := NewUniqueCounterOperation[int]("ns", "uv_"+fmt.Sprint(rand.Int31()), 1, p, NewExpiration(time.Minute, 0))
+	a, b, c := op.Key("a"), op.Key("b"), op.Key("c")
+	defer a.Remove()
+	defer b.Remove()
+	defer c.Remove()
+
+	a.MustAdd(1, 2, 3)
+	b.MustAdd(3, 4)
+	if n := op.MustCount(a, b, c); n != 4 {
+		t.Errorf("Count() = %v, want 4", n)
+	}
+
+	op.MustMerge(c, a, b)
+	if n := c.MustCount(); n != 4 {
+		t.Errorf("Merge() count = %v, want 4", n)
+	}
+	if ttl := ring.PTTL(context.Background(), c.Key).Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Merge() ttl = %v, want <= 1m", ttl)
+	}
+}
+
+func TestUniqueCounterOperation_WrongType(t *testing.T) {
+	p := NewMemoryCacheProvider(time.Second)
+	keyOp := NewUniqueCounterOperation[int]("ns", "uv_wrong_type", 0, p, nil).Key()
+	p.Set(keyOp.Key, 1, time.Minute)
+
+	if _, err := keyOp.Add(1); err == nil {
+		t.Errorf("Add() on non-counter key should return error")
+	}
+	if _, err := keyOp.Count(); err == nil {
+		t.Errorf("Count() on non-counter key should return error")
+	}
+}